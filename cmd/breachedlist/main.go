@@ -0,0 +1,91 @@
+// Command breachedlist builds the prefix files of the bundled breached password list.
+//
+// It reads one password per line from stdin (or, with -sha1, Have I Been Pwned style
+// "HASH[:COUNT]" lines) and merges them into the files of the output directory:
+//
+//	go run ./cmd/breachedlist -out pkg/auth/breached < passwords.txt
+package main
+
+import (
+	"bufio"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go-api-starter/pkg/auth"
+)
+
+func main() {
+	out := flag.String("out", "pkg/auth/breached", "directory of the prefix files")
+	hashed := flag.Bool("sha1", false, "input lines are SHA-1 hashes instead of passwords")
+	flag.Parse()
+
+	buckets := make(map[string]map[string]struct{})
+	add := func(hash string) {
+		prefix, suffix := hash[:auth.BreachedPrefixLen], hash[auth.BreachedPrefixLen:]
+		if buckets[prefix] == nil {
+			buckets[prefix] = make(map[string]struct{})
+		}
+		buckets[prefix][suffix] = struct{}{}
+	}
+
+	// Keep what is already in the list
+	entries, err := os.ReadDir(*out)
+	if err != nil && !os.IsNotExist(err) {
+		log.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || len(e.Name()) != auth.BreachedPrefixLen {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(*out, e.Name()))
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, line := range strings.Fields(string(data)) {
+			suffix, _, _ := strings.Cut(line, ":")
+			add(e.Name() + suffix)
+		}
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	added := 0
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if *hashed {
+			hash, _, _ := strings.Cut(line, ":")
+			if len(hash) != 40 {
+				continue
+			}
+			add(strings.ToUpper(hash))
+		} else {
+			add(auth.BreachedHash(line))
+		}
+		added++
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatal(err)
+	}
+	for prefix, set := range buckets {
+		suffixes := make([]string, 0, len(set))
+		for s := range set {
+			suffixes = append(suffixes, s)
+		}
+		slices.Sort(suffixes)
+		data := strings.Join(suffixes, "\n") + "\n"
+		if err := os.WriteFile(filepath.Join(*out, prefix), []byte(data), 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+	log.Printf("read %d entries, %d prefix files in %s", added, len(buckets), *out)
+}
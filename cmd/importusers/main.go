@@ -0,0 +1,139 @@
+// Command importusers imports users from a legacy system together with their password hashes.
+//
+// It reads one JSON object per line from stdin:
+//
+//	{"email": "john@example.com", "mobile": "13800138000", "username": "john", "password_hash": "$2y$10$..."}
+//
+// Accepted hash formats are Argon2id, bcrypt and PBKDF2-SHA256 (passlib or Django). Imported
+// hashes are verified as-is and upgraded to Argon2id on the user's next successful login.
+// Imported users get the default roles, like users who register. Users whose email or
+// mobile already exists are skipped.
+//
+//	go run ./cmd/importusers < users.jsonl
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"go-api-starter/internal/config"
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/repository"
+	"go-api-starter/pkg/auth"
+	"go-api-starter/pkg/database"
+)
+
+type legacyUser struct {
+	Email        string `json:"email"`
+	Mobile       string `json:"mobile"`
+	Username     string `json:"username"`
+	PasswordHash string `json:"password_hash"`
+}
+
+func main() {
+	cfg := config.Load()
+	db, err := database.Init(&database.Config{
+		Driver:          cfg.Database.Driver,
+		Path:            cfg.Database.Path,
+		Host:            cfg.Database.Host,
+		Port:            cfg.Database.Port,
+		Username:        cfg.Database.Username,
+		Password:        cfg.Database.Password,
+		DBName:          cfg.Database.DBName,
+		Charset:         cfg.Database.Charset,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		ConnMaxLifetime: time.Duration(cfg.Database.ConnMaxLifetime) * time.Second,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	userRepo := repository.NewUserRepository(db)
+	ctx := context.Background()
+
+	var imported, skipped int
+	scanner := bufio.NewScanner(os.Stdin)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var in legacyUser
+		if err := json.Unmarshal([]byte(text), &in); err != nil {
+			log.Fatalf("line %d: %v", line, err)
+		}
+		user, err := toUser(in)
+		if err != nil {
+			log.Fatalf("line %d: %v", line, err)
+		}
+
+		exists, err := userExists(ctx, userRepo, user)
+		if err != nil {
+			log.Fatalf("line %d: %v", line, err)
+		}
+		if exists {
+			log.Printf("line %d: user already exists, skipped", line)
+			skipped++
+			continue
+		}
+		if err := userRepo.CreateWithDefaultRoles(ctx, user); err != nil {
+			log.Fatalf("line %d: %v", line, err)
+		}
+		imported++
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("imported %d users, skipped %d", imported, skipped)
+}
+
+// toUser validates a legacy record and converts it to a User
+func toUser(in legacyUser) (*model.User, error) {
+	email := strings.ToLower(strings.TrimSpace(in.Email))
+	mobile := strings.TrimSpace(in.Mobile)
+	if email == "" && mobile == "" {
+		return nil, errors.New("email or mobile is required")
+	}
+	if auth.HashAlgorithm(in.PasswordHash) == "" {
+		return nil, errors.New("unsupported password hash format")
+	}
+
+	user := &model.User{Password: &in.PasswordHash}
+	if email != "" {
+		user.Email = &email
+	}
+	if mobile != "" {
+		user.Mobile = &mobile
+	}
+	if username := strings.TrimSpace(in.Username); username != "" {
+		user.Username = &username
+	}
+	return user, nil
+}
+
+// userExists reports whether the email or mobile is already registered
+func userExists(ctx context.Context, userRepo *repository.UserRepository, user *model.User) (bool, error) {
+	lookups := make([]func() (*model.User, error), 0, 2)
+	if user.Email != nil {
+		lookups = append(lookups, func() (*model.User, error) { return userRepo.FindByEmail(ctx, *user.Email) })
+	}
+	if user.Mobile != nil {
+		lookups = append(lookups, func() (*model.User, error) { return userRepo.FindByMobile(ctx, *user.Mobile) })
+	}
+	for _, find := range lookups {
+		_, err := find()
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, repository.ErrUserNotFound) {
+			return false, err
+		}
+	}
+	return false, nil
+}
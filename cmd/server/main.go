@@ -87,9 +87,10 @@ func main() {
 	}
 
 	// Setup router
-	r, permMw, _ := router.Setup(db)
+	r, permMw, c := router.Setup(db)
 
-	// Seed permissions defined in route registrations
+	// Seed the default role first so that it picks up newly created :own permissions
+	seed.SyncDefaultRole(db)
 	seed.SyncPermissions(db, permMw.CollectedCodes())
 
 	// Seed default admin user and role if configured
@@ -98,6 +99,11 @@ func main() {
 		seed.SyncAdminRole(db, cfg.App.AdminEmail)
 	}
 
+	// Seeds write roles and grants straight to the database, bypassing the permission cache
+	if err := c.PermissionCache().InvalidateAll(context.Background()); err != nil {
+		logger.Log.Warnf("Failed to reset permission cache: %v", err)
+	}
+
 	// Print banner (empty tools status since there are no external tool dependencies)
 	localIP := netutil.GetLocalIP()
 	banner.PrintBanner(cfg.App.Name, cfg.App.Env, cfg.Server.Port, localIP, nil)
@@ -113,12 +119,20 @@ func main() {
 		}
 	}()
 
+	// Lift expired temporary freezes and delete expired role assignments in the background
+	sweepCtx, stopSweep := context.WithCancel(context.Background())
+	go c.FreezeService().RunSweeper(sweepCtx)
+	go c.RoleExpiryService().RunSweeper(sweepCtx)
+	// Drop cached permissions when another instance changes roles or grants
+	go c.PermissionCache().Listen(sweepCtx)
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	logger.Log.Info("Shutting down server...")
+	stopSweep()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -13,14 +13,170 @@ import (
 
 // Config holds all configuration
 type Config struct {
-	App       AppConfig       `mapstructure:"app"`
-	Server    ServerConfig    `mapstructure:"server"`
-	Database  DatabaseConfig  `mapstructure:"database"`
-	Log       LogConfig       `mapstructure:"log"`
-	OSS       OSSConfig       `mapstructure:"oss"`
-	Redis     RedisConfig     `mapstructure:"redis"`
-	CORS      CORSConfig      `mapstructure:"cors"`
-	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	App           AppConfig            `mapstructure:"app"`
+	Server        ServerConfig         `mapstructure:"server"`
+	Database      DatabaseConfig       `mapstructure:"database"`
+	Log           LogConfig            `mapstructure:"log"`
+	OSS           OSSConfig            `mapstructure:"oss"`
+	Redis         RedisConfig          `mapstructure:"redis"`
+	CORS          CORSConfig           `mapstructure:"cors"`
+	RateLimit     RateLimitConfig      `mapstructure:"rate_limit"`
+	Verify        VerifyConfig         `mapstructure:"verify"`
+	Mail          MailConfig           `mapstructure:"mail"`
+	SMS           SMSConfig            `mapstructure:"sms"`
+	MFA           MFAConfig            `mapstructure:"mfa"`
+	JWT           JWTConfig            `mapstructure:"jwt"`
+	OIDC          OIDCConfig           `mapstructure:"oidc"`
+	QRLogin       QRLoginConfig        `mapstructure:"qr_login"`
+	MagicLink     MagicLinkConfig      `mapstructure:"magic_link"`
+	WebAuthn      WebAuthnConfig       `mapstructure:"webauthn"`
+	LoginGuard    LoginGuardConfig     `mapstructure:"login_guard"`
+	Password      PasswordPolicyConfig `mapstructure:"password_policy"`
+	Impersonation ImpersonationConfig  `mapstructure:"impersonation"`
+	OAuth         OAuthConfig          `mapstructure:"oauth"`
+	Freeze        FreezeConfig         `mapstructure:"freeze"`
+	PermCache     PermCacheConfig      `mapstructure:"permission_cache"`
+	RoleExpiry    RoleExpiryConfig     `mapstructure:"role_expiry"`
+}
+
+// VerifyConfig holds verification code settings.
+type VerifyConfig struct {
+	CodeTTL       time.Duration `mapstructure:"code_ttl"`
+	Cooldown      time.Duration `mapstructure:"cooldown"`        // 同一账号两次发送的最小间隔
+	MaxAttempts   int           `mapstructure:"max_attempts"`    // 单个验证码允许的错误次数
+	AccountPerDay int           `mapstructure:"account_per_day"` // 每个账号每天最多发送次数
+	IPPerHour     int           `mapstructure:"ip_per_hour"`     // 每个 IP 每小时最多发送次数
+}
+
+// MailConfig holds SMTP settings. Codes are logged to console when Host is empty.
+type MailConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+	FromName string `mapstructure:"from_name"`
+	// 未配置 host 时邮件写入该目录（每封一个 .eml 文件），为空则输出到日志；仅用于开发
+	OutboxDir string `mapstructure:"outbox_dir"`
+}
+
+// SMSConfig holds SMS gateway settings. Codes are logged to console when GatewayURL is empty.
+type SMSConfig struct {
+	GatewayURL string `mapstructure:"gateway_url"`
+	APIKey     string `mapstructure:"api_key"`
+	SignName   string `mapstructure:"sign_name"`
+}
+
+// JWTConfig selects the token signing algorithm.
+// HS256 uses app.jwt_secret; RS256 / EdDSA load PEM keys and publish them at /.well-known/jwks.json.
+type JWTConfig struct {
+	Algorithm    string         `mapstructure:"algorithm"`      // HS256 | RS256 | EdDSA
+	SigningKeyID string         `mapstructure:"signing_key_id"` // 用于签发新令牌的 kid
+	Keys         []JWTKeyConfig `mapstructure:"keys"`           // 所有可用于验证的密钥，轮换时保留旧公钥
+}
+
+// IsHMAC reports whether tokens are signed with the shared secret (HS256)
+func (c JWTConfig) IsHMAC() bool {
+	return c.Algorithm == "" || c.Algorithm == "HS256"
+}
+
+// JWTKeyConfig points to the PEM files of one signing key.
+type JWTKeyConfig struct {
+	ID             string `mapstructure:"id"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	PublicKeyFile  string `mapstructure:"public_key_file"` // 已退役、只用于验证的密钥只需公钥
+}
+
+// MFAConfig holds TOTP two-factor authentication settings.
+type MFAConfig struct {
+	Issuer        string        `mapstructure:"issuer"`         // 验证器 App 中显示的服务名
+	PendingTTL    time.Duration `mapstructure:"pending_ttl"`    // mfa_pending 令牌有效期
+	MaxAttempts   int           `mapstructure:"max_attempts"`   // 每个 mfa_pending 令牌允许的错误次数
+	RequiredRoles []string      `mapstructure:"required_roles"` // 持有这些角色的用户必须启用 MFA
+}
+
+// ImpersonationConfig holds settings for administrators acting as another user.
+type ImpersonationConfig struct {
+	TTL time.Duration `mapstructure:"ttl"` // 模拟登录令牌有效期，不可刷新
+}
+
+// OAuthConfig holds settings for the OAuth endpoints used by backend services.
+type OAuthConfig struct {
+	ClientTokenTTL time.Duration `mapstructure:"client_token_ttl"` // client_credentials 令牌有效期，不可刷新
+}
+
+// FreezeConfig holds settings for frozen accounts.
+type FreezeConfig struct {
+	SweepInterval time.Duration `mapstructure:"sweep_interval"` // 检查临时冻结是否到期的间隔
+}
+
+// RoleExpiryConfig holds settings for time-bound role assignments.
+type RoleExpiryConfig struct {
+	SweepInterval time.Duration `mapstructure:"sweep_interval"` // 清理已到期角色分配的间隔
+}
+
+// PermCacheConfig holds settings for the cache of computed user permissions.
+type PermCacheConfig struct {
+	Store string        `mapstructure:"store"`  // cache: 走 CacheBackend（Redis / 内存）；sql: user_permission_caches 表
+	TTL   time.Duration `mapstructure:"ttl"`    // 共享缓存中的有效期
+	L1TTL time.Duration `mapstructure:"l1_ttl"` // 进程内缓存的有效期，也是丢失失效通知时最长的过期时间；0 关闭
+}
+
+// PasswordPolicyConfig holds the rules for new passwords (register and password resets).
+type PasswordPolicyConfig struct {
+	MinLength     int  `mapstructure:"min_length"`
+	MaxLength     int  `mapstructure:"max_length"`
+	MinClasses    int  `mapstructure:"min_classes"`    // 小写、大写、数字、符号中至少包含几类
+	CheckBreached bool `mapstructure:"check_breached"` // 拒绝内置常见 / 泄露密码列表中的密码
+	HistorySize   int  `mapstructure:"history_size"`   // 不能与最近几次用过的密码相同，0 表示不检查
+}
+
+// LoginGuardConfig holds brute-force protection settings for password login.
+// Each lockout of the same account or IP lasts twice as long as the previous one, up to MaxLockout.
+type LoginGuardConfig struct {
+	AccountMaxFailures int           `mapstructure:"account_max_failures"` // 同一账号在窗口内允许的失败次数
+	IPMaxFailures      int           `mapstructure:"ip_max_failures"`      // 同一 IP 在窗口内允许的失败次数
+	FailureWindow      time.Duration `mapstructure:"failure_window"`       // 失败计数的滑动窗口
+	BaseLockout        time.Duration `mapstructure:"base_lockout"`         // 首次锁定时长
+	MaxLockout         time.Duration `mapstructure:"max_lockout"`
+}
+
+// QRLoginConfig holds QR code cross-device login settings.
+type QRLoginConfig struct {
+	TTL time.Duration `mapstructure:"ttl"` // 二维码有效期
+}
+
+// MagicLinkConfig holds email magic-link login settings.
+type MagicLinkConfig struct {
+	TTL      time.Duration `mapstructure:"ttl"`      // 链接有效期
+	URL      string        `mapstructure:"url"`      // 前端落地页，邮件中的链接为 url?token=...
+	Cooldown time.Duration `mapstructure:"cooldown"` // 同一邮箱两次发送的最小间隔
+	PerHour  int           `mapstructure:"per_hour"` // 每个邮箱每小时最多发送次数
+}
+
+// WebAuthnConfig holds passkey (WebAuthn) settings.
+type WebAuthnConfig struct {
+	RPID    string        `mapstructure:"rp_id"`   // 站点域名，passkey 与之绑定，上线后不能再改
+	RPName  string        `mapstructure:"rp_name"` // 系统弹窗中显示的名称
+	Origins []string      `mapstructure:"origins"` // 发起 WebAuthn 请求的前端 origin
+	Timeout time.Duration `mapstructure:"timeout"` // 一次注册或登录仪式的时限
+}
+
+// OIDCConfig holds OpenID Connect social login settings.
+type OIDCConfig struct {
+	StateTTL  time.Duration        `mapstructure:"state_ttl"` // 授权请求（state / nonce / PKCE verifier）的有效期
+	Providers []OIDCProviderConfig `mapstructure:"providers"`
+}
+
+// OIDCProviderConfig describes one identity provider.
+type OIDCProviderConfig struct {
+	Name         string   `mapstructure:"name"` // 路由中的标识，如 /auth/oidc/{name}/authorize
+	DisplayName  string   `mapstructure:"display_name"`
+	Issuer       string   `mapstructure:"issuer"` // 通过 {issuer}/.well-known/openid-configuration 自动发现
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"` // 支持 ${ENV_VAR} 引用环境变量
+	RedirectURL  string   `mapstructure:"redirect_url"`
+	Scopes       []string `mapstructure:"scopes"`
 }
 
 // CORSConfig holds CORS middleware configuration.
@@ -28,6 +184,8 @@ type CORSConfig struct {
 	AllowOrigins []string `mapstructure:"allow_origins"`
 	AllowMethods []string `mapstructure:"allow_methods"`
 	AllowHeaders []string `mapstructure:"allow_headers"`
+	// 允许跨域请求携带 Cookie（邮件登录链接的浏览器绑定依赖它），此时 allow_origins 不能为 *
+	AllowCredentials bool `mapstructure:"allow_credentials"`
 }
 
 // RateLimitConfig holds rate limiter configuration.
@@ -242,6 +400,7 @@ func bindEnvVariables() {
 	viper.BindEnv("cors.allow_origins", "CORS_ALLOW_ORIGINS")
 	viper.BindEnv("cors.allow_methods", "CORS_ALLOW_METHODS")
 	viper.BindEnv("cors.allow_headers", "CORS_ALLOW_HEADERS")
+	viper.BindEnv("cors.allow_credentials", "CORS_ALLOW_CREDENTIALS")
 
 	viper.BindEnv("rate_limit.global_per_minute", "RATE_LIMIT_GLOBAL_PER_MINUTE")
 	viper.BindEnv("rate_limit.user_per_minute", "RATE_LIMIT_USER_PER_MINUTE")
@@ -249,6 +408,52 @@ func bindEnvVariables() {
 	viper.BindEnv("rate_limit.upload_per_minute", "RATE_LIMIT_UPLOAD_PER_MINUTE")
 	viper.BindEnv("rate_limit.fallback_rps", "RATE_LIMIT_FALLBACK_RPS")
 	viper.BindEnv("rate_limit.fallback_burst", "RATE_LIMIT_FALLBACK_BURST")
+
+	viper.BindEnv("mail.host", "MAIL_HOST")
+	viper.BindEnv("mail.port", "MAIL_PORT")
+	viper.BindEnv("mail.username", "MAIL_USERNAME")
+	viper.BindEnv("mail.password", "MAIL_PASSWORD")
+	viper.BindEnv("mail.from", "MAIL_FROM")
+	viper.BindEnv("mail.outbox_dir", "MAIL_OUTBOX_DIR")
+
+	viper.BindEnv("sms.gateway_url", "SMS_GATEWAY_URL")
+	viper.BindEnv("sms.api_key", "SMS_API_KEY")
+	viper.BindEnv("sms.sign_name", "SMS_SIGN_NAME")
+
+	viper.BindEnv("jwt.algorithm", "JWT_ALGORITHM")
+	viper.BindEnv("jwt.signing_key_id", "JWT_SIGNING_KEY_ID")
+
+	viper.BindEnv("mfa.issuer", "MFA_ISSUER")
+	viper.BindEnv("mfa.required_roles", "MFA_REQUIRED_ROLES")
+
+	viper.BindEnv("qr_login.ttl", "QR_LOGIN_TTL")
+
+	viper.BindEnv("magic_link.ttl", "MAGIC_LINK_TTL")
+	viper.BindEnv("magic_link.url", "MAGIC_LINK_URL")
+	viper.BindEnv("magic_link.cooldown", "MAGIC_LINK_COOLDOWN")
+	viper.BindEnv("magic_link.per_hour", "MAGIC_LINK_PER_HOUR")
+
+	viper.BindEnv("webauthn.rp_id", "WEBAUTHN_RP_ID")
+	viper.BindEnv("webauthn.rp_name", "WEBAUTHN_RP_NAME")
+	viper.BindEnv("webauthn.origins", "WEBAUTHN_ORIGINS")
+
+	viper.BindEnv("password_policy.min_length", "PASSWORD_MIN_LENGTH")
+	viper.BindEnv("password_policy.max_length", "PASSWORD_MAX_LENGTH")
+	viper.BindEnv("password_policy.min_classes", "PASSWORD_MIN_CLASSES")
+	viper.BindEnv("password_policy.check_breached", "PASSWORD_CHECK_BREACHED")
+	viper.BindEnv("password_policy.history_size", "PASSWORD_HISTORY_SIZE")
+	viper.BindEnv("impersonation.ttl", "IMPERSONATION_TTL")
+	viper.BindEnv("oauth.client_token_ttl", "OAUTH_CLIENT_TOKEN_TTL")
+	viper.BindEnv("freeze.sweep_interval", "FREEZE_SWEEP_INTERVAL")
+	viper.BindEnv("permission_cache.store", "PERMISSION_CACHE_STORE")
+	viper.BindEnv("permission_cache.ttl", "PERMISSION_CACHE_TTL")
+	viper.BindEnv("permission_cache.l1_ttl", "PERMISSION_CACHE_L1_TTL")
+	viper.BindEnv("role_expiry.sweep_interval", "ROLE_EXPIRY_SWEEP_INTERVAL")
+
+	viper.BindEnv("login_guard.account_max_failures", "LOGIN_GUARD_ACCOUNT_MAX_FAILURES")
+	viper.BindEnv("login_guard.ip_max_failures", "LOGIN_GUARD_IP_MAX_FAILURES")
+	viper.BindEnv("login_guard.base_lockout", "LOGIN_GUARD_BASE_LOCKOUT")
+	viper.BindEnv("login_guard.max_lockout", "LOGIN_GUARD_MAX_LOCKOUT")
 }
 
 func setDefaults() {
@@ -274,6 +479,55 @@ func setDefaults() {
 	viper.SetDefault("rate_limit.fallback_rps", 100)
 	viper.SetDefault("rate_limit.fallback_burst", 200)
 
+	viper.SetDefault("verify.code_ttl", 5*time.Minute)
+	viper.SetDefault("verify.cooldown", 60*time.Second)
+	viper.SetDefault("verify.max_attempts", 5)
+	viper.SetDefault("verify.account_per_day", 10)
+	viper.SetDefault("verify.ip_per_hour", 30)
+
+	viper.SetDefault("mail.port", 465)
+	viper.SetDefault("mail.from_name", "go-api-starter")
+
+	viper.SetDefault("jwt.algorithm", "HS256")
+
+	viper.SetDefault("mfa.issuer", "go-api-starter")
+	viper.SetDefault("mfa.pending_ttl", 5*time.Minute)
+	viper.SetDefault("mfa.max_attempts", 5)
+	viper.SetDefault("mfa.required_roles", []string{})
+
+	viper.SetDefault("oidc.state_ttl", 10*time.Minute)
+
+	viper.SetDefault("qr_login.ttl", 2*time.Minute)
+
+	viper.SetDefault("magic_link.ttl", 10*time.Minute)
+	viper.SetDefault("magic_link.url", "http://localhost:3000/login/magic-link")
+	viper.SetDefault("magic_link.cooldown", 60*time.Second)
+	viper.SetDefault("magic_link.per_hour", 5)
+
+	viper.SetDefault("webauthn.rp_id", "localhost")
+	viper.SetDefault("webauthn.rp_name", "go-api-starter")
+	viper.SetDefault("webauthn.origins", []string{"http://localhost:3000"})
+	viper.SetDefault("webauthn.timeout", 5*time.Minute)
+
+	viper.SetDefault("password_policy.min_length", 8)
+	viper.SetDefault("password_policy.max_length", 128)
+	viper.SetDefault("password_policy.min_classes", 2)
+	viper.SetDefault("password_policy.check_breached", true)
+	viper.SetDefault("password_policy.history_size", 5)
+	viper.SetDefault("impersonation.ttl", 15*time.Minute)
+	viper.SetDefault("oauth.client_token_ttl", time.Hour)
+	viper.SetDefault("freeze.sweep_interval", time.Minute)
+	viper.SetDefault("permission_cache.store", "cache")
+	viper.SetDefault("permission_cache.ttl", time.Hour)
+	viper.SetDefault("permission_cache.l1_ttl", 30*time.Second)
+	viper.SetDefault("role_expiry.sweep_interval", time.Minute)
+
+	viper.SetDefault("login_guard.account_max_failures", 5)
+	viper.SetDefault("login_guard.ip_max_failures", 20)
+	viper.SetDefault("login_guard.failure_window", 15*time.Minute)
+	viper.SetDefault("login_guard.base_lockout", time.Minute)
+	viper.SetDefault("login_guard.max_lockout", time.Hour)
+
 	viper.SetDefault("server.host", "localhost")
 	viper.SetDefault("server.port", "9527")
 	viper.SetDefault("server.mode", "debug")
@@ -44,22 +44,24 @@ func (c *Config) Validate() ValidationErrors {
 
 	// Only enforce strict validation in production
 	if c.App.Env == "production" || c.App.Env == "prod" {
-		// JWT Secret validation
-		if c.App.JWTSecret == "" {
-			errors = append(errors, ValidationError{
-				Field:   "app.jwt_secret",
-				Message: "JWT secret must be set in production",
-			})
-		} else if c.App.JWTSecret == "your-secret-key-change-in-production" {
-			errors = append(errors, ValidationError{
-				Field:   "app.jwt_secret",
-				Message: "JWT secret must be changed from default value in production",
-			})
-		} else if len(c.App.JWTSecret) < 32 {
-			errors = append(errors, ValidationError{
-				Field:   "app.jwt_secret",
-				Message: "JWT secret should be at least 32 characters in production",
-			})
+		// JWT Secret validation (the secret is only used by HS256)
+		if c.JWT.IsHMAC() {
+			if c.App.JWTSecret == "" {
+				errors = append(errors, ValidationError{
+					Field:   "app.jwt_secret",
+					Message: "JWT secret must be set in production",
+				})
+			} else if c.App.JWTSecret == "your-secret-key-change-in-production" {
+				errors = append(errors, ValidationError{
+					Field:   "app.jwt_secret",
+					Message: "JWT secret must be changed from default value in production",
+				})
+			} else if len(c.App.JWTSecret) < 32 {
+				errors = append(errors, ValidationError{
+					Field:   "app.jwt_secret",
+					Message: "JWT secret should be at least 32 characters in production",
+				})
+			}
 		}
 
 		// Database validation for non-SQLite databases
@@ -108,6 +110,45 @@ func (c *Config) Validate() ValidationErrors {
 	}
 
 	// General validation (all environments)
+	switch c.JWT.Algorithm {
+	case "", "HS256":
+	case "RS256", "EdDSA":
+		if c.JWT.SigningKeyID == "" || len(c.JWT.Keys) == 0 {
+			errors = append(errors, ValidationError{
+				Field:   "jwt.keys",
+				Message: "signing_key_id and at least one key are required for " + c.JWT.Algorithm,
+			})
+		}
+	default:
+		errors = append(errors, ValidationError{
+			Field:   "jwt.algorithm",
+			Message: "Algorithm must be HS256, RS256 or EdDSA",
+		})
+	}
+
+	seen := make(map[string]bool, len(c.OIDC.Providers))
+	for i, p := range c.OIDC.Providers {
+		field := fmt.Sprintf("oidc.providers[%d]", i)
+		if p.Name == "" || strings.ContainsAny(p.Name, "/?#% ") {
+			errors = append(errors, ValidationError{
+				Field:   field + ".name",
+				Message: "Provider name is required and must be usable as a URL path segment",
+			})
+		} else if seen[p.Name] {
+			errors = append(errors, ValidationError{
+				Field:   field + ".name",
+				Message: "Duplicate provider name " + p.Name,
+			})
+		}
+		seen[p.Name] = true
+		if p.Issuer == "" || p.ClientID == "" || p.RedirectURL == "" {
+			errors = append(errors, ValidationError{
+				Field:   field,
+				Message: "issuer, client_id and redirect_url are required",
+			})
+		}
+	}
+
 	if port, convErr := strconv.Atoi(c.Server.Port); convErr != nil || port <= 0 || port > 65535 {
 		errors = append(errors, ValidationError{
 			Field:   "server.port",
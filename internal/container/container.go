@@ -15,6 +15,7 @@ import (
 	"go-api-starter/internal/service"
 	"go-api-starter/pkg/auth"
 	"go-api-starter/pkg/cache"
+	"go-api-starter/pkg/notify"
 )
 
 // Container is the dependency injection container for the slim starter.
@@ -24,38 +25,92 @@ type Container struct {
 	logger *zap.Logger
 
 	// Repositories
-	userRepo          repository.UserRepositoryInterface
-	userRepoOnce      sync.Once
-	permRepo          repository.PermissionRepositoryInterface
-	permRepoOnce      sync.Once
-	roleRepo          repository.RoleRepositoryInterface
-	roleRepoOnce      sync.Once
-	spaceRepo         repository.PermissionSpaceRepositoryInterface
-	spaceRepoOnce     sync.Once
-	userRoleRepo      repository.UserRoleRepositoryInterface
-	userRoleRepoOnce  sync.Once
-	rolePermRepo      repository.RolePermissionRepositoryInterface
-	rolePermRepoOnce  sync.Once
-	cacheRepo         repository.UserPermissionCacheRepositoryInterface
-	cacheRepoOnce     sync.Once
-	multipartRepo     repository.MultipartRepositoryInterface
-	multipartRepoOnce sync.Once
-	fileRepo          repository.FileRepositoryInterface
-	fileRepoOnce      sync.Once
+	userRepo                   repository.UserRepositoryInterface
+	userRepoOnce               sync.Once
+	permRepo                   repository.PermissionRepositoryInterface
+	permRepoOnce               sync.Once
+	roleRepo                   repository.RoleRepositoryInterface
+	roleRepoOnce               sync.Once
+	spaceRepo                  repository.PermissionSpaceRepositoryInterface
+	spaceRepoOnce              sync.Once
+	userRoleRepo               repository.UserRoleRepositoryInterface
+	userRoleRepoOnce           sync.Once
+	rolePermRepo               repository.RolePermissionRepositoryInterface
+	rolePermRepoOnce           sync.Once
+	cacheRepo                  repository.UserPermissionCacheRepositoryInterface
+	cacheRepoOnce              sync.Once
+	multipartRepo              repository.MultipartRepositoryInterface
+	multipartRepoOnce          sync.Once
+	fileRepo                   repository.FileRepositoryInterface
+	fileRepoOnce               sync.Once
+	refreshTokenRepo           repository.RefreshTokenRepositoryInterface
+	refreshTokenRepoOnce       sync.Once
+	sessionRepo                repository.SessionRepositoryInterface
+	sessionRepoOnce            sync.Once
+	mfaRepo                    repository.MFARepositoryInterface
+	mfaRepoOnce                sync.Once
+	identityRepo               repository.IdentityRepositoryInterface
+	identityRepoOnce           sync.Once
+	apiKeyRepo                 repository.APIKeyRepositoryInterface
+	apiKeyRepoOnce             sync.Once
+	auditLogRepo               repository.AuditLogRepositoryInterface
+	auditLogRepoOnce           sync.Once
+	passwordHistoryRepo        repository.PasswordHistoryRepositoryInterface
+	passwordHistoryRepoOnce    sync.Once
+	webAuthnCredentialRepo     repository.WebAuthnCredentialRepositoryInterface
+	webAuthnCredentialRepoOnce sync.Once
+	oauthClientRepo            repository.OAuthClientRepositoryInterface
+	oauthClientRepoOnce        sync.Once
 
 	// Services
-	authService        service.AuthServiceInterface
-	authServiceOnce    sync.Once
-	userService        service.UserServiceInterface
-	userServiceOnce    sync.Once
-	permService        service.PermissionServiceInterface
-	permServiceOnce    sync.Once
-	ossService         service.OSSServiceInterface
-	ossServiceOnce     sync.Once
-	fileService        service.FileServiceInterface
-	fileServiceOnce    sync.Once
-	tokenBlacklist     service.TokenBlacklist
-	tokenBlacklistOnce sync.Once
+	authService              service.AuthServiceInterface
+	authServiceOnce          sync.Once
+	userService              service.UserServiceInterface
+	userServiceOnce          sync.Once
+	permService              service.PermissionServiceInterface
+	permServiceOnce          sync.Once
+	ossService               service.OSSServiceInterface
+	ossServiceOnce           sync.Once
+	fileService              service.FileServiceInterface
+	fileServiceOnce          sync.Once
+	tokenBlacklist           service.TokenBlacklist
+	tokenBlacklistOnce       sync.Once
+	codeService              *service.VerificationCodeService
+	codeServiceOnce          sync.Once
+	codeSender               service.CodeSender
+	codeSenderOnce           sync.Once
+	emailSender              notify.EmailSender
+	emailSenderOnce          sync.Once
+	sessionService           *service.SessionService
+	sessionServiceOnce       sync.Once
+	mfaService               *service.MFAService
+	mfaServiceOnce           sync.Once
+	oidcService              *service.OIDCService
+	oidcServiceOnce          sync.Once
+	qrLoginService           *service.QRLoginService
+	qrLoginServiceOnce       sync.Once
+	apiKeyService            service.APIKeyServiceInterface
+	apiKeyServiceOnce        sync.Once
+	auditService             *service.AuditService
+	auditServiceOnce         sync.Once
+	loginGuard               *service.LoginGuard
+	loginGuardOnce           sync.Once
+	passwordService          *service.PasswordService
+	passwordServiceOnce      sync.Once
+	impersonationService     *service.ImpersonationService
+	impersonationServiceOnce sync.Once
+	freezeService            *service.FreezeService
+	freezeServiceOnce        sync.Once
+	roleExpiryService        *service.RoleExpiryService
+	roleExpiryServiceOnce    sync.Once
+	contactChangeService     *service.ContactChangeService
+	contactChangeServiceOnce sync.Once
+	passkeyService           *service.PasskeyService
+	passkeyServiceOnce       sync.Once
+	magicLinkService         *service.MagicLinkService
+	magicLinkServiceOnce     sync.Once
+	oauthService             service.OAuthServiceInterface
+	oauthServiceOnce         sync.Once
 
 	// Permission components
 	permManager     *service.BitPermissionManager
@@ -88,6 +143,14 @@ type Container struct {
 	ossHandlerOnce    sync.Once
 	healthHandler     *handler.HealthHandler
 	healthHandlerOnce sync.Once
+	jwksHandler       *handler.JWKSHandler
+	jwksHandlerOnce   sync.Once
+	apiKeyHandler     *handler.APIKeyHandler
+	apiKeyHandlerOnce sync.Once
+	auditHandler      *handler.AuditHandler
+	auditHandlerOnce  sync.Once
+	oauthHandler      *handler.OAuthHandler
+	oauthHandlerOnce  sync.Once
 
 	// JWT manager
 	jwtManager     *auth.JWTManager
@@ -127,15 +190,25 @@ func (c *Container) AuthService() service.AuthServiceInterface {
 	c.authServiceOnce.Do(func() {
 		c.authService = service.NewAuthService(
 			c.UserRepository(), c.JWTManager(), c.TokenBlacklist(),
+			c.VerificationCodeService(), c.RefreshTokenRepository(), c.SessionService(),
+			c.MFAService(), c.OIDCService(), c.QRLoginService(), c.LoginGuard(), c.PasswordService(),
+			c.PasskeyService(), c.MagicLinkService(), c.PermissionChecker(),
 		)
 	})
 	return c.authService
 }
 
+func (c *Container) APIKeyService() service.APIKeyServiceInterface {
+	c.apiKeyServiceOnce.Do(func() {
+		c.apiKeyService = service.NewAPIKeyService(c.APIKeyRepository(), c.PermissionChecker())
+	})
+	return c.apiKeyService
+}
+
 func (c *Container) UserService() service.UserServiceInterface {
 	c.userServiceOnce.Do(func() {
 		c.userService = service.NewUserService(
-			c.UserRepository(), c.FileRepository(),
+			c.UserRepository(), c.FileRepository(), c.LoginGuard(), c.AuditService(), c.ContactChangeService(),
 		)
 	})
 	return c.userService
@@ -143,8 +216,12 @@ func (c *Container) UserService() service.UserServiceInterface {
 
 func (c *Container) PermissionCache() *service.PermissionCache {
 	c.permCacheOnce.Do(func() {
+		var store service.PermissionStore = service.NewBackendPermissionStore(c.CacheBackend())
+		if c.config.PermCache.Store == "sql" {
+			store = service.NewSQLPermissionStore(c.UserPermissionCacheRepository())
+		}
 		c.permCache = service.NewPermissionCache(
-			c.UserPermissionCacheRepository(), time.Hour,
+			store, c.PubSub(), c.config.PermCache.TTL, c.config.PermCache.L1TTL,
 		)
 	})
 	return c.permCache
@@ -154,6 +231,7 @@ func (c *Container) PermissionChecker() *service.PermissionChecker {
 	c.permCheckerOnce.Do(func() {
 		c.permChecker = service.NewPermissionChecker(
 			c.PermissionRepository(),
+			c.RoleRepository(),
 			c.RolePermissionRepository(),
 			c.UserRoleRepository(),
 			c.PermissionCache(),
@@ -170,7 +248,7 @@ func (c *Container) BitPermissionManager() *service.BitPermissionManager {
 			c.RoleRepository().(*repository.RoleRepository),
 			c.UserRoleRepository().(*repository.UserRoleRepository),
 			c.RolePermissionRepository().(*repository.RolePermissionRepository),
-			c.UserPermissionCacheRepository().(*repository.UserPermissionCacheRepository),
+			c.PermissionCache(),
 		)
 	})
 	return c.permManager
@@ -209,6 +287,185 @@ func (c *Container) TokenBlacklist() service.TokenBlacklist {
 	return c.tokenBlacklist
 }
 
+func (c *Container) VerificationCodeService() *service.VerificationCodeService {
+	c.codeServiceOnce.Do(func() {
+		c.codeService = service.NewVerificationCodeService(
+			c.CacheBackend(), c.CodeSender(), c.UserRepository(), c.config.Verify,
+		)
+	})
+	return c.codeService
+}
+
+func (c *Container) SessionService() *service.SessionService {
+	c.sessionServiceOnce.Do(func() {
+		c.sessionService = service.NewSessionService(c.SessionRepository(), c.RefreshTokenRepository())
+	})
+	return c.sessionService
+}
+
+func (c *Container) MFAService() *service.MFAService {
+	c.mfaServiceOnce.Do(func() {
+		c.mfaService = service.NewMFAService(
+			c.MFARepository(), c.UserRepository(), c.UserRoleRepository(),
+			c.JWTManager(), c.CacheBackend(), c.config.MFA,
+		)
+	})
+	return c.mfaService
+}
+
+func (c *Container) OIDCService() *service.OIDCService {
+	c.oidcServiceOnce.Do(func() {
+		c.oidcService = service.NewOIDCService(
+			c.config.OIDC, c.IdentityRepository(), c.UserRepository(), c.CacheBackend(),
+		)
+	})
+	return c.oidcService
+}
+
+func (c *Container) QRLoginService() *service.QRLoginService {
+	c.qrLoginServiceOnce.Do(func() {
+		c.qrLoginService = service.NewQRLoginService(c.CacheBackend(), c.config.QRLogin)
+	})
+	return c.qrLoginService
+}
+
+func (c *Container) PasskeyService() *service.PasskeyService {
+	c.passkeyServiceOnce.Do(func() {
+		c.passkeyService = service.NewPasskeyService(
+			c.WebAuthnCredentialRepository(), c.UserRepository(), c.CacheBackend(), c.config.WebAuthn,
+		)
+	})
+	return c.passkeyService
+}
+
+func (c *Container) MagicLinkService() *service.MagicLinkService {
+	c.magicLinkServiceOnce.Do(func() {
+		c.magicLinkService = service.NewMagicLinkService(
+			c.UserRepository(), c.CacheBackend(), c.EmailSender(), c.config.MagicLink,
+		)
+	})
+	return c.magicLinkService
+}
+
+func (c *Container) OAuthService() service.OAuthServiceInterface {
+	c.oauthServiceOnce.Do(func() {
+		c.oauthService = service.NewOAuthService(
+			c.OAuthClientRepository(), c.JWTManager(), c.TokenBlacklist(), c.RefreshTokenRepository(),
+			c.SessionService(), c.UserRepository(), c.PermissionChecker(), c.config.OAuth,
+		)
+	})
+	return c.oauthService
+}
+
+// FileOwnerResolver and UserOwnerResolver look up resource owners for RequireResourcePermission
+func (c *Container) FileOwnerResolver() *service.FileOwnerResolver {
+	return service.NewFileOwnerResolver(c.FileRepository())
+}
+
+func (c *Container) UserOwnerResolver() *service.UserOwnerResolver {
+	return service.NewUserOwnerResolver(c.UserRepository())
+}
+
+func (c *Container) AuditService() *service.AuditService {
+	c.auditServiceOnce.Do(func() {
+		c.auditService = service.NewAuditService(c.AuditLogRepository())
+	})
+	return c.auditService
+}
+
+func (c *Container) LoginGuard() *service.LoginGuard {
+	c.loginGuardOnce.Do(func() {
+		c.loginGuard = service.NewLoginGuard(c.CacheBackend(), c.AuditService(), c.config.LoginGuard)
+	})
+	return c.loginGuard
+}
+
+func (c *Container) PasswordService() *service.PasswordService {
+	c.passwordServiceOnce.Do(func() {
+		c.passwordService = service.NewPasswordService(c.PasswordHistoryRepository(), c.config.Password)
+	})
+	return c.passwordService
+}
+
+func (c *Container) ImpersonationService() *service.ImpersonationService {
+	c.impersonationServiceOnce.Do(func() {
+		c.impersonationService = service.NewImpersonationService(
+			c.UserRepository(), c.JWTManager(), c.PermissionChecker(), c.AuditService(), c.config.Impersonation,
+		)
+	})
+	return c.impersonationService
+}
+
+func (c *Container) FreezeService() *service.FreezeService {
+	c.freezeServiceOnce.Do(func() {
+		c.freezeService = service.NewFreezeService(
+			c.UserRepository(), c.SessionService(), c.PermissionChecker(), c.AuditService(), c.config.Freeze,
+		)
+	})
+	return c.freezeService
+}
+
+func (c *Container) RoleExpiryService() *service.RoleExpiryService {
+	c.roleExpiryServiceOnce.Do(func() {
+		c.roleExpiryService = service.NewRoleExpiryService(
+			c.UserRoleRepository(), c.PermissionCache(), c.AuditService(), c.config.RoleExpiry,
+		)
+	})
+	return c.roleExpiryService
+}
+
+func (c *Container) ContactChangeService() *service.ContactChangeService {
+	c.contactChangeServiceOnce.Do(func() {
+		c.contactChangeService = service.NewContactChangeService(
+			c.CacheBackend(), c.VerificationCodeService(), c.CodeSender(), c.UserRepository(), c.AuditService(), c.config.Verify,
+		)
+	})
+	return c.contactChangeService
+}
+
+// EmailSender sends over SMTP. Without an SMTP host emails go to mail.outbox_dir, or to the log when that is empty too.
+func (c *Container) EmailSender() notify.EmailSender {
+	c.emailSenderOnce.Do(func() {
+		switch mail := c.config.Mail; {
+		case mail.Host != "":
+			c.emailSender = notify.NewSMTPSender(notify.SMTPConfig{
+				Host:     mail.Host,
+				Port:     mail.Port,
+				Username: mail.Username,
+				Password: mail.Password,
+				From:     mail.From,
+				FromName: mail.FromName,
+			})
+		case mail.OutboxDir != "":
+			log.Printf("Mail not configured, emails will be written to %s", mail.OutboxDir)
+			c.emailSender = notify.NewFileSender(mail.OutboxDir)
+		default:
+			log.Printf("Mail not configured, emails will be logged to console")
+			c.emailSender = service.NewConsoleEmailSender()
+		}
+	})
+	return c.emailSender
+}
+
+// CodeSender delivers codes through EmailSender, and by SMS gateway when configured, otherwise logs them.
+func (c *Container) CodeSender() service.CodeSender {
+	c.codeSenderOnce.Do(func() {
+		smsSender := service.CodeSender(service.NewConsoleCodeSender())
+		if sms := c.config.SMS; sms.GatewayURL != "" {
+			smsSender = service.NewSMSCodeSender(notify.NewHTTPSMSSender(notify.HTTPSMSConfig{
+				GatewayURL: sms.GatewayURL,
+				APIKey:     sms.APIKey,
+				SignName:   sms.SignName,
+			}))
+		} else {
+			log.Printf("SMS gateway not configured, SMS verification codes will be logged to console")
+		}
+
+		c.codeSender = service.NewAccountCodeSender(service.NewEmailCodeSender(c.EmailSender()), smsSender)
+	})
+	return c.codeSender
+}
+
 // ========== Infrastructure ==========
 
 func (c *Container) JWTSecret() string {
@@ -219,6 +476,7 @@ func (c *Container) JWTSecret() string {
 	return jwtSecret
 }
 
+// JWTManager signs with HS256 and the shared secret, or with the PEM keys of jwt.keys for RS256 / EdDSA.
 func (c *Container) JWTManager() *auth.JWTManager {
 	c.jwtManagerOnce.Do(func() {
 		c.jwtManager = auth.NewJWTManager(
@@ -226,6 +484,19 @@ func (c *Container) JWTManager() *auth.JWTManager {
 			c.config.App.AccessTokenDays,
 			c.config.App.RefreshTokenDays,
 		)
+		if c.config.JWT.IsHMAC() {
+			return
+		}
+
+		files := make([]auth.KeyFile, 0, len(c.config.JWT.Keys))
+		for _, k := range c.config.JWT.Keys {
+			files = append(files, auth.KeyFile{ID: k.ID, PrivateKeyFile: k.PrivateKeyFile, PublicKeyFile: k.PublicKeyFile})
+		}
+		keys, err := auth.LoadKeySet(c.config.JWT.Algorithm, c.config.JWT.SigningKeyID, files)
+		if err != nil {
+			log.Fatalf("Failed to load JWT keys: %v", err)
+		}
+		c.jwtManager = c.jwtManager.WithKeySet(keys)
 	})
 	return c.jwtManager
 }
@@ -270,6 +541,15 @@ func (c *Container) CacheBackend() cache.CacheBackend {
 	return c.cacheBackend
 }
 
+// PubSub returns Redis pub/sub when Redis is available, otherwise an in-process one
+// that only reaches subscribers of this instance
+func (c *Container) PubSub() cache.PubSub {
+	if redisCache := c.RedisCache(); redisCache != nil {
+		return redisCache
+	}
+	return c.MemoryCache()
+}
+
 func (c *Container) RateLimiter() *middleware.RedisRateLimiter {
 	c.rateLimiterOnce.Do(func() {
 		c.rateLimiter = middleware.NewRedisRateLimiter(c.CacheBackend(), 100, time.Minute)
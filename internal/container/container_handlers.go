@@ -15,7 +15,7 @@ func (c *Container) AuthHandler() *handler.AuthHandler {
 
 func (c *Container) UserHandler() *handler.UserHandler {
 	c.userHandlerOnce.Do(func() {
-		c.userHandler = handler.NewUserHandler(c.UserService())
+		c.userHandler = handler.NewUserHandler(c.UserService(), c.ImpersonationService(), c.ContactChangeService(), c.FreezeService())
 	})
 	return c.userHandler
 }
@@ -42,3 +42,31 @@ func (c *Container) HealthHandler() *handler.HealthHandler {
 	})
 	return c.healthHandler
 }
+
+func (c *Container) JWKSHandler() *handler.JWKSHandler {
+	c.jwksHandlerOnce.Do(func() {
+		c.jwksHandler = handler.NewJWKSHandler(c.JWTManager())
+	})
+	return c.jwksHandler
+}
+
+func (c *Container) APIKeyHandler() *handler.APIKeyHandler {
+	c.apiKeyHandlerOnce.Do(func() {
+		c.apiKeyHandler = handler.NewAPIKeyHandler(c.APIKeyService())
+	})
+	return c.apiKeyHandler
+}
+
+func (c *Container) AuditHandler() *handler.AuditHandler {
+	c.auditHandlerOnce.Do(func() {
+		c.auditHandler = handler.NewAuditHandler(c.AuditService(), c.UserService())
+	})
+	return c.auditHandler
+}
+
+func (c *Container) OAuthHandler() *handler.OAuthHandler {
+	c.oauthHandlerOnce.Do(func() {
+		c.oauthHandler = handler.NewOAuthHandler(c.OAuthService())
+	})
+	return c.oauthHandler
+}
@@ -68,3 +68,66 @@ func (c *Container) FileRepository() repository.FileRepositoryInterface {
 	})
 	return c.fileRepo
 }
+
+func (c *Container) RefreshTokenRepository() repository.RefreshTokenRepositoryInterface {
+	c.refreshTokenRepoOnce.Do(func() {
+		c.refreshTokenRepo = repository.NewRefreshTokenRepository(c.db)
+	})
+	return c.refreshTokenRepo
+}
+
+func (c *Container) SessionRepository() repository.SessionRepositoryInterface {
+	c.sessionRepoOnce.Do(func() {
+		c.sessionRepo = repository.NewSessionRepository(c.db)
+	})
+	return c.sessionRepo
+}
+
+func (c *Container) MFARepository() repository.MFARepositoryInterface {
+	c.mfaRepoOnce.Do(func() {
+		c.mfaRepo = repository.NewMFARepository(c.db)
+	})
+	return c.mfaRepo
+}
+
+func (c *Container) IdentityRepository() repository.IdentityRepositoryInterface {
+	c.identityRepoOnce.Do(func() {
+		c.identityRepo = repository.NewIdentityRepository(c.db)
+	})
+	return c.identityRepo
+}
+
+func (c *Container) APIKeyRepository() repository.APIKeyRepositoryInterface {
+	c.apiKeyRepoOnce.Do(func() {
+		c.apiKeyRepo = repository.NewAPIKeyRepository(c.db)
+	})
+	return c.apiKeyRepo
+}
+
+func (c *Container) AuditLogRepository() repository.AuditLogRepositoryInterface {
+	c.auditLogRepoOnce.Do(func() {
+		c.auditLogRepo = repository.NewAuditLogRepository(c.db)
+	})
+	return c.auditLogRepo
+}
+
+func (c *Container) PasswordHistoryRepository() repository.PasswordHistoryRepositoryInterface {
+	c.passwordHistoryRepoOnce.Do(func() {
+		c.passwordHistoryRepo = repository.NewPasswordHistoryRepository(c.db)
+	})
+	return c.passwordHistoryRepo
+}
+
+func (c *Container) WebAuthnCredentialRepository() repository.WebAuthnCredentialRepositoryInterface {
+	c.webAuthnCredentialRepoOnce.Do(func() {
+		c.webAuthnCredentialRepo = repository.NewWebAuthnCredentialRepository(c.db)
+	})
+	return c.webAuthnCredentialRepo
+}
+
+func (c *Container) OAuthClientRepository() repository.OAuthClientRepositoryInterface {
+	c.oauthClientRepoOnce.Do(func() {
+		c.oauthClientRepo = repository.NewOAuthClientRepository(c.db)
+	})
+	return c.oauthClientRepo
+}
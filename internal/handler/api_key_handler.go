@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/service"
+	"go-api-starter/pkg/apperrors"
+	"go-api-starter/pkg/response"
+)
+
+// APIKeyHandler manages the current user's API keys
+type APIKeyHandler struct {
+	apiKeyService service.APIKeyServiceInterface
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler
+func NewAPIKeyHandler(apiKeyService service.APIKeyServiceInterface) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// Create godoc
+// @Summary 创建 API Key
+// @Description 创建长期有效的 API Key，权限范围必须是当前用户已有权限的子集。明文 key 只在本次响应中返回，使用方式：Authorization: ApiKey <key>
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.CreateAPIKeyRequest true "API Key 信息"
+// @Success 201 {object} response.Response{data=model.CreateAPIKeyResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/auth/api-keys [post]
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+	var req model.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	key, err := h.apiKeyService.Create(ctx, userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Created(c, key)
+}
+
+// List godoc
+// @Summary API Key 列表
+// @Description 列出当前用户未撤销、未过期的 API Key（不含明文）
+// @Tags API Key
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.APIKeyResponse}
+// @Failure 401 {object} response.Response
+// @Router /api/v1/auth/api-keys [get]
+func (h *APIKeyHandler) List(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	keys, err := h.apiKeyService.List(ctx, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, keys)
+}
+
+// Revoke godoc
+// @Summary 撤销 API Key
+// @Description 撤销后使用该 key 的请求立即被拒绝
+// @Tags API Key
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "API Key ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/auth/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.apiKeyService.Revoke(ctx, userID, c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "API Key 已撤销"})
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/service"
+	"go-api-starter/pkg/response"
+)
+
+// AuditHandler exposes audit logs to administrators
+type AuditHandler struct {
+	service     service.AuditServiceInterface
+	userService service.UserServiceInterface
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(svc service.AuditServiceInterface, userService service.UserServiceInterface) *AuditHandler {
+	return &AuditHandler{service: svc, userService: userService}
+}
+
+// List godoc
+// @Summary 审计日志列表
+// @Description 分页查询安全审计日志，如登录锁定和解锁
+// @Tags 审计日志
+// @Produce json
+// @Security BearerAuth
+// @Param action query string false "事件类型，例如 auth.account_locked"
+// @Param user_sec_uid query string false "受影响用户的 SecUID"
+// @Param subject query string false "事件对象，例如被锁定的账号或 IP"
+// @Param page query int false "页码（默认：1）"
+// @Param page_size query int false "每页数量（默认：10）"
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/audit-logs [get]
+func (h *AuditHandler) List(c *gin.Context) {
+	p, ok := BindPagination(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	var filter model.AuditLogFilter
+	if action := c.Query("action"); action != "" {
+		filter.Action = &action
+	}
+	if subject := c.Query("subject"); subject != "" {
+		filter.Subject = &subject
+	}
+	if secUID := c.Query("user_sec_uid"); secUID != "" {
+		user, err := h.userService.GetBySecUID(ctx, secUID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		filter.UserID = &user.ID
+	}
+
+	logs, total, err := h.service.List(ctx, filter, p.GetOffset(), p.GetPageSize(), p.GetSort())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result := make([]*model.AuditLogResponse, len(logs))
+	for i := range logs {
+		result[i] = logs[i].ToResponse()
+	}
+	response.SuccessWithPage(c, result, total, p)
+}
@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"errors"
+	"io"
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -12,6 +15,12 @@ import (
 	"go-api-starter/pkg/response"
 )
 
+// 邮件登录链接的 nonce Cookie，只随 /auth/magic-link 下的请求发送
+const (
+	magicLinkCookie     = "magic_link_nonce"
+	magicLinkCookiePath = "/api/v1/auth/magic-link"
+)
+
 type AuthHandler struct {
 	authService service.AuthServiceInterface
 }
@@ -23,9 +32,39 @@ func NewAuthHandler(authService service.AuthServiceInterface) *AuthHandler {
 	}
 }
 
+// SendCode godoc
+// @Summary 发送验证码
+// @Description 向邮箱或手机号发送一次性验证码（用于验证码登录、注册或找回密码），同一账号与 IP 均有频率限制
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.SendCodeRequest true "发送验证码请求数据"
+// @Success 200 {object} response.Response{data=model.SendCodeResponse}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /api/v1/auth/code [post]
+func (h *AuthHandler) SendCode(c *gin.Context) {
+	var req model.SendCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	resp, err := h.authService.SendVerificationCode(ctx, &req, c.ClientIP())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, resp)
+}
+
 // Register godoc
 // @Summary 注册新用户
-// @Description 使用邮箱或手机号 + 密码注册一个新用户，注册成功后自动返回登录令牌
+// @Description 使用邮箱或手机号 + 密码 + 验证码注册一个新用户，注册成功后自动返回登录令牌
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -47,7 +86,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
-	loginResp, err := h.authService.Register(ctx, &req)
+	loginResp, err := h.authService.Register(ctx, &req, GetClientInfo(c))
 	if err != nil {
 		c.Error(err)
 		return
@@ -58,7 +97,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 // Login godoc
 // @Summary 用户登录
-// @Description 使用手机号或邮箱和密码登录
+// @Description 使用手机号或邮箱和密码登录；login_type 为 code 时使用验证码登录。已启用两步验证的账号返回 mfa_required 和 mfa_token
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -77,13 +116,107 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	// 将 account 字段解析到 email 或 mobile
 	req.ResolveAccount()
 
-	if req.Password == "" {
+	if req.LoginType == model.LoginTypeCode {
+		if req.Code == "" {
+			c.Error(apperrors.BadRequestCode(i18n.ErrProvideCode))
+			return
+		}
+	} else if req.Password == "" {
 		c.Error(apperrors.BadRequestCode(i18n.ErrPasswordRequired))
 		return
 	}
 
 	ctx := c.Request.Context()
-	loginResp, err := h.authService.Login(ctx, &req)
+	loginResp, err := h.authService.Login(ctx, &req, GetClientInfo(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, loginResp)
+}
+
+// VerifyMFA godoc
+// @Summary 两步验证登录
+// @Description 登录返回 mfa_required 时，使用 mfa_token 和 TOTP 验证码（或恢复码）换取登录令牌
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.MFAVerifyRequest true "两步验证请求数据"
+// @Success 200 {object} response.Response{data=model.LoginResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /api/v1/auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req model.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	loginResp, err := h.authService.VerifyMFA(ctx, &req, GetClientInfo(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, loginResp)
+}
+
+// OIDCProviders godoc
+// @Summary 第三方登录方式列表
+// @Description 返回已配置的 OpenID Connect 身份提供方
+// @Tags 认证
+// @Produce json
+// @Success 200 {object} response.Response{data=[]model.OIDCProviderResponse}
+// @Router /api/v1/auth/oidc/providers [get]
+func (h *AuthHandler) OIDCProviders(c *gin.Context) {
+	response.Success(c, h.authService.OIDCProviders())
+}
+
+// OIDCAuthorize godoc
+// @Summary 发起第三方登录
+// @Description 生成 state、nonce 和 PKCE 参数，返回身份提供方的授权地址，前端跳转到该地址
+// @Tags 认证
+// @Produce json
+// @Param provider path string true "身份提供方名称"
+// @Success 200 {object} response.Response{data=model.OIDCAuthorizeResponse}
+// @Failure 404 {object} response.Response
+// @Router /api/v1/auth/oidc/{provider}/authorize [get]
+func (h *AuthHandler) OIDCAuthorize(c *gin.Context) {
+	ctx := c.Request.Context()
+	resp, err := h.authService.OIDCAuthorize(ctx, c.Param("provider"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// OIDCCallback godoc
+// @Summary 完成第三方登录
+// @Description 提交身份提供方回调中的 code 和 state，校验 ID Token 后登录；首次登录时按已验证邮箱关联已有账号或创建新账号
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param provider path string true "身份提供方名称"
+// @Param request body model.OIDCCallbackRequest true "回调参数"
+// @Success 200 {object} response.Response{data=model.LoginResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/auth/oidc/{provider}/callback [post]
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	var req model.OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	loginResp, err := h.authService.OIDCLogin(ctx, c.Param("provider"), &req, GetClientInfo(c))
 	if err != nil {
 		c.Error(err)
 		return
@@ -92,9 +225,474 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	response.Success(c, loginResp)
 }
 
+// BeginPasskeyLogin godoc
+// @Summary 开始通行密钥登录
+// @Description 返回 navigator.credentials.get() 所需的 publicKey 参数。可选传入账号，只提示该账号的通行密钥；不传时浏览器列出本站所有可用的通行密钥
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.PasskeyLoginBeginRequest false "登录账号（可选）"
+// @Success 200 {object} response.Response{data=model.PasskeyRequestResponse}
+// @Router /api/v1/auth/passkey/login/begin [post]
+func (h *AuthHandler) BeginPasskeyLogin(c *gin.Context) {
+	var req model.PasskeyLoginBeginRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	resp, err := h.authService.BeginPasskeyLogin(ctx, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// PasskeyLogin godoc
+// @Summary 通行密钥登录
+// @Description 提交 navigator.credentials.get() 返回的凭据（PublicKeyCredential.toJSON()），验证通过后登录，不再要求两步验证
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.PasskeyLoginRequest true "断言结果"
+// @Success 200 {object} response.Response{data=model.LoginResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/auth/passkey/login/finish [post]
+func (h *AuthHandler) PasskeyLogin(c *gin.Context) {
+	var req model.PasskeyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	loginResp, err := h.authService.PasskeyLogin(ctx, &req, GetClientInfo(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, loginResp)
+}
+
+// RequestMagicLink godoc
+// @Summary 发送邮件登录链接
+// @Description 向邮箱发送一次性登录链接，并在响应中设置 HttpOnly Cookie（magic_link_nonce），链接只能在该浏览器中使用。邮箱未注册时同样返回成功但不发送邮件；同一邮箱有发送间隔与每小时次数限制
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.MagicLinkRequest true "邮箱"
+// @Success 200 {object} response.Response{data=model.MagicLinkResponse}
+// @Failure 400 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /api/v1/auth/magic-link [post]
+func (h *AuthHandler) RequestMagicLink(c *gin.Context) {
+	var req model.MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	resp, nonce, err := h.authService.RequestMagicLink(ctx, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setMagicLinkCookie(c, nonce, int(resp.ExpiresIn))
+	response.Success(c, resp)
+}
+
+// MagicLinkLogin godoc
+// @Summary 邮件链接登录
+// @Description 前端落地页从链接中取出 token 提交，请求须带上申请链接时设置的 Cookie。链接只能使用一次；开启了两步验证的账号返回 mfa_required
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.MagicLinkVerifyRequest true "链接中的 token"
+// @Success 200 {object} response.Response{data=model.LoginResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/auth/magic-link/verify [post]
+func (h *AuthHandler) MagicLinkLogin(c *gin.Context) {
+	var req model.MagicLinkVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+	nonce, _ := c.Cookie(magicLinkCookie)
+
+	ctx := c.Request.Context()
+	loginResp, err := h.authService.MagicLinkLogin(ctx, &req, nonce, GetClientInfo(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setMagicLinkCookie(c, "", -1)
+	response.Success(c, loginResp)
+}
+
+// setMagicLinkCookie sets the nonce cookie; maxAge < 0 deletes it
+func setMagicLinkCookie(c *gin.Context, nonce string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(magicLinkCookie, nonce, maxAge, magicLinkCookiePath, "", secure, true)
+}
+
+// CreateQRLogin godoc
+// @Summary 创建扫码登录二维码
+// @Description 桌面端调用，key 用于生成二维码，poll_token 由桌面端保存用于轮询，不要放进二维码
+// @Tags 认证
+// @Produce json
+// @Success 200 {object} response.Response{data=model.QRCreateResponse}
+// @Router /api/v1/auth/qr [post]
+func (h *AuthHandler) CreateQRLogin(c *gin.Context) {
+	ctx := c.Request.Context()
+	resp, err := h.authService.CreateQRLogin(ctx, GetClientInfo(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// PollQRLogin godoc
+// @Summary 轮询扫码登录状态
+// @Description 桌面端轮询，状态为 pending / scanned / confirmed / cancelled / expired；confirmed 时返回登录令牌（仅返回一次）
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.QRPollRequest true "二维码 key 与 poll_token"
+// @Success 200 {object} response.Response{data=model.QRPollResponse}
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/auth/qr/poll [post]
+func (h *AuthHandler) PollQRLogin(c *gin.Context) {
+	var req model.QRPollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequestCode(i18n.ErrQRKeyMissing))
+		return
+	}
+
+	ctx := c.Request.Context()
+	resp, err := h.authService.PollQRLogin(ctx, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// ScanQRLogin godoc
+// @Summary 扫描登录二维码
+// @Description 已登录的手机端扫码后调用，返回发起登录的设备信息供用户确认
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.QRKeyRequest true "二维码 key"
+// @Success 200 {object} response.Response{data=model.QRScanResponse}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/auth/qr/scan [post]
+func (h *AuthHandler) ScanQRLogin(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+	var req model.QRKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequestCode(i18n.ErrQRKeyMissing))
+		return
+	}
+
+	ctx := c.Request.Context()
+	resp, err := h.authService.ScanQRLogin(ctx, userID, req.Key)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// ConfirmQRLogin godoc
+// @Summary 确认扫码登录
+// @Description 手机端确认后，桌面端下一次轮询即获得登录令牌
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.QRKeyRequest true "二维码 key"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/auth/qr/confirm [post]
+func (h *AuthHandler) ConfirmQRLogin(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+	var req model.QRKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequestCode(i18n.ErrQRKeyMissing))
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.authService.ConfirmQRLogin(ctx, userID, req.Key); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "已确认登录"})
+}
+
+// CancelQRLogin godoc
+// @Summary 取消扫码登录
+// @Description 手机端拒绝本次登录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.QRKeyRequest true "二维码 key"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/auth/qr/cancel [post]
+func (h *AuthHandler) CancelQRLogin(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+	var req model.QRKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequestCode(i18n.ErrQRKeyMissing))
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.authService.CancelQRLogin(ctx, userID, req.Key); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "已取消登录"})
+}
+
+// EnrollTOTP godoc
+// @Summary 设置 TOTP 两步验证
+// @Description 生成 TOTP 密钥、otpauth URI（用于生成二维码）和一次性恢复码，需调用 activate 确认后才生效
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.MFAEnrollResponse}
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/auth/mfa/totp/enroll [post]
+func (h *AuthHandler) EnrollTOTP(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	resp, err := h.authService.EnrollTOTP(ctx, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// ActivateTOTP godoc
+// @Summary 启用 TOTP 两步验证
+// @Description 提交验证器 App 中的验证码，确认设置并启用两步验证
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.MFACodeRequest true "TOTP 验证码"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/auth/mfa/totp/activate [post]
+func (h *AuthHandler) ActivateTOTP(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req model.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.authService.ActivateTOTP(ctx, userID, req.Code); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "两步验证已启用"})
+}
+
+// DisableTOTP godoc
+// @Summary 关闭 TOTP 两步验证
+// @Description 提交 TOTP 验证码或恢复码关闭两步验证；角色要求强制 MFA 时不可关闭
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.MFACodeRequest true "TOTP 验证码或恢复码"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/auth/mfa/totp/disable [post]
+func (h *AuthHandler) DisableTOTP(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req model.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.authService.DisableTOTP(ctx, userID, req.Code); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "两步验证已关闭"})
+}
+
+// BeginPasskeyRegistration godoc
+// @Summary 开始注册通行密钥
+// @Description 返回 navigator.credentials.create() 所需的 publicKey 参数，已注册的通行密钥会被排除
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.PasskeyCreationResponse}
+// @Failure 401 {object} response.Response
+// @Router /api/v1/auth/passkeys/register/begin [post]
+func (h *AuthHandler) BeginPasskeyRegistration(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	resp, err := h.authService.BeginPasskeyRegistration(ctx, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// FinishPasskeyRegistration godoc
+// @Summary 完成注册通行密钥
+// @Description 提交 navigator.credentials.create() 返回的凭据（PublicKeyCredential.toJSON()），验证后保存
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.PasskeyRegisterRequest true "通行密钥名称和注册结果"
+// @Success 201 {object} response.Response{data=model.PasskeyResponse}
+// @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/auth/passkeys/register/finish [post]
+func (h *AuthHandler) FinishPasskeyRegistration(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+	var req model.PasskeyRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	passkey, err := h.authService.FinishPasskeyRegistration(ctx, userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Created(c, passkey)
+}
+
+// ListPasskeys godoc
+// @Summary 通行密钥列表
+// @Description 列出当前用户注册的通行密钥
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.PasskeyResponse}
+// @Failure 401 {object} response.Response
+// @Router /api/v1/auth/passkeys [get]
+func (h *AuthHandler) ListPasskeys(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	passkeys, err := h.authService.ListPasskeys(ctx, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, passkeys)
+}
+
+// DeletePasskey godoc
+// @Summary 删除通行密钥
+// @Description 删除后该通行密钥不能再用于登录，设备上保存的密钥需要用户自行删除
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "通行密钥ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/auth/passkeys/{id} [delete]
+func (h *AuthHandler) DeletePasskey(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.authService.DeletePasskey(ctx, userID, c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "通行密钥已删除"})
+}
+
 // RefreshToken godoc
 // @Summary 刷新访问令牌
-// @Description 使用刷新令牌获取新的访问令牌
+// @Description 使用刷新令牌换取新的访问令牌和新的刷新令牌（令牌轮换）。已轮换过的刷新令牌再次使用会导致整个登录会话失效
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -111,21 +709,18 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
-	accessToken, err := h.authService.RefreshToken(ctx, req.RefreshToken)
+	resp, err := h.authService.RefreshToken(ctx, req.RefreshToken)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	response.Success(c, model.RefreshTokenResponse{
-		AccessToken: accessToken,
-		ExpiresIn:   h.authService.AccessTokenExpiresIn(),
-	})
+	response.Success(c, resp)
 }
 
 // ResetPassword godoc
 // @Summary 重置用户密码（管理员）
-// @Description 重置指定用户的密码（仅管理员）
+// @Description 重置指定用户的密码（需 user.update:any；拥有 role.manage 的账号只能由同样拥有该权限的管理员重置）
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -134,9 +729,14 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 // @Param request body model.ResetPasswordRequest true "重置密码请求数据"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Router /api/v1/auth/reset-password/{id} [post]
 func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	actorID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
 	userIDStr := c.Param("id")
 	userID, err := strconv.ParseUint(userIDStr, 10, 32)
 	if err != nil {
@@ -151,7 +751,7 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
-	if err := h.authService.ResetPassword(ctx, uint(userID), &req); err != nil {
+	if err := h.authService.ResetPassword(ctx, actorID, uint(userID), &req); err != nil {
 		c.Error(err)
 		return
 	}
@@ -159,9 +759,36 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	response.Success(c, gin.H{"message": "密码重置成功"})
 }
 
+// SelfResetPassword godoc
+// @Summary 找回密码
+// @Description 使用邮箱或手机号 + 验证码（purpose=reset_password）重置密码，成功后该账号所有已登录会话失效
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.SelfResetPasswordRequest true "找回密码请求数据"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/auth/forgot-password [post]
+func (h *AuthHandler) SelfResetPassword(c *gin.Context) {
+	var req model.SelfResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.authService.SelfResetPassword(ctx, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "密码重置成功，请重新登录"})
+}
+
 // Logout godoc
 // @Summary 用户登出
-// @Description 使当前令牌失效（需要 Redis 支持）
+// @Description 使当前令牌失效（需要 Redis 支持）。模拟登录令牌调用时提前结束模拟
 // @Tags 认证
 // @Produce json
 // @Security BearerAuth
@@ -169,14 +796,17 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 // @Failure 401 {object} response.Response
 // @Router /api/v1/auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
-	token, exists := c.Get("token")
-	if !exists {
+	principal, ok := GetPrincipal(c)
+	if !ok {
+		return
+	}
+	if principal.Token == "" {
 		c.Error(apperrors.UnauthorizedCode(i18n.ErrUnauthenticated))
 		return
 	}
 
 	ctx := c.Request.Context()
-	if err := h.authService.Logout(ctx, token.(string)); err != nil {
+	if err := h.authService.Logout(ctx, principal.Token); err != nil {
 		c.Error(err)
 		return
 	}
@@ -186,7 +816,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 
 // LogoutAllDevices godoc
 // @Summary 登出所有设备
-// @Description 使当前用户的所有令牌失效
+// @Description 注销当前用户的所有登录会话，所有设备上的令牌立即失效
 // @Tags 认证
 // @Produce json
 // @Security BearerAuth
@@ -207,3 +837,54 @@ func (h *AuthHandler) LogoutAllDevices(c *gin.Context) {
 
 	response.Success(c, gin.H{"message": "已登出所有设备"})
 }
+
+// ListSessions godoc
+// @Summary 登录会话列表
+// @Description 列出当前用户所有未失效的登录会话（设备），current 标记发起请求的会话
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.SessionResponse}
+// @Failure 401 {object} response.Response
+// @Router /api/v1/auth/sessions [get]
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	principal, ok := GetPrincipal(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	sessions, err := h.authService.ListSessions(ctx, principal.UserID, principal.SessionID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, sessions)
+}
+
+// RevokeSession godoc
+// @Summary 注销登录会话
+// @Description 注销当前用户的指定登录会话，该设备的访问令牌和刷新令牌立即失效
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "会话ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.authService.RevokeSession(ctx, userID, c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "会话已注销"})
+}
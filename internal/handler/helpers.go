@@ -3,32 +3,58 @@ package handler
 import (
 	"strconv"
 
+	"go-api-starter/internal/model"
 	"go-api-starter/pkg/apperrors"
 	"go-api-starter/pkg/response"
 
 	"github.com/gin-gonic/gin"
 )
 
+// GetPrincipal extracts the authenticated caller from gin context.
+// Returns nil and sets an error if not authenticated.
+func GetPrincipal(c *gin.Context) (*model.Principal, bool) {
+	if v, exists := c.Get(model.PrincipalContextKey); exists {
+		if p, ok := v.(*model.Principal); ok {
+			return p, true
+		}
+	}
+	c.Error(apperrors.Unauthorized("user not authenticated"))
+	return nil, false
+}
+
 // GetUserID extracts the authenticated user ID from gin context.
-// Returns 0 and sets an error if not authenticated.
+// Returns 0 and sets an error if not authenticated, or if the caller is an OAuth client with no user.
 func GetUserID(c *gin.Context) (uint, bool) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.Error(apperrors.Unauthorized("user not authenticated"))
+	p, ok := GetPrincipal(c)
+	if !ok {
+		return 0, false
+	}
+	if p.IsClient() {
+		c.Error(apperrors.Forbidden("client credentials cannot act as a user"))
 		return 0, false
 	}
-	return userID.(uint), true
+	return p.UserID, true
 }
 
 // GetOptionalUserID extracts user ID from context if present, returns 0 if not.
 // Does not set any error — use for endpoints with optional auth.
 func GetOptionalUserID(c *gin.Context) uint {
-	if uid, exists := c.Get("userID"); exists {
-		return uid.(uint)
+	if v, exists := c.Get(model.PrincipalContextKey); exists {
+		if p, ok := v.(*model.Principal); ok {
+			return p.UserID
+		}
 	}
 	return 0
 }
 
+// GetClientInfo collects the client IP and user agent for session tracking.
+func GetClientInfo(c *gin.Context) model.ClientInfo {
+	return model.ClientInfo{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
 // GetSecUID extracts sec_uid path parameter.
 // Returns empty string and sets an error if missing.
 func GetSecUID(c *gin.Context) (string, bool) {
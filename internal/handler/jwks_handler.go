@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-api-starter/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler publishes the public keys used to verify our JWTs
+type JWKSHandler struct {
+	jwtManager *auth.JWTManager
+}
+
+// NewJWKSHandler creates a new JWKSHandler
+func NewJWKSHandler(jwtManager *auth.JWTManager) *JWKSHandler {
+	return &JWKSHandler{jwtManager: jwtManager}
+}
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description 公开用于验证访问令牌签名的公钥（RS256 / EdDSA 模式），按 kid 匹配。HS256 模式下返回空集合
+// @Tags 认证
+// @Produce json
+// @Success 200 {object} auth.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) JWKS(c *gin.Context) {
+	// 标准 JWKS 格式，不使用统一响应包装，便于其他服务直接消费
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwtManager.JWKS())
+}
@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/service"
+	"go-api-starter/pkg/apperrors"
+	"go-api-starter/pkg/i18n"
+	"go-api-starter/pkg/logger"
+	"go-api-starter/pkg/response"
+)
+
+// OAuthHandler serves the OAuth endpoints for backend services and the management of their clients
+type OAuthHandler struct {
+	oauthService service.OAuthServiceInterface
+}
+
+// NewOAuthHandler creates a new OAuthHandler
+func NewOAuthHandler(oauthService service.OAuthServiceInterface) *OAuthHandler {
+	return &OAuthHandler{oauthService: oauthService}
+}
+
+// Token godoc
+// @Summary 获取客户端访问令牌（client_credentials）
+// @Description 后端服务以自身身份换取 access token，令牌不对应任何用户，只能使用客户端获准的权限码（scope 可进一步缩小）。令牌不可刷新，过期后重新获取。响应为标准格式，不使用统一响应包装
+// @Tags OAuth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Security BasicAuth
+// @Param grant_type formData string true "固定为 client_credentials"
+// @Param scope formData string false "空格分隔的权限码，不传时授予客户端的全部权限"
+// @Success 200 {object} model.ClientTokenResponse
+// @Failure 400 {object} model.OAuthErrorResponse
+// @Failure 401 {object} model.OAuthErrorResponse
+// @Router /oauth/token [post]
+func (h *OAuthHandler) Token(c *gin.Context) {
+	client, ok := h.authenticateClient(c)
+	if !ok {
+		return
+	}
+	var req model.ClientCredentialsRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.OAuthErrorResponse{Error: "invalid_request", ErrorDescription: "grant_type is required"})
+		return
+	}
+	if req.GrantType != "client_credentials" {
+		c.JSON(http.StatusBadRequest, model.OAuthErrorResponse{Error: "unsupported_grant_type"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	resp, err := h.oauthService.IssueClientToken(ctx, client, req.Scope)
+	if err != nil {
+		oauthError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, resp)
+}
+
+// Introspect godoc
+// @Summary 令牌内省（RFC 7662）
+// @Description 供其他后端服务查询访问令牌或刷新令牌当前是否有效（已登出、会话已注销、用户已冻结或已过期均返回 active=false），需使用 client_id / client_secret 认证（HTTP Basic 或表单参数）。响应为标准格式，不使用统一响应包装
+// @Tags OAuth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Security BasicAuth
+// @Param token formData string true "待查询的令牌"
+// @Param token_type_hint formData string false "access_token / refresh_token"
+// @Success 200 {object} model.IntrospectionResponse
+// @Failure 400 {object} model.OAuthErrorResponse
+// @Failure 401 {object} model.OAuthErrorResponse
+// @Router /oauth/introspect [post]
+func (h *OAuthHandler) Introspect(c *gin.Context) {
+	var req model.OAuthTokenRequest
+	if !h.authenticate(c, &req) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	resp, err := h.oauthService.Introspect(ctx, req.Token)
+	if err != nil {
+		oauthError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, resp)
+}
+
+// Revoke godoc
+// @Summary 吊销令牌（RFC 7009）
+// @Description 吊销访问令牌（直到其过期）或刷新令牌（连同其登录会话）。令牌无效或不存在时同样返回 200，需使用 client_id / client_secret 认证
+// @Tags OAuth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Security BasicAuth
+// @Param token formData string true "待吊销的令牌"
+// @Param token_type_hint formData string false "access_token / refresh_token"
+// @Success 200
+// @Failure 400 {object} model.OAuthErrorResponse
+// @Failure 401 {object} model.OAuthErrorResponse
+// @Router /oauth/revoke [post]
+func (h *OAuthHandler) Revoke(c *gin.Context) {
+	var req model.OAuthTokenRequest
+	if !h.authenticate(c, &req) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.oauthService.Revoke(ctx, req.Token); err != nil {
+		oauthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// authenticate checks the client credentials and binds the form, writing the error response on failure
+func (h *OAuthHandler) authenticate(c *gin.Context, req *model.OAuthTokenRequest) bool {
+	if _, ok := h.authenticateClient(c); !ok {
+		return false
+	}
+	if err := c.ShouldBind(req); err != nil {
+		c.JSON(http.StatusBadRequest, model.OAuthErrorResponse{Error: "invalid_request", ErrorDescription: "token is required"})
+		return false
+	}
+	return true
+}
+
+// authenticateClient checks the client credentials, writing the error response on failure
+func (h *OAuthHandler) authenticateClient(c *gin.Context) (*model.OAuthClient, bool) {
+	// client_secret_basic 优先，其次 client_secret_post
+	clientID, secret, ok := c.Request.BasicAuth()
+	if ok {
+		// RFC 6749 §2.3.1：Basic 认证中的 id 和 secret 先经过 form 编码
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID, secret = c.PostForm("client_id"), c.PostForm("client_secret")
+	}
+	client, err := h.oauthService.AuthenticateClient(c.Request.Context(), clientID, secret)
+	if err != nil {
+		oauthError(c, err)
+		return nil, false
+	}
+	return client, true
+}
+
+// oauthError writes an RFC 6749 error response
+func oauthError(c *gin.Context, err error) {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		switch appErr.Code {
+		case i18n.ErrOAuthInvalidClient:
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+			c.JSON(http.StatusUnauthorized, model.OAuthErrorResponse{Error: "invalid_client"})
+			return
+		case i18n.ErrOAuthInvalidScope:
+			c.JSON(http.StatusBadRequest, model.OAuthErrorResponse{Error: "invalid_scope"})
+			return
+		}
+	}
+	if logger.Log != nil {
+		logger.Log.Errorf("%s %s - %v", c.Request.Method, c.Request.URL.Path, err)
+	}
+	c.JSON(http.StatusInternalServerError, model.OAuthErrorResponse{Error: "server_error"})
+}
+
+// CreateClient godoc
+// @Summary 注册 OAuth 客户端
+// @Description 为后端服务创建 client_id / client_secret。scopes 为其 client_credentials 令牌可使用的权限码，只能是操作人自己拥有的权限。明文 secret 只在本次响应中返回
+// @Tags OAuth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.CreateOAuthClientRequest true "客户端信息"
+// @Success 201 {object} response.Response{data=model.CreateOAuthClientResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/oauth/clients [post]
+func (h *OAuthHandler) CreateClient(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+	var req model.CreateOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	client, err := h.oauthService.CreateClient(ctx, userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Created(c, client)
+}
+
+// ListClients godoc
+// @Summary OAuth 客户端列表
+// @Description 列出未吊销的 OAuth 客户端（不含 secret）
+// @Tags OAuth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.OAuthClientResponse}
+// @Failure 403 {object} response.Response
+// @Router /api/v1/oauth/clients [get]
+func (h *OAuthHandler) ListClients(c *gin.Context) {
+	ctx := c.Request.Context()
+	clients, err := h.oauthService.ListClients(ctx)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, clients)
+}
+
+// RevokeClient godoc
+// @Summary 吊销 OAuth 客户端
+// @Description 吊销后该客户端的凭据立即失效
+// @Tags OAuth
+// @Produce json
+// @Security BearerAuth
+// @Param client_id path string true "client_id"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/oauth/clients/{client_id} [delete]
+func (h *OAuthHandler) RevokeClient(c *gin.Context) {
+	ctx := c.Request.Context()
+	if err := h.oauthService.RevokeClient(ctx, c.Param("client_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "OAuth 客户端已吊销"})
+}
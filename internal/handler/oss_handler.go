@@ -253,7 +253,7 @@ func (h *OSSHandler) ListFiles(c *gin.Context) {
 
 // DeleteFile godoc
 // @Summary 删除文件
-// @Description 从 OSS 和数据库中删除文件
+// @Description 从 OSS 和数据库中删除文件，需要 file.delete:any，或 file.delete:own 且为上传者
 // @Tags 文件管理
 // @Produce json
 // @Security BearerAuth
@@ -274,7 +274,7 @@ func (h *OSSHandler) DeleteFile(c *gin.Context) {
 
 // UpdateFile godoc
 // @Summary 更新文件信息
-// @Description 更新文件的名称或隐私设置
+// @Description 更新文件的名称或隐私设置，需要 file.update:any，或 file.update:own 且为上传者
 // @Tags 文件管理
 // @Accept json
 // @Produce json
@@ -294,23 +294,12 @@ func (h *OSSHandler) UpdateFile(c *gin.Context) {
 		return
 	}
 
-	userID := GetOptionalUserID(c)
-
-	file, err := h.service.GetFileBySecUID(secUID)
-	if err != nil {
-		c.Error(err)
-		return
-	}
-	if file.UserID != userID {
-		c.Error(apperrors.Forbidden("you don't have permission to update this file"))
-		return
-	}
 	if err := h.service.UpdateFile(secUID, &req); err != nil {
 		c.Error(err)
 		return
 	}
 
-	file, err = h.service.GetFileBySecUID(secUID)
+	file, err := h.service.GetFileBySecUID(secUID)
 	if err != nil {
 		c.Error(err)
 		return
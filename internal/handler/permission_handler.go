@@ -68,6 +68,82 @@ func (h *PermissionHandler) GetAllSpaces(c *gin.Context) {
 	response.Success(c, spaces)
 }
 
+// UpdateSpace godoc
+// @Summary 更新权限空间
+// @Description 重命名、修改描述或启用 / 停用权限空间
+// @Tags 权限空间
+// @Accept json
+// @Produce json
+// @Param id path int true "权限空间ID"
+// @Param space body model.UpdateSpaceRequest true "权限空间数据"
+// @Success 200 {object} response.Response{data=model.PermissionSpace}
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/permissions/spaces/{id} [put]
+func (h *PermissionHandler) UpdateSpace(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.BadRequestCode(i18n.ErrInvalidSpaceID))
+		return
+	}
+	var req model.UpdateSpaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequestCode(i18n.ErrParamInvalid))
+		return
+	}
+	space, err := h.service.UpdateSpace(c.Request.Context(), uint(id), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, space)
+}
+
+// DeleteSpace godoc
+// @Summary 删除权限空间
+// @Description 删除空的权限空间，空间下仍有权限时需先移走或删除
+// @Tags 权限空间
+// @Param id path int true "权限空间ID"
+// @Success 204
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/permissions/spaces/{id} [delete]
+func (h *PermissionHandler) DeleteSpace(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.BadRequestCode(i18n.ErrInvalidSpaceID))
+		return
+	}
+	if err := h.service.DeleteSpace(c.Request.Context(), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+	response.NoContent(c)
+}
+
+// CompactSpace godoc
+// @Summary 压缩权限空间
+// @Description 按现有顺序把空间内的权限重新编号为 0 ~ n-1，消除删除或移走权限留下的空洞；同一事务内改写所有角色授权的位值，并清除该空间的用户权限缓存
+// @Tags 权限空间
+// @Produce json
+// @Param id path int true "权限空间ID"
+// @Success 200 {object} response.Response{data=model.CompactSpaceResult}
+// @Failure 404 {object} response.Response
+// @Router /api/v1/permissions/spaces/{id}/compact [post]
+func (h *PermissionHandler) CompactSpace(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.BadRequestCode(i18n.ErrInvalidSpaceID))
+		return
+	}
+	result, err := h.service.CompactSpace(c.Request.Context(), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, result)
+}
+
 // ====================
 // 权限管理 (Permission Management)
 // ====================
@@ -167,7 +243,7 @@ func (h *PermissionHandler) UpdatePermission(c *gin.Context) {
 
 // DeletePermission godoc
 // @Summary 删除权限
-// @Description 根据ID删除权限
+// @Description 根据ID删除权限，并收回所有角色对它的授权
 // @Tags 权限管理
 // @Param id path int true "权限ID"
 // @Success 204
@@ -186,13 +262,44 @@ func (h *PermissionHandler) DeletePermission(c *gin.Context) {
 	response.NoContent(c)
 }
 
+// MovePermission godoc
+// @Summary 移动权限到其他空间
+// @Description 把权限放到目标空间的下一个空闲位置，保留所有角色授权（同一事务内改写其位值），并清除两个空间的用户权限缓存
+// @Tags 权限管理
+// @Accept json
+// @Produce json
+// @Param id path int true "权限ID"
+// @Param request body model.MovePermissionRequest true "目标空间"
+// @Success 200 {object} response.Response{data=model.Permission}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/permissions/permissions/{id}/move [post]
+func (h *PermissionHandler) MovePermission(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.BadRequestCode(i18n.ErrInvalidPermissionID))
+		return
+	}
+	var req model.MovePermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequestCode(i18n.ErrParamInvalid))
+		return
+	}
+	perm, err := h.service.MovePermission(c.Request.Context(), uint(id), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, perm)
+}
+
 // ====================
 // 角色管理 (Role Management)
 // ====================
 
 // CreateRole godoc
 // @Summary 创建角色
-// @Description 创建一个新角色，可选择性地分配权限
+// @Description 创建一个新角色，可选择性地分配权限；指定 parent_id 时继承父角色及其祖先的全部权限
 // @Tags 角色管理
 // @Accept json
 // @Produce json
@@ -232,7 +339,7 @@ func (h *PermissionHandler) GetAllRoles(c *gin.Context) {
 
 // GetRole godoc
 // @Summary 获取角色详情
-// @Description 根据ID获取角色详情及其权限
+// @Description 根据ID获取角色详情、自身权限及从祖先角色继承的权限
 // @Tags 角色管理
 // @Produce json
 // @Param id path int true "角色ID"
@@ -255,13 +362,14 @@ func (h *PermissionHandler) GetRole(c *gin.Context) {
 
 // UpdateRole godoc
 // @Summary 更新角色
-// @Description 根据ID更新角色
+// @Description 根据ID更新角色。parent_id 传 0 取消父角色；父角色不能是自身或其子孙角色
 // @Tags 角色管理
 // @Accept json
 // @Produce json
 // @Param id path int true "角色ID"
 // @Param role body model.UpdateRoleRequest true "角色数据"
 // @Success 200 {object} response.Response{data=model.Role}
+// @Failure 400 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Router /api/v1/permissions/roles/{id} [put]
 func (h *PermissionHandler) UpdateRole(c *gin.Context) {
@@ -285,7 +393,7 @@ func (h *PermissionHandler) UpdateRole(c *gin.Context) {
 
 // DeleteRole godoc
 // @Summary 删除角色
-// @Description 根据ID删除角色
+// @Description 根据ID删除角色，其子角色变为顶层角色
 // @Tags 角色管理
 // @Param id path int true "角色ID"
 // @Success 204
@@ -429,7 +537,7 @@ func (h *PermissionHandler) GetUserRolesBySecUID(c *gin.Context) {
 	response.Success(c, roles)
 }
 
-// AssignUserRoleBySecUID 通过 sec_uid 分配角色
+// AssignUserRoleBySecUID 通过 sec_uid 分配角色，可指定生效和到期时间
 func (h *PermissionHandler) AssignUserRoleBySecUID(c *gin.Context) {
 	secUID, ok := GetSecUID(c)
 	if !ok {
@@ -445,7 +553,7 @@ func (h *PermissionHandler) AssignUserRoleBySecUID(c *gin.Context) {
 		c.Error(apperrors.BadRequestCode(i18n.ErrParamInvalid))
 		return
 	}
-	if err := h.service.AssignUserRole(c.Request.Context(), user.ID, req.RoleID); err != nil {
+	if err := h.service.AssignUserRole(c.Request.Context(), user.ID, &req); err != nil {
 		c.Error(err)
 		return
 	}
@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"errors"
+	"io"
+
 	"github.com/gin-gonic/gin"
 
 	"go-api-starter/internal/model"
@@ -11,12 +14,20 @@ import (
 
 // UserHandler handles user HTTP requests
 type UserHandler struct {
-	service service.UserServiceInterface
+	service       service.UserServiceInterface
+	impersonation service.ImpersonationServiceInterface
+	contactChange service.ContactChangeServiceInterface
+	freeze        service.FreezeServiceInterface
 }
 
 // NewUserHandler creates a new UserHandler
-func NewUserHandler(svc service.UserServiceInterface) *UserHandler {
-	return &UserHandler{service: svc}
+func NewUserHandler(
+	svc service.UserServiceInterface,
+	impersonation service.ImpersonationServiceInterface,
+	contactChange service.ContactChangeServiceInterface,
+	freeze service.FreezeServiceInterface,
+) *UserHandler {
+	return &UserHandler{service: svc, impersonation: impersonation, contactChange: contactChange, freeze: freeze}
 }
 
 // Create godoc
@@ -101,15 +112,18 @@ func (h *UserHandler) Get(c *gin.Context) {
 
 // Update godoc
 // @Summary 更新用户
-// @Description 根据 SecUID 更新用户
+// @Description 根据 SecUID 更新用户，需要 user.update:any，或 user.update:own 且为本人。修改邮箱或手机号需要 force=true（仅限 :any）：不经验证直接生效，但仍通知旧地址并写入审计日志
 // @Tags 用户管理
 // @Accept json
 // @Produce json
 // @Param sec_uid path string true "用户 SecUID"
+// @Param force query bool false "允许直接修改邮箱 / 手机号"
 // @Param user body model.UpdateUserRequest true "用户数据"
 // @Success 200 {object} response.Response{data=model.UserResponse}
 // @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
 // @Router /api/v1/users/{sec_uid} [put]
 func (h *UserHandler) Update(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -131,7 +145,21 @@ func (h *UserHandler) Update(c *gin.Context) {
 		return
 	}
 
-	updatedUser, err := h.service.Update(ctx, user.ID, &req)
+	var updatedUser *model.User
+	if c.Query("force") == "true" {
+		// 跳过验证修改登录凭据只对 :any 开放，本人须走 /users/me/contact-change
+		if c.GetString(model.ResourceScopeContextKey) != model.ScopeAny {
+			c.Error(apperrors.Forbidden("force update requires user.update:any"))
+			return
+		}
+		actorID, ok := GetUserID(c)
+		if !ok {
+			return
+		}
+		updatedUser, err = h.service.ForceUpdate(ctx, actorID, user.ID, &req, c.ClientIP())
+	} else {
+		updatedUser, err = h.service.Update(ctx, user.ID, &req)
+	}
 	if err != nil {
 		c.Error(err)
 		return
@@ -168,6 +196,173 @@ func (h *UserHandler) Delete(c *gin.Context) {
 	response.NoContent(c)
 }
 
+// Unlock godoc
+// @Summary 解除登录锁定
+// @Description 清除用户邮箱和手机号上因登录失败产生的锁定与计数，操作记录到审计日志
+// @Tags 用户管理
+// @Produce json
+// @Security BearerAuth
+// @Param sec_uid path string true "用户 SecUID"
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/users/{sec_uid}/unlock [post]
+func (h *UserHandler) Unlock(c *gin.Context) {
+	actorID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+	secUID, ok := GetSecUID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.service.Unlock(ctx, actorID, secUID, c.ClientIP()); err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, gin.H{"message": "已解除登录锁定"})
+}
+
+// UnlockIP godoc
+// @Summary 解除 IP 登录锁定
+// @Description 清除某个 IP 因登录失败产生的锁定与计数，操作记录到审计日志
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.UnlockIPRequest true "被锁定的 IP"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/users/unlock-ip [post]
+func (h *UserHandler) UnlockIP(c *gin.Context) {
+	actorID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+	var req model.UnlockIPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.service.UnlockIP(ctx, actorID, req.IP, c.ClientIP()); err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, gin.H{"message": "已解除登录锁定"})
+}
+
+// Freeze godoc
+// @Summary 冻结用户
+// @Description 冻结用户并立即注销其全部登录会话，需填写原因。可选 frozen_until 设为临时冻结，到期后自动解冻。拥有 role.manage 权限的账号只能由同样拥有该权限的管理员冻结，操作记录到审计日志
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param sec_uid path string true "用户 SecUID"
+// @Param request body model.FreezeUserRequest true "冻结原因与截止时间"
+// @Success 200 {object} response.Response{data=model.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/users/{sec_uid}/freeze [post]
+func (h *UserHandler) Freeze(c *gin.Context) {
+	actorID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+	secUID, ok := GetSecUID(c)
+	if !ok {
+		return
+	}
+	var req model.FreezeUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := h.freeze.Freeze(ctx, actorID, secUID, &req, c.ClientIP())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, user)
+}
+
+// Unfreeze godoc
+// @Summary 解冻用户
+// @Description 提前解除冻结，操作记录到审计日志。用户需重新登录
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param sec_uid path string true "用户 SecUID"
+// @Param request body model.UnfreezeUserRequest false "解冻原因"
+// @Success 200 {object} response.Response{data=model.UserResponse}
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/users/{sec_uid}/unfreeze [post]
+func (h *UserHandler) Unfreeze(c *gin.Context) {
+	actorID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+	secUID, ok := GetSecUID(c)
+	if !ok {
+		return
+	}
+	var req model.UnfreezeUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := h.freeze.Unfreeze(ctx, actorID, secUID, &req, c.ClientIP())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, user)
+}
+
+// Impersonate godoc
+// @Summary 模拟登录
+// @Description 以目标用户身份签发短时 access token（不可刷新，不能访问会话和凭证管理接口），用于排查用户问题。令牌的 act 声明记录操作人，使用它的每个请求都写入审计日志。不能模拟拥有 role.manage 权限的用户
+// @Tags 用户管理
+// @Produce json
+// @Security BearerAuth
+// @Param sec_uid path string true "用户 SecUID"
+// @Success 200 {object} response.Response{data=model.ImpersonationResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/users/{sec_uid}/impersonate [post]
+func (h *UserHandler) Impersonate(c *gin.Context) {
+	actorID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+	secUID, ok := GetSecUID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	resp, err := h.impersonation.Start(ctx, actorID, secUID, GetClientInfo(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, resp)
+}
+
 // GetMe godoc
 // @Summary 获取当前用户信息
 // @Tags 用户管理
@@ -194,6 +389,7 @@ func (h *UserHandler) GetMe(c *gin.Context) {
 
 // UpdateMe godoc
 // @Summary 更新当前用户信息
+// @Description 邮箱和手机号不能在这里修改（传入不同的值会被拒绝），请使用 /users/me/contact-change
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -224,3 +420,68 @@ func (h *UserHandler) UpdateMe(c *gin.Context) {
 	}
 	response.Success(c, updatedUser.ToResponse())
 }
+
+// RequestContactChange godoc
+// @Summary 申请更换邮箱 / 手机号
+// @Description 向新地址发送验证码并记录待确认的变更，有效期与验证码相同。再次申请会覆盖之前的变更
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.ContactChangeRequest true "要更换的字段和新地址"
+// @Success 200 {object} response.Response{data=model.ContactChangeResponse}
+// @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /api/v1/users/me/contact-change [post]
+func (h *UserHandler) RequestContactChange(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+	var req model.ContactChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequest("validation error: " + err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	resp, err := h.contactChange.Request(ctx, userID, &req, c.ClientIP())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, resp)
+}
+
+// ConfirmContactChange godoc
+// @Summary 确认更换邮箱 / 手机号
+// @Description 提交新地址收到的验证码，变更生效后通知旧地址
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.ConfirmContactChangeRequest true "字段和验证码"
+// @Success 200 {object} response.Response{data=model.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/users/me/contact-change/confirm [post]
+func (h *UserHandler) ConfirmContactChange(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return
+	}
+	var req model.ConfirmContactChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.BadRequest("validation error: " + err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := h.contactChange.Confirm(ctx, userID, &req, c.ClientIP())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, user.ToResponse())
+}
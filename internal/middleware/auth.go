@@ -5,9 +5,9 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 
 	"go-api-starter/internal/model"
+	"go-api-starter/pkg/auth"
 	"go-api-starter/pkg/response"
 )
 
@@ -21,148 +21,248 @@ type UserRepository interface {
 	FindByID(ctx context.Context, id uint) (*model.User, error)
 }
 
+// APIKeyAuthenticator resolves the plaintext key of an "Authorization: ApiKey <key>" header
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, plaintext string) (*model.APIKey, error)
+}
+
+// ImpersonationRecorder audits requests made with an impersonation token
+type ImpersonationRecorder interface {
+	RecordRequest(ctx context.Context, principal *model.Principal, method, path string, status int, ip string)
+}
+
+// OAuthClientResolver looks up the client named by a client_credentials token; revoked clients are an error
+type OAuthClientResolver interface {
+	ActiveClient(ctx context.Context, clientID string) (*model.OAuthClient, error)
+}
+
 type AuthMiddleware struct {
-	jwtSecret        string
+	jwtManager       *auth.JWTManager
 	blacklistChecker TokenBlacklistChecker
 	userRepo         UserRepository
+	apiKeys          APIKeyAuthenticator
+	impersonation    ImpersonationRecorder
+	clients          OAuthClientResolver
 }
 
 // NewAuthMiddleware creates an auth middleware with all features
-func NewAuthMiddleware(jwtSecret string, checker TokenBlacklistChecker, userRepo UserRepository) *AuthMiddleware {
+func NewAuthMiddleware(
+	jwtManager *auth.JWTManager,
+	checker TokenBlacklistChecker,
+	userRepo UserRepository,
+	apiKeys APIKeyAuthenticator,
+	impersonation ImpersonationRecorder,
+	clients OAuthClientResolver,
+) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtSecret:        jwtSecret,
+		jwtManager:       jwtManager,
 		blacklistChecker: checker,
 		userRepo:         userRepo,
+		apiKeys:          apiKeys,
+		impersonation:    impersonation,
+		clients:          clients,
 	}
 }
 
-// RequireAuth validates JWT token and sets userID in context
+// authLevel is which kinds of credentials an endpoint accepts
+type authLevel int
+
+const (
+	authOptional authLevel = iota // 任意凭据，或匿名
+	authAny                       // 登录会话、模拟登录、API Key、客户端令牌
+	authUser                      // 只接受代表用户本人的令牌：登录会话和模拟登录
+	authSession                   // 只接受登录会话
+)
+
+// RequireAuth validates a JWT access token ("Bearer <token>") or an API key ("ApiKey <key>")
+// and stores the caller as a *model.Principal. The token may also belong to an OAuth client
+// (client_credentials). API key and client scopes are enforced by RequirePermission, so
+// every route behind RequireAuth must check a permission; use RequireUserAuth otherwise.
 func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
+	return m.authenticate(authAny)
+}
+
+// RequireUserAuth is for endpoints that check no permission: it rejects API keys and OAuth
+// clients, whose scopes nothing there would enforce.
+func (m *AuthMiddleware) RequireUserAuth() gin.HandlerFunc {
+	return m.authenticate(authUser)
+}
+
+// RequireSessionAuth only accepts JWTs issued to a login session. Use it for endpoints that
+// manage the account's credentials, which neither an API key, an OAuth client nor an
+// impersonating administrator must be able to reach.
+func (m *AuthMiddleware) RequireSessionAuth() gin.HandlerFunc {
+	return m.authenticate(authSession)
+}
+
+// OptionalAuth sets the principal when valid credentials are presented but never blocks the request.
+// Missing, invalid, revoked or frozen credentials are all treated as anonymous.
+func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
+	return m.authenticate(authOptional)
+}
+
+// authError is why a request could not be authenticated
+type authError struct {
+	forbidden bool
+	message   string
+}
+
+var (
+	errMissingCredentials = &authError{message: "缺少认证令牌"}
+	errMalformedHeader    = &authError{message: "认证令牌格式错误"}
+	errInvalidAPIKey      = &authError{message: "API Key 无效"}
+	errRevokedToken       = &authError{message: "认证令牌已失效"}
+	errInvalidToken       = &authError{message: "认证令牌无效"}
+	errUserNotFound       = &authError{message: "用户不存在"}
+	errUserFrozen         = &authError{forbidden: true, message: "用户已被冻结"}
+	errImpersonation      = &authError{forbidden: true, message: "模拟登录不能访问此接口"}
+	errClientToken        = &authError{forbidden: true, message: "客户端令牌不能访问此接口"}
+	errAPIKeyNotAllowed   = &authError{forbidden: true, message: "API Key 不能访问此接口"}
+)
+
+func (m *AuthMiddleware) authenticate(level authLevel) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			response.Unauthorized(c, "缺少认证令牌")
+		principal, authErr := m.resolve(c, level)
+		if authErr != nil {
+			if level == authOptional {
+				c.Next()
+				return
+			}
+			if authErr.forbidden {
+				response.Forbidden(c, authErr.message)
+			} else {
+				response.Unauthorized(c, authErr.message)
+			}
 			c.Abort()
 			return
 		}
 
-		// Extract token from "Bearer <token>"
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			response.Unauthorized(c, "认证令牌格式错误")
-			c.Abort()
-			return
+		c.Set(model.PrincipalContextKey, principal)
+		c.Next()
+
+		if principal.IsImpersonated() && m.impersonation != nil {
+			m.impersonation.RecordRequest(c.Request.Context(), principal, c.Request.Method, c.Request.URL.Path, c.Writer.Status(), c.ClientIP())
 		}
+	}
+}
 
+// resolve turns the Authorization header into a principal. Every mode goes through
+// the same checks: token type, blacklist / revoked session, and frozen user.
+func (m *AuthMiddleware) resolve(c *gin.Context, level authLevel) (*model.Principal, *authError) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return nil, errMissingCredentials
+	}
+
+	// Extract credentials from "Bearer <token>" or "ApiKey <key>"
+	parts := strings.SplitN(authHeader, " ", 2)
+	isAPIKey := len(parts) == 2 && parts[0] == "ApiKey" && m.apiKeys != nil
+	if len(parts) != 2 || (parts[0] != "Bearer" && !isAPIKey) {
+		return nil, errMalformedHeader
+	}
+	if isAPIKey && level >= authUser {
+		return nil, errAPIKeyNotAllowed
+	}
+
+	ctx := c.Request.Context()
+	var principal *model.Principal
+	if isAPIKey {
+		key, err := m.apiKeys.Authenticate(ctx, parts[1])
+		if err != nil {
+			return nil, errInvalidAPIKey
+		}
+		principal = &model.Principal{
+			UserID:     key.UserID,
+			TokenID:    key.SecUID,
+			AuthMethod: model.AuthMethodAPIKey,
+			Scopes:     key.Scopes,
+		}
+	} else {
 		tokenString := parts[1]
 
-		// Check if token is blacklisted
+		// Signature, expiry and token_type: refresh and mfa_pending tokens are rejected here
+		claims, err := m.jwtManager.ValidateAccessToken(tokenString)
+		if err != nil || (claims.UserID == 0 && claims.ClientID == "") {
+			return nil, errInvalidToken
+		}
+
+		// Logged-out tokens and tokens of revoked sessions
 		if m.blacklistChecker != nil {
-			blacklisted, err := m.blacklistChecker.IsTokenBlacklisted(c.Request.Context(), tokenString)
+			blacklisted, err := m.blacklistChecker.IsTokenBlacklisted(ctx, tokenString)
 			if err == nil && blacklisted {
-				response.Unauthorized(c, "认证令牌已失效")
-				c.Abort()
-				return
+				return nil, errRevokedToken
 			}
 		}
 
-		// Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
+		if claims.ClientID != "" {
+			if level >= authUser {
+				return nil, errClientToken
 			}
-			return []byte(m.jwtSecret), nil
-		})
-
-		if err != nil || !token.Valid {
-			response.Unauthorized(c, "认证令牌无效")
-			c.Abort()
-			return
+			return m.resolveClient(ctx, tokenString, claims)
 		}
 
-		// Extract user ID from claims
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			response.Unauthorized(c, "认证令牌解析失败")
-			c.Abort()
-			return
+		principal = &model.Principal{
+			UserID:     claims.UserID,
+			TokenID:    claims.ID,
+			SessionID:  claims.SessionID,
+			AuthMethod: model.AuthMethodSession,
+			Token:      tokenString,
 		}
-
-		userID, ok := claims["user_id"].(float64)
-		if !ok {
-			response.Unauthorized(c, "用户ID无效")
-			c.Abort()
-			return
+		if claims.Act != nil {
+			if level == authSession {
+				return nil, errImpersonation
+			}
+			principal.AuthMethod = model.AuthMethodImpersonation
+			principal.ImpersonatorID = claims.Act.UserID
+			principal.ImpersonatorSecUID = claims.Act.Subject
 		}
+	}
 
-		userIDUint := uint(userID)
-
-		// Check if user is frozen (if userRepo is available)
-		if m.userRepo != nil {
-			user, err := m.userRepo.FindByID(c.Request.Context(), userIDUint)
+	if m.userRepo != nil {
+		// An impersonation token dies with its administrator's account as well
+		userIDs := []uint{principal.UserID}
+		if principal.IsImpersonated() {
+			userIDs = append(userIDs, principal.ImpersonatorID)
+		}
+		for _, id := range userIDs {
+			user, err := m.userRepo.FindByID(ctx, id)
 			if err != nil {
-				response.Unauthorized(c, "用户不存在")
-				c.Abort()
-				return
+				return nil, errUserNotFound
 			}
-
 			if user.Freezed {
-				response.Forbidden(c, "用户已被冻结")
-				c.Abort()
-				return
+				return nil, errUserFrozen
 			}
 		}
-
-		// Set user ID and token in context
-		c.Set("userID", userIDUint)
-		c.Set("token", tokenString)
-		c.Next()
 	}
-}
 
-// OptionalAuth tries to parse JWT token and set userID if present, but does not block the request
-func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.Next()
-			return
-		}
-
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.Next()
-			return
-		}
-
-		tokenString := parts[1]
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(m.jwtSecret), nil
-		})
-
-		if err != nil || !token.Valid {
-			c.Next()
-			return
-		}
-
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.Next()
-			return
-		}
+	return principal, nil
+}
 
-		userID, ok := claims["user_id"].(float64)
-		if !ok {
-			c.Next()
-			return
-		}
+// resolveClient builds the principal of a client_credentials token. The client must still be
+// active, and the token only keeps the scopes the client is still granted.
+func (m *AuthMiddleware) resolveClient(ctx context.Context, tokenString string, claims *auth.Claims) (*model.Principal, *authError) {
+	if m.clients == nil {
+		return nil, errInvalidToken
+	}
+	client, err := m.clients.ActiveClient(ctx, claims.ClientID)
+	if err != nil {
+		return nil, errRevokedToken
+	}
+	return &model.Principal{
+		ClientID:   client.ClientID,
+		TokenID:    claims.ID,
+		AuthMethod: model.AuthMethodClient,
+		Scopes:     client.AllowedScopes(claims.Scopes()),
+		Token:      tokenString,
+	}, nil
+}
 
-		c.Set("userID", uint(userID))
-		c.Set("token", tokenString)
-		c.Next()
+// GetPrincipal returns the principal stored by AuthMiddleware, if any
+func GetPrincipal(c *gin.Context) (*model.Principal, bool) {
+	v, ok := c.Get(model.PrincipalContextKey)
+	if !ok {
+		return nil, false
 	}
+	p, ok := v.(*model.Principal)
+	return p, ok
 }
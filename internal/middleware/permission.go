@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"go-api-starter/internal/model"
 	"go-api-starter/internal/service"
 	"go-api-starter/pkg/response"
 	"sync"
@@ -8,6 +10,11 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// ResourceOwnerResolver loads the owner of the resource named by the :sec_uid path parameter
+type ResourceOwnerResolver interface {
+	ResourceOwner(ctx context.Context, secUID string) (uint, error)
+}
+
 type PermissionMiddleware struct {
 	permService    service.PermissionServiceInterface
 	collectedCodes map[string]struct{}
@@ -21,7 +28,8 @@ func NewPermissionMiddleware(permService service.PermissionServiceInterface) *Pe
 	}
 }
 
-// RequirePermission checks if the user has the required permission.
+// RequirePermission checks if the user has the required permission, or for an OAuth client
+// whether the code is among its granted scopes.
 // It also collects the permission code for auto-seeding.
 func (m *PermissionMiddleware) RequirePermission(permissionCode string) gin.HandlerFunc {
 	// 路由注册阶段自动收集 code
@@ -30,20 +38,31 @@ func (m *PermissionMiddleware) RequirePermission(permissionCode string) gin.Hand
 	m.mu.Unlock()
 
 	return func(c *gin.Context) {
-		userIDVal, exists := c.Get("userID")
+		principal, exists := GetPrincipal(c)
 		if !exists {
 			response.Unauthorized(c, "用户未认证")
 			c.Abort()
 			return
 		}
+		userID := principal.UserID
 
-		userID, ok := userIDVal.(uint)
-		if !ok {
-			response.Unauthorized(c, "用户ID无效")
+		// API keys only reach permissions listed in their scopes, and only while the owner still holds them
+		if !principal.AllowsPermission(permissionCode) {
+			if principal.IsClient() {
+				response.Forbidden(c, "客户端未获授权此操作")
+			} else {
+				response.Forbidden(c, "API Key 未授权此操作")
+			}
 			c.Abort()
 			return
 		}
 
+		// OAuth clients have no user row: their granted scopes are the whole answer
+		if principal.IsClient() {
+			c.Next()
+			return
+		}
+
 		hasPermission, err := m.permService.CheckUserPermission(userID, permissionCode)
 		if err != nil {
 			response.InternalError(c, "权限检查失败")
@@ -61,6 +80,78 @@ func (m *PermissionMiddleware) RequirePermission(permissionCode string) gin.Hand
 	}
 }
 
+// RequireResourcePermission checks action against the resource named by :sec_uid.
+// Holders of "action:any" may act on every resource, the owner needs only "action:own".
+// Both codes are collected for auto-seeding.
+func (m *PermissionMiddleware) RequireResourcePermission(action string, resolver ResourceOwnerResolver) gin.HandlerFunc {
+	anyCode := model.ResourcePermission(action, model.ScopeAny)
+	ownCode := model.ResourcePermission(action, model.ScopeOwn)
+	m.mu.Lock()
+	m.collectedCodes[anyCode] = struct{}{}
+	m.collectedCodes[ownCode] = struct{}{}
+	m.mu.Unlock()
+
+	return func(c *gin.Context) {
+		principal, exists := GetPrincipal(c)
+		if !exists {
+			response.Unauthorized(c, "用户未认证")
+			c.Abort()
+			return
+		}
+
+		// 先判断 :any，持有者无需加载资源
+		allowed, err := m.holds(principal, anyCode)
+		if err != nil {
+			response.InternalError(c, "权限检查失败")
+			c.Abort()
+			return
+		}
+		if allowed {
+			c.Set(model.ResourceScopeContextKey, model.ScopeAny)
+			c.Next()
+			return
+		}
+
+		// OAuth 客户端不拥有任何资源，只能通过 :any 授权
+		if !principal.IsClient() {
+			ownerID, err := resolver.ResourceOwner(c.Request.Context(), c.Param("sec_uid"))
+			if err != nil {
+				c.Error(err)
+				c.Abort()
+				return
+			}
+			if ownerID == principal.UserID {
+				allowed, err = m.holds(principal, ownCode)
+				if err != nil {
+					response.InternalError(c, "权限检查失败")
+					c.Abort()
+					return
+				}
+				if allowed {
+					c.Set(model.ResourceScopeContextKey, model.ScopeOwn)
+					c.Next()
+					return
+				}
+			}
+		}
+
+		response.Forbidden(c, "没有权限操作该资源")
+		c.Abort()
+	}
+}
+
+// holds reports whether the credential allows the code and, unless the caller is an
+// OAuth client, the user holds it
+func (m *PermissionMiddleware) holds(principal *model.Principal, code string) (bool, error) {
+	if !principal.AllowsPermission(code) {
+		return false, nil
+	}
+	if principal.IsClient() {
+		return true, nil
+	}
+	return m.permService.CheckUserPermission(principal.UserID, code)
+}
+
 // CollectedCodes returns all permission codes that were registered via RequirePermission.
 func (m *PermissionMiddleware) CollectedCodes() []string {
 	m.mu.Lock()
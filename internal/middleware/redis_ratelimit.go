@@ -9,6 +9,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"go-api-starter/internal/model"
 	"go-api-starter/pkg/cache"
 )
 
@@ -108,10 +109,10 @@ func (r *RedisRateLimiter) RateLimit() gin.HandlerFunc {
 func (r *RedisRateLimiter) RateLimitByUser() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get user ID from context (set by auth middleware)
-		userID, exists := c.Get("userID")
+		principal, exists := GetPrincipal(c)
 		var identifier string
 		if exists {
-			identifier = fmt.Sprintf("user:%d", userID.(uint))
+			identifier = principalIdentifier(principal)
 		} else {
 			identifier = c.ClientIP()
 		}
@@ -222,9 +223,9 @@ func (m *MultiLevelRateLimiter) RateLimit() gin.HandlerFunc {
 
 		// Check user limit if authenticated
 		if m.user != nil {
-			if userID, exists := c.Get("userID"); exists {
+			if principal, exists := GetPrincipal(c); exists {
 				limiter := NewRedisRateLimiter(m.cache, m.user.Rate, m.user.Window)
-				identifier := fmt.Sprintf("user:%d", userID.(uint))
+				identifier := principalIdentifier(principal)
 				if allowed, info, _ := limiter.Allow(c.Request.Context(), identifier); !allowed {
 					setRateLimitHeaders(c, info)
 					c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
@@ -260,3 +261,11 @@ func setRateLimitHeaders(c *gin.Context, info RateLimitInfo) {
 	c.Header("X-RateLimit-Remaining", strconv.Itoa(info.Remaining))
 	c.Header("X-RateLimit-Reset", strconv.FormatInt(info.ResetAt.Unix(), 10))
 }
+
+// principalIdentifier is the rate limit key of an authenticated caller; clients have no user ID
+func principalIdentifier(p *model.Principal) string {
+	if p.IsClient() {
+		return "client:" + p.ClientID
+	}
+	return fmt.Sprintf("user:%d", p.UserID)
+}
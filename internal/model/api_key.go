@@ -0,0 +1,82 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix marks our API keys so they are easy to recognise in logs and secret scanners
+const APIKeyPrefix = "gak_"
+
+// APIKey is a long-lived personal access token for scripts and integrations.
+// Only the SHA-256 hash of the key is stored; the key itself is shown once on creation.
+type APIKey struct {
+	ID         uint       `json:"-" gorm:"primaryKey"`
+	SecUID     string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	UserID     uint       `json:"-" gorm:"index;not null"`
+	Name       string     `json:"-" gorm:"size:100;not null"`
+	Prefix     string     `json:"-" gorm:"size:16;not null"` // 密钥前几位，便于用户辨认
+	KeyHash    string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	Scopes     []string   `json:"-" gorm:"serializer:json;type:text"` // 允许使用的权限码，是所有者权限的子集
+	ExpiresAt  *time.Time `json:"-" gorm:"index"`                     // 为空表示永不过期
+	LastUsedAt *time.Time `json:"-"`
+	RevokedAt  *time.Time `json:"-" gorm:"index"`
+	CreatedAt  time.Time  `json:"-"`
+	UpdatedAt  time.Time  `json:"-"`
+}
+
+// TableName returns the table name for APIKey
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// BeforeCreate 创建前自动生成 SecUID
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	if k.SecUID == "" {
+		k.SecUID = GenerateSecUID()
+	}
+	return nil
+}
+
+// IsActive reports whether the key is neither revoked nor expired
+func (k *APIKey) IsActive() bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt))
+}
+
+// ToResponse converts an APIKey to its public representation
+func (k *APIKey) ToResponse() *APIKeyResponse {
+	return &APIKeyResponse{
+		ID:         k.SecUID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+// CreateAPIKeyRequest represents the request to create an API key
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,max=100" example:"ci-deploy"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,required" example:"user.read"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=3650" example:"90"` // 不传表示永不过期
+}
+
+// APIKeyResponse represents an API key without its secret
+type APIKeyResponse struct {
+	ID         string     `json:"id" example:"Xk3m9Qp2Rt5v8Wy1Zb4c6d"`
+	Name       string     `json:"name" example:"ci-deploy"`
+	Prefix     string     `json:"prefix" example:"gak_Xk3m9Qp2"`
+	Scopes     []string   `json:"scopes" example:"user.read"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyResponse carries the plaintext key; it cannot be retrieved again
+type CreateAPIKeyResponse struct {
+	Key string `json:"key" example:"gak_Xk3m9Qp2Rt5v8Wy1Zb4c6dHj7Kl0Mn3Pq6St9Vw2"`
+	*APIKeyResponse
+}
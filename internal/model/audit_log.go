@@ -0,0 +1,78 @@
+package model
+
+import "time"
+
+// Audit actions
+const (
+	AuditActionAccountLocked   = "auth.account_locked"
+	AuditActionIPLocked        = "auth.ip_locked"
+	AuditActionAccountUnlocked = "auth.account_unlocked"
+	AuditActionIPUnlocked      = "auth.ip_unlocked"
+
+	AuditActionImpersonationStarted = "auth.impersonation_started"
+	AuditActionImpersonatedRequest  = "auth.impersonated_request" // 模拟登录令牌发起的每个请求
+
+	AuditActionContactChanged = "user.contact_changed" // 邮箱或手机号变更
+	AuditActionUserFrozen     = "user.frozen"
+	AuditActionUserUnfrozen   = "user.unfrozen"     // 管理员解冻，或临时冻结到期自动解冻
+	AuditActionRoleExpired    = "user.role_expired" // 限时角色分配到期后被清理
+)
+
+// AuditLog records a security-relevant event for later review. Rows are append-only.
+type AuditLog struct {
+	ID        uint           `json:"-" gorm:"primaryKey"`
+	Action    string         `json:"-" gorm:"size:64;index;not null"`
+	ActorID   *uint          `json:"-" gorm:"index"`          // 操作人，系统自动触发时为空
+	UserID    *uint          `json:"-" gorm:"index"`          // 受影响的用户
+	Subject   string         `json:"-" gorm:"size:255;index"` // 非用户对象，如被锁定的账号或 IP
+	IP        string         `json:"-" gorm:"size:64"`
+	Metadata  map[string]any `json:"-" gorm:"serializer:json;type:text"`
+	CreatedAt time.Time      `json:"-" gorm:"index"`
+
+	// Relations
+	Actor *User `json:"-" gorm:"foreignKey:ActorID"`
+	User  *User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// TableName returns the table name for AuditLog
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// AuditLogFilter represents filter options for querying audit logs
+type AuditLogFilter struct {
+	Action  *string
+	UserID  *uint
+	Subject *string
+}
+
+// AuditLogResponse represents an audit log entry in API responses
+type AuditLogResponse struct {
+	ID          uint           `json:"id" example:"1"`
+	Action      string         `json:"action" example:"auth.account_locked"`
+	ActorSecUID string         `json:"actor_sec_uid,omitempty"`
+	UserSecUID  string         `json:"user_sec_uid,omitempty"`
+	Subject     string         `json:"subject,omitempty" example:"admin@example.com"`
+	IP          string         `json:"ip,omitempty" example:"203.0.113.7"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// ToResponse converts an AuditLog to AuditLogResponse
+func (l *AuditLog) ToResponse() *AuditLogResponse {
+	resp := &AuditLogResponse{
+		ID:        l.ID,
+		Action:    l.Action,
+		Subject:   l.Subject,
+		IP:        l.IP,
+		Metadata:  l.Metadata,
+		CreatedAt: l.CreatedAt,
+	}
+	if l.Actor != nil {
+		resp.ActorSecUID = l.Actor.SecUID
+	}
+	if l.User != nil {
+		resp.UserSecUID = l.User.SecUID
+	}
+	return resp
+}
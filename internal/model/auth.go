@@ -2,6 +2,9 @@ package model
 
 import "strings"
 
+// LoginTypeCode 表示验证码登录
+const LoginTypeCode = "code"
+
 // LoginRequest represents the login request
 type LoginRequest struct {
 	Account   string  `json:"account" binding:"required" example:"admin@example.com"` // 账号：邮箱或手机号，自动识别
@@ -14,29 +17,69 @@ type LoginRequest struct {
 
 // ResolveAccount 将 account 字段解析到 email 或 mobile
 func (r *LoginRequest) ResolveAccount() {
-	if r.Account != "" {
-		if strings.Contains(r.Account, "@") {
-			r.Email = &r.Account
-		} else {
-			r.Mobile = &r.Account
-		}
+	r.Email, r.Mobile = splitAccount(r.Account)
+}
+
+// splitAccount 根据是否包含 @ 将账号识别为邮箱或手机号
+func splitAccount(account string) (email, mobile *string) {
+	if account == "" {
+		return nil, nil
+	}
+	if strings.Contains(account, "@") {
+		return &account, nil
 	}
+	return nil, &account
 }
 
 // RegisterRequest represents the registration request
 type RegisterRequest struct {
 	Mobile   *string `json:"mobile" binding:"omitempty,len=11" example:"13800138000"`
 	Email    *string `json:"email" binding:"omitempty,email" example:"admin@example.com"`
-	Password string  `json:"password" binding:"required,min=6" example:"password123"`
-	Code     string  `json:"code" binding:"required,len=6" example:"123456"`
+	Password string  `json:"password" binding:"required" example:"Tr1cky-Kite"`
+	Code     string  `json:"code" binding:"required,len=6" example:"123456"` // 发送到 email（未填写 email 时为 mobile）的验证码
+}
+
+// CodeTarget 返回注册验证码的接收账号：优先邮箱，其次手机号
+func (r *RegisterRequest) CodeTarget() string {
+	if r.Email != nil && *r.Email != "" {
+		return *r.Email
+	}
+	if r.Mobile != nil {
+		return *r.Mobile
+	}
+	return ""
 }
 
-// LoginResponse represents the login response
+// Verification code purposes
+const (
+	CodePurposeLogin         = "login"
+	CodePurposeRegister      = "register"
+	CodePurposeResetPassword = "reset_password"
+	CodePurposeChangeContact = "change_contact" // 更换邮箱 / 手机号，发往新地址，不能通过 /auth/code 申请
+)
+
+// SendCodeRequest represents the send verification code request
+type SendCodeRequest struct {
+	Account string `json:"account" binding:"required" example:"john@example.com"`                          // 邮箱或手机号，自动识别
+	Purpose string `json:"purpose" binding:"required,oneof=login register reset_password" example:"login"` // login | register | reset_password
+}
+
+// SendCodeResponse represents the send verification code response
+type SendCodeResponse struct {
+	ExpiresIn  int64 `json:"expires_in" example:"300"` // 验证码有效期（秒）
+	RetryAfter int64 `json:"retry_after" example:"60"` // 再次发送前需等待的时间（秒）
+}
+
+// LoginResponse represents the login response.
+// When MFARequired is true only MFAToken is set; exchange it at /auth/mfa/verify.
 type LoginResponse struct {
-	AccessToken  string        `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	RefreshToken string        `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	ExpiresIn    int64         `json:"expires_in" example:"86400"`    // access_token 过期时间（秒）
-	User         *UserResponse `json:"user"`
+	AccessToken   string             `json:"access_token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken  string             `json:"refresh_token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ExpiresIn     int64              `json:"expires_in,omitempty" example:"86400"` // access_token 过期时间（秒）
+	User          *UserResponse      `json:"user,omitempty"`
+	MFARequired   bool               `json:"mfa_required,omitempty"`   // 需要提交两步验证码
+	MFAToken      string             `json:"mfa_token,omitempty"`      // mfa_pending 临时令牌
+	MFAEnrollment *MFAEnrollResponse `json:"mfa_enrollment,omitempty"` // 角色强制 MFA 但尚未设置时返回，用首个验证码完成启用
 }
 
 // RefreshTokenRequest represents the refresh token request
@@ -46,20 +89,26 @@ type RefreshTokenRequest struct {
 
 // RefreshTokenResponse represents the refresh token response
 type RefreshTokenResponse struct {
-	AccessToken string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	ExpiresIn   int64  `json:"expires_in" example:"86400"` // access_token 过期时间（秒）
+	AccessToken  string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."` // 轮换后的新刷新令牌，旧令牌随即失效
+	ExpiresIn    int64  `json:"expires_in" example:"86400"`                                      // access_token 过期时间（秒）
 }
 
 // ResetPasswordRequest represents the reset password request (admin only)
 type ResetPasswordRequest struct {
-	NewPassword string `json:"new_password" binding:"required,min=6" example:"newpassword123"`
+	NewPassword string `json:"new_password" binding:"required" example:"N3w-Tr1cky-Kite"`
 }
 
 // SelfResetPasswordRequest represents the self-service password reset request
 type SelfResetPasswordRequest struct {
-	Account     string `json:"account" binding:"required" example:"john@example.com"`       // 账号：邮箱或手机号
-	Code        string `json:"code" binding:"required,len=6" example:"123456"`
-	NewPassword string `json:"new_password" binding:"required,min=6" example:"newpassword123"`
+	Account     string  `json:"account" binding:"required" example:"john@example.com"` // 账号：邮箱或手机号
+	Code        string  `json:"code" binding:"required,len=6" example:"123456"`
+	NewPassword string  `json:"new_password" binding:"required" example:"N3w-Tr1cky-Kite"`
+	Mobile      *string `json:"-"` // 内部使用，由 ResolveAccount 填充
+	Email       *string `json:"-"` // 内部使用，由 ResolveAccount 填充
 }
 
-
+// ResolveAccount 将 account 字段解析到 email 或 mobile
+func (r *SelfResetPasswordRequest) ResolveAccount() {
+	r.Email, r.Mobile = splitAccount(r.Account)
+}
@@ -0,0 +1,28 @@
+package model
+
+// Login identifiers that can only be changed after verifying the new address
+const (
+	ContactFieldEmail  = "email"
+	ContactFieldMobile = "mobile"
+)
+
+// ContactChangeRequest starts a change of the current user's email or mobile.
+// A code is sent to the new address; the change takes effect once it is confirmed.
+type ContactChangeRequest struct {
+	Field string `json:"field" binding:"required,oneof=email mobile" example:"email"` // email | mobile
+	Value string `json:"value" binding:"required,max=50" example:"new@example.com"`
+}
+
+// ConfirmContactChangeRequest confirms a pending change with the code sent to the new address
+type ConfirmContactChangeRequest struct {
+	Field string `json:"field" binding:"required,oneof=email mobile" example:"email"`
+	Code  string `json:"code" binding:"required" example:"123456"`
+}
+
+// ContactChangeResponse describes a pending change waiting for its code
+type ContactChangeResponse struct {
+	Field      string `json:"field" example:"email"`
+	Value      string `json:"value" example:"new@example.com"`
+	ExpiresIn  int64  `json:"expires_in" example:"300"` // 待确认的变更和验证码的有效期（秒）
+	RetryAfter int64  `json:"retry_after" example:"60"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// UserIdentity links an account at an external OpenID provider (provider + subject) to a User
+type UserIdentity struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	UserID    uint      `json:"-" gorm:"index;not null"`
+	Provider  string    `json:"provider" gorm:"size:50;not null;uniqueIndex:uk_identity_provider_subject"`
+	Subject   string    `json:"-" gorm:"size:255;not null;uniqueIndex:uk_identity_provider_subject"` // IdP 的 sub 声明
+	Email     string    `json:"email,omitempty" gorm:"size:255"`                                     // 最近一次登录时 IdP 提供的邮箱
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for UserIdentity
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// OIDCProviderResponse describes a configured identity provider
+type OIDCProviderResponse struct {
+	Name        string `json:"name" example:"company"`
+	DisplayName string `json:"display_name" example:"Company SSO"`
+}
+
+// OIDCAuthorizeResponse carries the URL the browser should be sent to
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url" example:"https://sso.example.com/authorize?response_type=code&client_id=go-api-starter&state=..."`
+	State            string `json:"state" example:"pJ3n0q2kq0bJ9vJm9o0E8w"`
+}
+
+// OIDCCallbackRequest carries the parameters the provider returned to the redirect URL
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// ImpersonationResponse carries a short-lived access token for acting as another user.
+// There is no refresh token; start a new impersonation once it expires.
+type ImpersonationResponse struct {
+	AccessToken string        `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ExpiresIn   int64         `json:"expires_in" example:"900"` // access_token 过期时间（秒）
+	ExpiresAt   time.Time     `json:"expires_at"`
+	User        *UserResponse `json:"user"` // 被模拟的用户
+}
@@ -0,0 +1,24 @@
+package model
+
+// MagicLinkToken is the state of one emailed sign-in link, kept in the cache under the
+// hash of the token. NonceHash ties it to the browser that asked for the link.
+type MagicLinkToken struct {
+	UserID    uint   `json:"user_id"`
+	NonceHash string `json:"nonce_hash"` // 申请链接的浏览器 Cookie 中 nonce 的 SHA-256
+}
+
+// MagicLinkRequest asks for a sign-in link to be emailed
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email" example:"john@example.com"`
+}
+
+// MagicLinkResponse is the same whether or not the address belongs to an account
+type MagicLinkResponse struct {
+	ExpiresIn  int64 `json:"expires_in" example:"600"` // 链接有效期（秒）
+	RetryAfter int64 `json:"retry_after" example:"60"` // 再次发送前需等待的时间（秒）
+}
+
+// MagicLinkVerifyRequest carries the token from the link; the nonce comes from the cookie
+type MagicLinkVerifyRequest struct {
+	Token string `json:"token" binding:"required"`
+}
@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+// UserMFA stores a user's TOTP second factor
+type UserMFA struct {
+	ID           uint       `json:"-" gorm:"primaryKey"`
+	UserID       uint       `json:"-" gorm:"uniqueIndex;not null"`
+	Secret       string     `json:"-" gorm:"size:64;not null"` // base32 TOTP 密钥
+	EnabledAt    *time.Time `json:"-"`                         // 为空表示已生成密钥但尚未验证启用
+	LastUsedStep int64      `json:"-" gorm:"default:0"`        // 最近一次成功验证的时间步，防止验证码重放
+	CreatedAt    time.Time  `json:"-"`
+	UpdatedAt    time.Time  `json:"-"`
+}
+
+// TableName returns the table name for UserMFA
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// IsEnabled reports whether enrollment was confirmed with a valid code
+func (m *UserMFA) IsEnabled() bool {
+	return m.EnabledAt != nil
+}
+
+// MFARecoveryCode is a single-use backup code, stored hashed
+type MFARecoveryCode struct {
+	ID        uint       `json:"-" gorm:"primaryKey"`
+	UserID    uint       `json:"-" gorm:"index;not null"`
+	CodeHash  string     `json:"-" gorm:"size:64;not null"`
+	UsedAt    *time.Time `json:"-"`
+	CreatedAt time.Time  `json:"-"`
+}
+
+// TableName returns the table name for MFARecoveryCode
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// MFAEnrollResponse carries the data needed to set up an authenticator app.
+// Recovery codes are shown only once.
+type MFAEnrollResponse struct {
+	Secret        string   `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	OTPAuthURI    string   `json:"otpauth_uri" example:"otpauth://totp/go-api-starter:john@example.com?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&issuer=go-api-starter"`
+	RecoveryCodes []string `json:"recovery_codes" example:"k7m2-x9qp"`
+}
+
+// MFACodeRequest carries a TOTP code (or a recovery code where accepted)
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+// MFAVerifyRequest exchanges an mfa_pending token and a second-factor code for login tokens
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Code     string `json:"code" binding:"required" example:"123456"` // TOTP 验证码或恢复码
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api-starter/pkg/webauthn"
+)
+
+// WebAuthnCredential is a passkey registered by a user. The public key is kept in
+// COSE form exactly as the authenticator returned it.
+type WebAuthnCredential struct {
+	ID             uint       `json:"-" gorm:"primaryKey"`
+	SecUID         string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	UserID         uint       `json:"-" gorm:"index;not null"`
+	CredentialID   string     `json:"-" gorm:"type:text;not null"`           // base64url，最长 1023 字节，无法直接建索引
+	CredentialHash string     `json:"-" gorm:"size:64;uniqueIndex;not null"` // CredentialID 的 SHA-256，用于查找
+	PublicKey      []byte     `json:"-" gorm:"not null"`
+	SignCount      uint32     `json:"-" gorm:"default:0"`                 // 认证器签名计数器，用于发现被克隆的密钥
+	Transports     []string   `json:"-" gorm:"serializer:json;type:text"` // usb / nfc / ble / internal / hybrid
+	Name           string     `json:"-" gorm:"size:100"`
+	LastUsedAt     *time.Time `json:"-"`
+	CreatedAt      time.Time  `json:"-"`
+	UpdatedAt      time.Time  `json:"-"`
+
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// TableName returns the table name for WebAuthnCredential
+func (WebAuthnCredential) TableName() string {
+	return "webauthn_credentials"
+}
+
+// BeforeCreate 创建前自动生成 SecUID
+func (c *WebAuthnCredential) BeforeCreate(tx *gorm.DB) error {
+	if c.SecUID == "" {
+		c.SecUID = GenerateSecUID()
+	}
+	return nil
+}
+
+// ToResponse converts a WebAuthnCredential to its public representation
+func (c *WebAuthnCredential) ToResponse() *PasskeyResponse {
+	return &PasskeyResponse{
+		ID:         c.SecUID,
+		Name:       c.Name,
+		Transports: c.Transports,
+		LastUsedAt: c.LastUsedAt,
+		CreatedAt:  c.CreatedAt,
+	}
+}
+
+// PasskeyResponse represents a registered passkey
+type PasskeyResponse struct {
+	ID         string     `json:"id" example:"Xk3m9Qp2Rt5v8Wy1Zb4c6d"`
+	Name       string     `json:"name" example:"MacBook Touch ID"`
+	Transports []string   `json:"transports" example:"internal"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// PasskeyCreationResponse wraps the options for navigator.credentials.create({publicKey})
+type PasskeyCreationResponse struct {
+	PublicKey *webauthn.CreationOptions `json:"public_key"`
+}
+
+// PasskeyRequestResponse wraps the options for navigator.credentials.get({publicKey})
+type PasskeyRequestResponse struct {
+	PublicKey *webauthn.RequestOptions `json:"public_key"`
+}
+
+// PasskeyRegisterRequest carries the browser's registration response
+type PasskeyRegisterRequest struct {
+	Name       string                        `json:"name" binding:"max=100" example:"MacBook Touch ID"`
+	Credential webauthn.RegistrationResponse `json:"credential"` // PublicKeyCredential.toJSON()
+}
+
+// PasskeyLoginBeginRequest optionally names the account so only its passkeys are offered.
+// Without it the browser lets the user pick any passkey stored for this site.
+type PasskeyLoginBeginRequest struct {
+	Account string `json:"account" example:"john@example.com"`
+}
+
+// PasskeyLoginRequest carries the browser's authentication response
+type PasskeyLoginRequest struct {
+	Credential webauthn.AuthenticationResponse `json:"credential"` // PublicKeyCredential.toJSON()
+}
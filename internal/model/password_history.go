@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// PasswordHistory keeps the argon2 hashes of a user's previous passwords so they cannot be reused
+type PasswordHistory struct {
+	ID           uint      `json:"-" gorm:"primaryKey"`
+	UserID       uint      `json:"-" gorm:"index;not null"`
+	PasswordHash string    `json:"-" gorm:"size:255;not null"`
+	CreatedAt    time.Time `json:"-"`
+}
+
+// TableName returns the table name for PasswordHistory
+func (PasswordHistory) TableName() string {
+	return "password_history"
+}
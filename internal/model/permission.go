@@ -37,6 +37,18 @@ type Permission struct {
 	Space *PermissionSpace `json:"space,omitempty" gorm:"foreignKey:SpaceID"`
 }
 
+// Resource-scoped permission codes end with a qualifier: "file.delete:own" covers the
+// caller's own resources, "file.delete:any" everyone's.
+const (
+	ScopeOwn = "own"
+	ScopeAny = "any"
+)
+
+// ResourcePermission returns the code of action with a scope qualifier, e.g. ("file.delete", ScopeOwn)
+func ResourcePermission(action, scope string) string {
+	return action + ":" + scope
+}
+
 // Role 角色
 type Role struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
@@ -44,6 +56,8 @@ type Role struct {
 	Description string         `json:"description" gorm:"type:text"`
 	IsActive    bool           `json:"is_active" gorm:"default:true;index"`
 	IsSystem    bool           `json:"is_system" gorm:"default:false;index"`
+	IsDefault   bool           `json:"is_default" gorm:"default:false;index"` // 新用户注册时自动获得
+	ParentID    *uint          `json:"parent_id" gorm:"index"`                // 父角色，继承其（及其祖先）全部权限
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
@@ -53,15 +67,27 @@ type Role struct {
 
 // UserRole 用户角色关联
 type UserRole struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:uk_user_role"`
-	RoleID    uint      `json:"role_id" gorm:"not null;uniqueIndex:uk_user_role;index"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;uniqueIndex:uk_user_role"`
+	RoleID    uint       `json:"role_id" gorm:"not null;uniqueIndex:uk_user_role;index"`
+	StartsAt  *time.Time `json:"starts_at,omitempty"`               // 生效时间，为空表示立即生效
+	ExpiresAt *time.Time `json:"expires_at,omitempty" gorm:"index"` // 到期时间，为空表示长期有效
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
 
 	Role *Role `json:"role,omitempty" gorm:"foreignKey:RoleID"`
 }
 
+// ActiveAt reports whether the assignment is in effect at t
+func (ur *UserRole) ActiveAt(t time.Time) bool {
+	return (ur.StartsAt == nil || !t.Before(*ur.StartsAt)) && !ur.EndedAt(t)
+}
+
+// EndedAt reports whether the assignment has expired at t
+func (ur *UserRole) EndedAt(t time.Time) bool {
+	return ur.ExpiresAt != nil && !t.Before(*ur.ExpiresAt)
+}
+
 
 // RolePermission 角色权限关联（存储位运算值）
 type RolePermission struct {
@@ -99,6 +125,13 @@ func (c *UserPermissionCache) IsExpired() bool {
 	return time.Now().After(c.ExpiresAt)
 }
 
+// PermissionCacheGeneration 权限缓存的代数：删除缓存前换一个新 token，计算期间 token 变了的值不再写入
+type PermissionCacheGeneration struct {
+	Scope     string    `json:"scope" gorm:"primaryKey;size:32"` // 用户 ID，或 "all" 表示所有用户
+	Token     string    `json:"token" gorm:"size:32;not null"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 // TableName returns the table name for PermissionSpace
 func (PermissionSpace) TableName() string {
 	return "permission_spaces"
@@ -129,6 +162,11 @@ func (UserPermissionCache) TableName() string {
 	return "user_permission_caches"
 }
 
+// TableName returns the table name for PermissionCacheGeneration
+func (PermissionCacheGeneration) TableName() string {
+	return "permission_cache_generations"
+}
+
 
 // ==================== Request DTOs ====================
 
@@ -138,6 +176,13 @@ type CreateSpaceRequest struct {
 	Description string `json:"description" binding:"max=500" example:"用户管理权限空间"`
 }
 
+// UpdateSpaceRequest 更新权限空间请求
+type UpdateSpaceRequest struct {
+	Name        string `json:"name" binding:"omitempty,min=2,max=100" example:"user"`
+	Description string `json:"description" binding:"max=500" example:"用户管理权限空间"`
+	IsActive    *bool  `json:"is_active" example:"true"`
+}
+
 // CreatePermissionRequest 创建权限请求
 type CreatePermissionRequest struct {
 	Code        string `json:"code" binding:"required,min=2,max=50" example:"USER_CREATE"`
@@ -154,10 +199,16 @@ type UpdatePermissionRequest struct {
 	IsActive    *bool  `json:"is_active" example:"true"`
 }
 
+// MovePermissionRequest 将权限移动到另一个权限空间
+type MovePermissionRequest struct {
+	SpaceID uint `json:"space_id" binding:"required" example:"2"`
+}
+
 // CreateRoleRequest 创建角色请求
 type CreateRoleRequest struct {
 	Name            string   `json:"name" binding:"required,min=2,max=100" example:"admin"`
 	Description     string   `json:"description" binding:"max=500" example:"系统管理员"`
+	ParentID        *uint    `json:"parent_id" example:"2"`
 	PermissionCodes []string `json:"permission_codes" example:"USER_CREATE,USER_READ"`
 }
 
@@ -166,6 +217,7 @@ type UpdateRoleRequest struct {
 	Name        string `json:"name" binding:"omitempty,min=2,max=100" example:"admin"`
 	Description string `json:"description" binding:"max=500" example:"系统管理员"`
 	IsActive    *bool  `json:"is_active" example:"true"`
+	ParentID    *uint  `json:"parent_id" example:"2"` // 传 0 表示取消父角色
 }
 
 // RolePermissionsRequest 角色权限操作请求
@@ -175,7 +227,9 @@ type RolePermissionsRequest struct {
 
 // AssignRoleRequest 分配角色请求
 type AssignRoleRequest struct {
-	RoleID uint `json:"role_id" binding:"required" example:"1"`
+	RoleID    uint       `json:"role_id" binding:"required" example:"1"`
+	StartsAt  *time.Time `json:"starts_at" example:"2026-01-01T00:00:00Z"`  // 为空表示立即生效
+	ExpiresAt *time.Time `json:"expires_at" example:"2026-01-08T00:00:00Z"` // 为空表示长期有效
 }
 
 // ==================== Response DTOs ====================
@@ -189,6 +243,13 @@ type SpaceWithCount struct {
 	PermissionCount int64  `json:"permission_count"`
 }
 
+// CompactSpaceResult 压缩权限空间的结果
+type CompactSpaceResult struct {
+	SpaceID         uint `json:"space_id"`
+	PermissionCount int  `json:"permission_count"` // 压缩后占用位置 0 ~ count-1
+	Moved           int  `json:"moved"`            // 位置发生变化的权限数
+}
+
 // PermissionDetail 权限详情
 type PermissionDetail struct {
 	ID          uint   `json:"id"`
@@ -210,7 +271,10 @@ type RoleDetail struct {
 	Description     string     `json:"description"`
 	IsActive        bool       `json:"is_active"`
 	IsSystem        bool       `json:"is_system"`
+	IsDefault       bool       `json:"is_default"`
+	ParentID        *uint      `json:"parent_id"`
 	PermissionCodes []string   `json:"permission_codes"`
+	InheritedCodes  []string   `json:"inherited_permission_codes"` // 从祖先角色继承的权限
 	Permissions     []PermissionDetail `json:"permissions,omitempty"`
 }
 
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUserRoleActiveAt tests the validity window of role assignments
+func TestUserRoleActiveAt(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	// No window: always active
+	assert.True(t, (&UserRole{}).ActiveAt(now))
+
+	// Started and not yet expired
+	assert.True(t, (&UserRole{StartsAt: &past, ExpiresAt: &future}).ActiveAt(now))
+
+	// Not started yet
+	pending := &UserRole{StartsAt: &future}
+	assert.False(t, pending.ActiveAt(now))
+	assert.False(t, pending.EndedAt(now))
+
+	// Expired, including exactly at expires_at
+	expired := &UserRole{ExpiresAt: &past}
+	assert.False(t, expired.ActiveAt(now))
+	assert.True(t, expired.EndedAt(now))
+	assert.True(t, (&UserRole{ExpiresAt: &now}).EndedAt(now))
+}
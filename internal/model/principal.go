@@ -0,0 +1,54 @@
+package model
+
+import "slices"
+
+// PrincipalContextKey is the gin context key under which AuthMiddleware stores the *Principal
+const PrincipalContextKey = "principal"
+
+// ResourceScopeContextKey holds the qualifier (ScopeOwn / ScopeAny) that let
+// RequireResourcePermission through
+const ResourceScopeContextKey = "resource_scope"
+
+// How a request was authenticated
+const (
+	AuthMethodSession       = "session" // 登录会话签发的 access token
+	AuthMethodAPIKey        = "api_key"
+	AuthMethodImpersonation = "impersonation" // 管理员模拟登录签发，不属于任何会话
+	AuthMethodClient        = "client"        // OAuth client_credentials 令牌，没有对应的用户
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	UserID     uint   // 客户端令牌为 0
+	ClientID   string // 客户端令牌的 client_id
+	TokenID    string // access token 的 jti，或 API Key 的 ID
+	SessionID  string // 所属登录会话，API Key 为空
+	AuthMethod string
+	Scopes     []string // API Key / 客户端令牌的权限范围；登录会话不受限，为空
+	Token      string   // 原始 access token，登出时加入黑名单
+
+	// 模拟登录时的实际操作人，UserID 是被模拟的用户
+	ImpersonatorID     uint
+	ImpersonatorSecUID string
+}
+
+// IsImpersonated reports whether an administrator is acting as the user
+func (p *Principal) IsImpersonated() bool {
+	return p.AuthMethod == AuthMethodImpersonation
+}
+
+// IsAPIKey reports whether the request was made with an API key
+func (p *Principal) IsAPIKey() bool {
+	return p.AuthMethod == AuthMethodAPIKey
+}
+
+// IsClient reports whether the caller is an OAuth client rather than a user
+func (p *Principal) IsClient() bool {
+	return p.AuthMethod == AuthMethodClient
+}
+
+// AllowsPermission reports whether the credential may exercise the permission.
+// Session tokens carry all of the user's permissions; API keys and clients only their scopes.
+func (p *Principal) AllowsPermission(code string) bool {
+	return !(p.IsAPIKey() || p.IsClient()) || slices.Contains(p.Scopes, code)
+}
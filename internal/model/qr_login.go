@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// QR login states: pending → scanned → confirmed / cancelled, or expired at any point before confirmation
+const (
+	QRStatusPending   = "pending"
+	QRStatusScanned   = "scanned"
+	QRStatusConfirmed = "confirmed"
+	QRStatusCancelled = "cancelled"
+	QRStatusExpired   = "expired"
+)
+
+// QRLoginSession is the state of one QR login, kept in the cache (not in the database)
+type QRLoginSession struct {
+	Status        string     `json:"status"`
+	PollTokenHash string     `json:"poll_token_hash"` // 只有发起登录的桌面端持有 poll_token
+	Client        ClientInfo `json:"client"`          // 发起登录的桌面端，确认页展示给手机端
+	UserID        uint       `json:"user_id,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+}
+
+// QRCreateResponse is returned to the desktop: key goes into the QR code, poll_token stays private
+type QRCreateResponse struct {
+	Key       string `json:"key" example:"6hX0n3c8QdWm2p1sY9aZbw"`
+	PollToken string `json:"poll_token" example:"q3Jr8mE0v6Tn1xKp4sYb7w"`
+	ExpiresIn int64  `json:"expires_in" example:"120"` // 秒
+}
+
+// QRPollRequest is sent by the desktop to check the state
+type QRPollRequest struct {
+	Key       string `json:"key" binding:"required"`
+	PollToken string `json:"poll_token" binding:"required"`
+}
+
+// QRPollResponse carries the current state; Login is set once, on the poll that sees the confirmation
+type QRPollResponse struct {
+	Status string         `json:"status" example:"scanned"`
+	Login  *LoginResponse `json:"login,omitempty"`
+}
+
+// QRKeyRequest identifies a QR login from the mobile app
+type QRKeyRequest struct {
+	Key string `json:"key" binding:"required"`
+}
+
+// QRScanResponse tells the mobile app which device is asking to log in
+type QRScanResponse struct {
+	Status    string    `json:"status" example:"scanned"`
+	IP        string    `json:"ip" example:"203.0.113.7"`
+	UserAgent string    `json:"user_agent" example:"Mozilla/5.0 (Windows NT 10.0; Win64; x64)"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// RefreshToken tracks an issued refresh token for rotation and reuse detection.
+// Every login starts a new family; each refresh revokes the presented token and
+// issues a child in the same family. Presenting a revoked token revokes the family.
+type RefreshToken struct {
+	ID        uint       `json:"-" gorm:"primaryKey"`
+	JTI       string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	FamilyID  string     `json:"-" gorm:"size:64;index;not null"`
+	ParentJTI string     `json:"-" gorm:"size:64;index"` // 空表示家族的第一个令牌
+	UserID    uint       `json:"-" gorm:"index;not null"`
+	ExpiresAt time.Time  `json:"-" gorm:"not null"`
+	RevokedAt *time.Time `json:"-" gorm:"index"`
+	CreatedAt time.Time  `json:"-"`
+}
+
+// TableName returns the table name for RefreshToken
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// IsActive reports whether the token is neither revoked nor expired
+func (t *RefreshToken) IsActive() bool {
+	return t.RevokedAt == nil && time.Now().Before(t.ExpiresAt)
+}
@@ -12,6 +12,17 @@ func AllModels() []interface{} {
 		&UserRole{},
 		&RolePermission{},
 		&UserPermissionCache{},
+		&PermissionCacheGeneration{},
+		&RefreshToken{},
+		&Session{},
+		&UserMFA{},
+		&MFARecoveryCode{},
+		&UserIdentity{},
+		&APIKey{},
+		&AuditLog{},
+		&PasswordHistory{},
+		&WebAuthnCredential{},
+		&OAuthClient{},
 
 		// File & Upload
 		&File{},
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Session is a login on one device. It is created on login/register, owns one
+// refresh-token family and is referenced by access tokens through the "sid" claim.
+type Session struct {
+	ID         uint       `json:"-" gorm:"primaryKey"`
+	SecUID     string     `json:"-" gorm:"size:64;uniqueIndex;not null"` // 对外暴露的会话ID
+	UserID     uint       `json:"-" gorm:"index;not null"`
+	FamilyID   string     `json:"-" gorm:"size:64;uniqueIndex;not null"` // 对应的刷新令牌家族
+	UserAgent  string     `json:"-" gorm:"size:512"`
+	IP         string     `json:"-" gorm:"size:64"`
+	LastSeenAt time.Time  `json:"-" gorm:"not null"`
+	ExpiresAt  time.Time  `json:"-" gorm:"index;not null"` // 随刷新令牌轮换顺延
+	RevokedAt  *time.Time `json:"-" gorm:"index"`
+	CreatedAt  time.Time  `json:"-"`
+	UpdatedAt  time.Time  `json:"-"`
+}
+
+// TableName returns the table name for Session
+func (Session) TableName() string {
+	return "user_sessions"
+}
+
+// BeforeCreate 创建前自动生成 SecUID
+func (s *Session) BeforeCreate(tx *gorm.DB) error {
+	if s.SecUID == "" {
+		s.SecUID = GenerateSecUID()
+	}
+	return nil
+}
+
+// IsActive reports whether the session is neither revoked nor expired
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// ToResponse converts Session to SessionResponse; current marks the caller's own session
+func (s *Session) ToResponse(current bool) *SessionResponse {
+	return &SessionResponse{
+		ID:         s.SecUID,
+		UserAgent:  s.UserAgent,
+		IP:         s.IP,
+		CreatedAt:  s.CreatedAt,
+		LastSeenAt: s.LastSeenAt,
+		ExpiresAt:  s.ExpiresAt,
+		Current:    current,
+	}
+}
+
+// ClientInfo describes the device a login request comes from
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// SessionResponse represents a login session shown to its owner
+type SessionResponse struct {
+	ID         string    `json:"id" example:"q3Zb1c9YQ0m2e7Xk4Jt8Fw"`
+	UserAgent  string    `json:"user_agent" example:"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7)"`
+	IP         string    `json:"ip" example:"203.0.113.7"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // 是否为发起请求的当前会话
+}
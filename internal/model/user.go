@@ -41,6 +41,12 @@ type User struct {
 	CreatedAt time.Time      `json:"created_at" gorm:"index"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 冻结详情，只通过冻结 / 解冻接口修改
+	FrozenReason string     `json:"-" gorm:"size:255"`
+	FrozenBy     *uint      `json:"-"` // 执行冻结的管理员
+	FrozenAt     *time.Time `json:"-"`
+	FrozenUntil  *time.Time `json:"-" gorm:"index"` // 临时冻结的截止时间，为空表示直到手动解冻
 }
 
 // BeforeCreate 创建前自动生成 SecUID、Username 和 LPID
@@ -113,6 +119,22 @@ type UpdateUserRequest struct {
 	Website          *string    `json:"website" binding:"omitempty,url" example:"https://example.com"`
 }
 
+// UnlockIPRequest represents the request body for lifting a login lockout on an IP
+type UnlockIPRequest struct {
+	IP string `json:"ip" binding:"required,ip" example:"203.0.113.7"`
+}
+
+// FreezeUserRequest represents the request body for freezing a user
+type FreezeUserRequest struct {
+	Reason      string     `json:"reason" binding:"required,max=255" example:"发布违规内容"`
+	FrozenUntil *time.Time `json:"frozen_until" example:"2026-01-01T00:00:00Z"` // 为空表示直到手动解冻
+}
+
+// UnfreezeUserRequest represents the request body for unfreezing a user
+type UnfreezeUserRequest struct {
+	Reason string `json:"reason" binding:"max=255" example:"申诉通过"`
+}
+
 // ToUser converts CreateUserRequest to User model
 func (r *CreateUserRequest) ToUser() *User {
 	return &User{
@@ -146,6 +168,7 @@ type UserResponse struct {
 	Signature      *string             `json:"signature"`
 	Website        *string             `json:"website"`
 	Freezed        bool                `json:"freezed"`
+	FrozenUntil    *time.Time          `json:"frozen_until,omitempty"`
 	CreatedAt      time.Time           `json:"created_at"`
 	UpdatedAt      time.Time           `json:"updated_at"`
 }
@@ -158,21 +181,22 @@ func (u *User) ToResponse() *UserResponse {
 	}
 
 	resp := &UserResponse{
-		SecUID:    u.SecUID,
-		LPID:      u.LPID,
-		Username:  u.Username,
-		Email:     u.Email,
-		Roles:     roleNames,
-		Sex:       u.Sex,
-		Birthday:  u.Birthday,
-		City:      u.City,
-		Job:       u.Job,
-		Company:   u.Company,
-		Signature: u.Signature,
-		Website:   u.Website,
-		Freezed:   u.Freezed,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		SecUID:      u.SecUID,
+		LPID:        u.LPID,
+		Username:    u.Username,
+		Email:       u.Email,
+		Roles:       roleNames,
+		Sex:         u.Sex,
+		Birthday:    u.Birthday,
+		City:        u.City,
+		Job:         u.Job,
+		Company:     u.Company,
+		Signature:   u.Signature,
+		Website:     u.Website,
+		Freezed:     u.Freezed,
+		FrozenUntil: u.FrozenUntil,
+		CreatedAt:   u.CreatedAt,
+		UpdatedAt:   u.UpdatedAt,
 	}
 	if u.AvatarFile != nil {
 		resp.AvatarFile = &AvatarFileResponse{
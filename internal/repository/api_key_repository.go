@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-api-starter/internal/model"
+
+	"gorm.io/gorm"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// Compile-time interface check
+var _ APIKeyRepositoryInterface = (*APIKeyRepository)(nil)
+
+// APIKeyRepository handles API key data operations
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new APIKeyRepository
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create creates a new API key
+func (r *APIKeyRepository) Create(ctx context.Context, key *model.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// FindByHash finds an API key by the hash of its secret
+func (r *APIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	var key model.APIKey
+	err := r.db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAPIKeyNotFound
+	}
+	return &key, err
+}
+
+// FindActiveByUserID lists the keys of a user that are neither revoked nor expired, newest first
+func (r *APIKeyRepository) FindActiveByUserID(ctx context.Context, userID uint) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := r.activeByUser(ctx, userID).
+		Order("created_at DESC").
+		Find(&keys).Error
+	return keys, err
+}
+
+// CountActiveByUserID counts the keys of a user that are neither revoked nor expired
+func (r *APIKeyRepository) CountActiveByUserID(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.activeByUser(ctx, userID).Model(&model.APIKey{}).Count(&count).Error
+	return count, err
+}
+
+// TouchLastUsed records when a key was last used
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&model.APIKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+}
+
+// RevokeBySecUID revokes one key of a user; false means the user has no such active key
+func (r *APIKeyRepository) RevokeBySecUID(ctx context.Context, userID uint, secUID string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.APIKey{}).
+		Where("user_id = ? AND sec_uid = ? AND revoked_at IS NULL", userID, secUID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *APIKeyRepository) activeByUser(ctx context.Context, userID uint) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now())
+}
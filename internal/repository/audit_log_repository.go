@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+
+	"go-api-starter/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Compile-time interface check
+var _ AuditLogRepositoryInterface = (*AuditLogRepository)(nil)
+
+// AuditLogRepository handles audit log data operations
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository creates a new AuditLogRepository
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// Create appends an audit log entry
+func (r *AuditLogRepository) Create(ctx context.Context, log *model.AuditLog) error {
+	return r.db.WithContext(ctx).Create(log).Error
+}
+
+// List returns audit logs matching the filter with pagination
+func (r *AuditLogRepository) List(ctx context.Context, filter model.AuditLogFilter, offset, limit int, sort string) ([]model.AuditLog, int64, error) {
+	var logs []model.AuditLog
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.AuditLog{})
+
+	if filter.Action != nil {
+		query = query.Where("action = ?", *filter.Action)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Subject != nil {
+		query = query.Where("subject = ?", *filter.Subject)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("Actor").
+		Preload("User").
+		Offset(offset).
+		Limit(limit).
+		Order(sort).
+		Find(&logs).Error
+
+	return logs, total, err
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"go-api-starter/internal/model"
+
+	"gorm.io/gorm"
+)
+
+var ErrIdentityNotFound = errors.New("identity not found")
+
+// Compile-time interface check
+var _ IdentityRepositoryInterface = (*IdentityRepository)(nil)
+
+// IdentityRepository handles links between users and external identity providers
+type IdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewIdentityRepository creates a new IdentityRepository
+func NewIdentityRepository(db *gorm.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+// Create links an identity to an existing user
+func (r *IdentityRepository) Create(ctx context.Context, identity *model.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+// CreateWithUser creates a new user with its default roles together with its first identity
+func (r *IdentityRepository) CreateWithUser(ctx context.Context, user *model.User, identity *model.UserIdentity) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := createWithDefaultRoles(tx, user); err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+// FindByProviderSubject finds the identity of a provider account
+func (r *IdentityRepository) FindByProviderSubject(ctx context.Context, provider, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrIdentityNotFound
+	}
+	return &identity, err
+}
+
+// UpdateEmail records the email the provider reported on the latest login
+func (r *IdentityRepository) UpdateEmail(ctx context.Context, id uint, email string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.UserIdentity{}).
+		Where("id = ?", id).
+		Update("email", email).Error
+}
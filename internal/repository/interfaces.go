@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"go-api-starter/internal/model"
 )
@@ -9,6 +10,7 @@ import (
 // UserRepositoryInterface defines the interface for user data operations
 type UserRepositoryInterface interface {
 	Create(ctx context.Context, user *model.User) error
+	CreateWithDefaultRoles(ctx context.Context, user *model.User) error
 	FindAll(ctx context.Context, offset, limit int, sort string) ([]model.User, int64, error)
 	FindByID(ctx context.Context, id uint) (*model.User, error)
 	FindByEmail(ctx context.Context, email string) (*model.User, error)
@@ -17,9 +19,91 @@ type UserRepositoryInterface interface {
 	FindByUsername(ctx context.Context, username string) (*model.User, error)
 	FindByLPID(ctx context.Context, lpID string) (*model.User, error)
 	Update(ctx context.Context, user *model.User) error
+	UpdateFreeze(ctx context.Context, user *model.User) error
+	FindExpiredFreezes(ctx context.Context, now time.Time, limit int) ([]model.User, error)
+	ClearExpiredFreeze(ctx context.Context, id uint, now time.Time) (bool, error)
 	Delete(ctx context.Context, id uint) error
 }
 
+// RefreshTokenRepositoryInterface defines the interface for refresh token data operations
+type RefreshTokenRepositoryInterface interface {
+	Create(ctx context.Context, token *model.RefreshToken) error
+	FindByJTI(ctx context.Context, jti string) (*model.RefreshToken, error)
+	RevokeIfActive(ctx context.Context, jti string) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeByUserID(ctx context.Context, userID uint) error
+}
+
+// SessionRepositoryInterface defines the interface for login session data operations
+type SessionRepositoryInterface interface {
+	Create(ctx context.Context, session *model.Session) error
+	FindBySecUID(ctx context.Context, secUID string) (*model.Session, error)
+	FindByFamilyID(ctx context.Context, familyID string) (*model.Session, error)
+	FindActiveByUserID(ctx context.Context, userID uint) ([]model.Session, error)
+	Touch(ctx context.Context, id uint, lastSeenAt, expiresAt time.Time) error
+	Revoke(ctx context.Context, id uint) error
+	RevokeByUserID(ctx context.Context, userID uint) error
+}
+
+// MFARepositoryInterface defines the interface for TOTP and recovery code data operations
+type MFARepositoryInterface interface {
+	FindByUserID(ctx context.Context, userID uint) (*model.UserMFA, error)
+	ReplacePending(ctx context.Context, mfa *model.UserMFA, codeHashes []string) error
+	Enable(ctx context.Context, id uint, step int64) error
+	ConsumeStep(ctx context.Context, id uint, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error)
+	DeleteByUserID(ctx context.Context, userID uint) error
+}
+
+// IdentityRepositoryInterface defines the interface for external identity data operations
+type IdentityRepositoryInterface interface {
+	Create(ctx context.Context, identity *model.UserIdentity) error
+	CreateWithUser(ctx context.Context, user *model.User, identity *model.UserIdentity) error
+	FindByProviderSubject(ctx context.Context, provider, subject string) (*model.UserIdentity, error)
+	UpdateEmail(ctx context.Context, id uint, email string) error
+}
+
+// APIKeyRepositoryInterface defines the interface for API key data operations
+type APIKeyRepositoryInterface interface {
+	Create(ctx context.Context, key *model.APIKey) error
+	FindByHash(ctx context.Context, keyHash string) (*model.APIKey, error)
+	FindActiveByUserID(ctx context.Context, userID uint) ([]model.APIKey, error)
+	CountActiveByUserID(ctx context.Context, userID uint) (int64, error)
+	TouchLastUsed(ctx context.Context, id uint, at time.Time) error
+	RevokeBySecUID(ctx context.Context, userID uint, secUID string) (bool, error)
+}
+
+// PasswordHistoryRepositoryInterface defines the interface for password history data operations
+type PasswordHistoryRepositoryInterface interface {
+	Create(ctx context.Context, entry *model.PasswordHistory) error
+	FindRecent(ctx context.Context, userID uint, limit int) ([]model.PasswordHistory, error)
+	Prune(ctx context.Context, userID uint, keep int) error
+}
+
+// WebAuthnCredentialRepositoryInterface defines the interface for passkey data operations
+type WebAuthnCredentialRepositoryInterface interface {
+	Create(ctx context.Context, credential *model.WebAuthnCredential) error
+	FindByCredentialHash(ctx context.Context, credentialHash string) (*model.WebAuthnCredential, error)
+	FindByUserID(ctx context.Context, userID uint) ([]model.WebAuthnCredential, error)
+	UpdateUsage(ctx context.Context, id uint, signCount uint32, at time.Time) error
+	DeleteBySecUID(ctx context.Context, userID uint, secUID string) (bool, error)
+}
+
+// OAuthClientRepositoryInterface defines the interface for OAuth client data operations
+type OAuthClientRepositoryInterface interface {
+	Create(ctx context.Context, client *model.OAuthClient) error
+	FindByClientID(ctx context.Context, clientID string) (*model.OAuthClient, error)
+	FindActive(ctx context.Context) ([]model.OAuthClient, error)
+	TouchLastUsed(ctx context.Context, id uint, at time.Time) error
+	Revoke(ctx context.Context, clientID string) (bool, error)
+}
+
+// AuditLogRepositoryInterface defines the interface for audit log data operations
+type AuditLogRepositoryInterface interface {
+	Create(ctx context.Context, log *model.AuditLog) error
+	List(ctx context.Context, filter model.AuditLogFilter, offset, limit int, sort string) ([]model.AuditLog, int64, error)
+}
+
 // PermissionRepositoryInterface defines the interface for permission data operations
 type PermissionRepositoryInterface interface {
 	Create(ctx context.Context, permission *model.Permission) error
@@ -30,6 +114,9 @@ type PermissionRepositoryInterface interface {
 	GetMaxPositionInSpace(ctx context.Context, spaceID uint) (int, error)
 	Update(ctx context.Context, permission *model.Permission) error
 	SoftDelete(ctx context.Context, id uint) error
+	CreateInSpace(ctx context.Context, permission *model.Permission, place func(current []model.Permission) error) error
+	Relayout(ctx context.Context, spaceID uint, plan func(current []model.Permission) ([]model.Permission, error)) error
+	MoveToSpace(ctx context.Context, id, spaceID uint, place func(permission *model.Permission, current []model.Permission) error) (*model.Permission, error)
 	Exists(ctx context.Context, code string) (bool, error)
 	FindByCodes(ctx context.Context, codes []string) ([]model.Permission, error)
 	CountBySpaceID(ctx context.Context, spaceID uint) (int64, error)
@@ -44,6 +131,7 @@ type RoleRepositoryInterface interface {
 	FindAll(ctx context.Context) ([]model.Role, error)
 	Update(ctx context.Context, role *model.Role) error
 	Delete(ctx context.Context, id uint) error
+	DetachChildren(ctx context.Context, parentID uint) error
 	Exists(ctx context.Context, name string) (bool, error)
 }
 
@@ -56,16 +144,21 @@ type PermissionSpaceRepositoryInterface interface {
 	FindAllWithCount(ctx context.Context) ([]model.SpaceWithCount, error)
 	Exists(ctx context.Context, name string) (bool, error)
 	Update(ctx context.Context, space *model.PermissionSpace) error
+	Delete(ctx context.Context, id uint) error
 }
 
 // UserRoleRepositoryInterface defines the interface for user role data operations
 type UserRoleRepositoryInterface interface {
 	Create(ctx context.Context, userRole *model.UserRole) error
+	Update(ctx context.Context, userRole *model.UserRole) error
 	Delete(ctx context.Context, userID, roleID uint) error
 	FindByUserID(ctx context.Context, userID uint) ([]model.UserRole, error)
 	FindByRoleID(ctx context.Context, roleID uint) ([]model.UserRole, error)
 	Exists(ctx context.Context, userID, roleID uint) (bool, error)
 	GetUserIDsByRoleID(ctx context.Context, roleID uint) ([]uint, error)
+	GetUserIDsByRoleIDs(ctx context.Context, roleIDs []uint) ([]uint, error)
+	FindExpired(ctx context.Context, now time.Time, limit int) ([]model.UserRole, error)
+	DeleteExpired(ctx context.Context, id uint, now time.Time) (bool, error)
 }
 
 // RolePermissionRepositoryInterface defines the interface for role permission data operations
@@ -74,6 +167,7 @@ type RolePermissionRepositoryInterface interface {
 	Update(ctx context.Context, rp *model.RolePermission) error
 	Delete(ctx context.Context, roleID, permissionID uint) error
 	DeleteByRoleID(ctx context.Context, roleID uint) error
+	DeleteByPermissionID(ctx context.Context, permissionID uint) error
 	FindByRoleID(ctx context.Context, roleID uint) ([]model.RolePermission, error)
 	FindByRoleAndSpace(ctx context.Context, roleID, spaceID uint) (*model.RolePermission, error)
 	FindByRoleAndPermission(ctx context.Context, roleID, permissionID uint) (*model.RolePermission, error)
@@ -88,7 +182,10 @@ type UserPermissionCacheRepositoryInterface interface {
 	FindByUserID(ctx context.Context, userID uint) ([]model.UserPermissionCache, error)
 	DeleteByUserID(ctx context.Context, userID uint) error
 	DeleteByUserIDs(ctx context.Context, userIDs []uint) error
+	DeleteAll(ctx context.Context) error
 	GetUserSpaceValues(ctx context.Context, userID uint) (map[uint]uint64, error)
+	FindGenerations(ctx context.Context, scopes []string) (map[string]string, error)
+	SetGeneration(ctx context.Context, scopes []string, token string) error
 }
 
 // MultipartRepositoryInterface defines the interface for multipart upload data operations
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-api-starter/internal/model"
+
+	"gorm.io/gorm"
+)
+
+var ErrMFANotFound = errors.New("mfa not found")
+
+// Compile-time interface check
+var _ MFARepositoryInterface = (*MFARepository)(nil)
+
+// MFARepository handles TOTP secrets and recovery codes
+type MFARepository struct {
+	db *gorm.DB
+}
+
+// NewMFARepository creates a new MFARepository
+func NewMFARepository(db *gorm.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// FindByUserID finds the TOTP record of a user
+func (r *MFARepository) FindByUserID(ctx context.Context, userID uint) (*model.UserMFA, error) {
+	var mfa model.UserMFA
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&mfa).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMFANotFound
+	}
+	return &mfa, err
+}
+
+// ReplacePending stores a new, not yet enabled secret together with its recovery codes,
+// replacing any previous record of the user
+func (r *MFARepository) ReplacePending(ctx context.Context, mfa *model.UserMFA, codeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", mfa.UserID).Delete(&model.UserMFA{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(mfa).Error; err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, mfa.UserID, codeHashes)
+	})
+}
+
+// Enable marks the record as enabled and records the step used to confirm it
+func (r *MFARepository) Enable(ctx context.Context, id uint, step int64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.UserMFA{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"enabled_at": time.Now(), "last_used_step": step}).Error
+}
+
+// ConsumeStep records a successfully used time step. It returns false when the
+// step (or a later one) was already used, which means the code is being replayed.
+func (r *MFARepository) ConsumeStep(ctx context.Context, id uint, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.UserMFA{}).
+		Where("id = ? AND last_used_step < ?", id, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UseRecoveryCode marks an unused recovery code as used; false means no such unused code
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteByUserID removes the TOTP record and recovery codes of a user
+func (r *MFARepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserMFA{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint, codeHashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(codeHashes) == 0 {
+		return nil
+	}
+	codes := make([]model.MFARecoveryCode, 0, len(codeHashes))
+	for _, h := range codeHashes {
+		codes = append(codes, model.MFARecoveryCode{UserID: userID, CodeHash: h})
+	}
+	return tx.Create(&codes).Error
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-api-starter/internal/model"
+
+	"gorm.io/gorm"
+)
+
+var ErrOAuthClientNotFound = errors.New("oauth client not found")
+
+// Compile-time interface check
+var _ OAuthClientRepositoryInterface = (*OAuthClientRepository)(nil)
+
+// OAuthClientRepository handles OAuth client data operations
+type OAuthClientRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthClientRepository creates a new OAuthClientRepository
+func NewOAuthClientRepository(db *gorm.DB) *OAuthClientRepository {
+	return &OAuthClientRepository{db: db}
+}
+
+// Create creates a new client
+func (r *OAuthClientRepository) Create(ctx context.Context, client *model.OAuthClient) error {
+	return r.db.WithContext(ctx).Create(client).Error
+}
+
+// FindByClientID finds a client by its client_id
+func (r *OAuthClientRepository) FindByClientID(ctx context.Context, clientID string) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	err := r.db.WithContext(ctx).Where("client_id = ?", clientID).First(&client).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOAuthClientNotFound
+	}
+	return &client, err
+}
+
+// FindActive lists the clients that have not been revoked, newest first
+func (r *OAuthClientRepository) FindActive(ctx context.Context) ([]model.OAuthClient, error) {
+	var clients []model.OAuthClient
+	err := r.db.WithContext(ctx).
+		Where("revoked_at IS NULL").
+		Order("created_at DESC").
+		Find(&clients).Error
+	return clients, err
+}
+
+// TouchLastUsed records when a client last authenticated
+func (r *OAuthClientRepository) TouchLastUsed(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&model.OAuthClient{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+}
+
+// Revoke revokes a client; false means there is no such active client
+func (r *OAuthClientRepository) Revoke(ctx context.Context, clientID string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.OAuthClient{}).
+		Where("client_id = ? AND revoked_at IS NULL", clientID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
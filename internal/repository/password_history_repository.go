@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+
+	"go-api-starter/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Compile-time interface check
+var _ PasswordHistoryRepositoryInterface = (*PasswordHistoryRepository)(nil)
+
+// PasswordHistoryRepository handles password history data operations
+type PasswordHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordHistoryRepository creates a new PasswordHistoryRepository
+func NewPasswordHistoryRepository(db *gorm.DB) *PasswordHistoryRepository {
+	return &PasswordHistoryRepository{db: db}
+}
+
+// Create records a password hash
+func (r *PasswordHistoryRepository) Create(ctx context.Context, entry *model.PasswordHistory) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// FindRecent returns the user's most recent password hashes, newest first
+func (r *PasswordHistoryRepository) FindRecent(ctx context.Context, userID uint, limit int) ([]model.PasswordHistory, error) {
+	var entries []model.PasswordHistory
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("id desc").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
+
+// Prune deletes all but the user's newest keep entries
+func (r *PasswordHistoryRepository) Prune(ctx context.Context, userID uint, keep int) error {
+	db := r.db.WithContext(ctx)
+	recent := db.Model(&model.PasswordHistory{}).
+		Select("id").
+		Where("user_id = ?", userID).
+		Order("id desc").
+		Limit(keep)
+	// 子查询包一层，兼容 MySQL 不支持在 IN 子查询中直接使用 LIMIT
+	return db.
+		Where("user_id = ? AND id NOT IN (?)", userID, db.Table("(?) AS recent", recent).Select("id")).
+		Delete(&model.PasswordHistory{}).Error
+}
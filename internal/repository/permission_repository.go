@@ -7,11 +7,15 @@ import (
 	"go-api-starter/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
 	ErrPermissionNotFound   = errors.New("permission not found")
 	ErrPermissionCodeExists = errors.New("permission code already exists")
+
+	// errPermissionSpaceChanged restarts a move whose permission left its space before the lock was taken
+	errPermissionSpaceChanged = errors.New("permission space changed")
 )
 
 // Compile-time interface check
@@ -97,6 +101,141 @@ func (r *PermissionRepository) SoftDelete(ctx context.Context, id uint) error {
 	return result.Error
 }
 
+// CreateInSpace inserts permission into its space. place sets its Position and Value from the
+// permissions currently in the space; it runs under the same space lock as Relayout, so
+// concurrent creates, moves and compactions cannot hand out the same position.
+func (r *PermissionRepository) CreateInSpace(ctx context.Context, permission *model.Permission, place func(current []model.Permission) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := lockSpace(tx, permission.SpaceID)
+		if err != nil {
+			return err
+		}
+		if err := place(current); err != nil {
+			return err
+		}
+		return tx.Create(permission).Error
+	})
+}
+
+// Relayout locks spaceID, passes its current permissions to plan and places the permissions
+// plan returns in spaceID at their Position / Value, rewriting the value of every role grant
+// of them, all in one transaction. Grants left behind by soft-deleted permissions of the
+// space are dropped as well, so their stale bits cannot overlap the new positions.
+func (r *PermissionRepository) Relayout(ctx context.Context, spaceID uint, plan func(current []model.Permission) ([]model.Permission, error)) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := lockSpace(tx, spaceID)
+		if err != nil {
+			return err
+		}
+		perms, err := plan(current)
+		if err != nil {
+			return err
+		}
+		return placePermissions(tx, spaceID, perms)
+	})
+}
+
+// MoveToSpace moves permission id and its role grants to spaceID in one transaction. It locks
+// the current space of the permission and spaceID, re-reads the permission under the locks
+// and lets place set its Position / Value among the current permissions of spaceID. A
+// permission already in spaceID is returned as is, without calling place.
+func (r *PermissionRepository) MoveToSpace(ctx context.Context, id, spaceID uint, place func(permission *model.Permission, current []model.Permission) error) (*model.Permission, error) {
+	for {
+		var permission model.Permission
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.First(&permission, id).Error; err != nil {
+				return err
+			}
+			from := permission.SpaceID
+			if from == spaceID {
+				return nil
+			}
+
+			// Lock both spaces in ID order, so that two opposite moves cannot deadlock
+			first, second := from, spaceID
+			if first > second {
+				first, second = second, first
+			}
+			firstPerms, err := lockSpace(tx, first)
+			if err != nil {
+				return err
+			}
+			current, err := lockSpace(tx, second)
+			if err != nil {
+				return err
+			}
+			if first == spaceID {
+				current = firstPerms
+			}
+
+			if err := tx.First(&permission, id).Error; err != nil {
+				return err
+			}
+			if permission.SpaceID != from {
+				return errPermissionSpaceChanged
+			}
+			if err := place(&permission, current); err != nil {
+				return err
+			}
+			permission.SpaceID, permission.Space = spaceID, nil
+			return placePermissions(tx, spaceID, []model.Permission{permission})
+		})
+		if errors.Is(err, errPermissionSpaceChanged) {
+			continue
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPermissionNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &permission, nil
+	}
+}
+
+// placePermissions writes the Position / Value of perms in spaceID and rewrites the value of
+// every role grant of them. Grants left behind by soft-deleted permissions of the space are
+// dropped as well, so their stale bits cannot overlap the new positions.
+func placePermissions(tx *gorm.DB, spaceID uint, perms []model.Permission) error {
+	deleted := tx.Unscoped().Model(&model.Permission{}).
+		Select("id").
+		Where("space_id = ? AND deleted_at IS NOT NULL", spaceID)
+	if err := tx.Where("permission_id IN (?)", deleted).Delete(&model.RolePermission{}).Error; err != nil {
+		return err
+	}
+	for _, p := range perms {
+		err := tx.Model(&model.Permission{}).
+			Where("id = ?", p.ID).
+			Updates(map[string]interface{}{"space_id": spaceID, "position": p.Position, "value": p.Value}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&model.RolePermission{}).
+			Where("permission_id = ?", p.ID).
+			Updates(map[string]interface{}{"space_id": spaceID, "value": p.Value}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockSpace locks the space row for the rest of tx and returns its permissions by position.
+// SQLite has no row locks; its writers are serialized anyway.
+func lockSpace(tx *gorm.DB, spaceID uint) ([]model.Permission, error) {
+	var space model.PermissionSpace
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&space, spaceID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPermissionSpaceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var perms []model.Permission
+	err = tx.Where("space_id = ?", spaceID).Order("position ASC").Find(&perms).Error
+	return perms, err
+}
+
 // Exists checks if a permission with the given code exists
 func (r *PermissionRepository) Exists(ctx context.Context, code string) (bool, error) {
 	var count int64
@@ -83,3 +83,12 @@ func (r *PermissionSpaceRepository) Exists(ctx context.Context, name string) (bo
 func (r *PermissionSpaceRepository) Update(ctx context.Context, space *model.PermissionSpace) error {
 	return r.db.WithContext(ctx).Save(space).Error
 }
+
+// Delete soft deletes a permission space
+func (r *PermissionSpaceRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&model.PermissionSpace{}, id)
+	if result.RowsAffected == 0 {
+		return ErrPermissionSpaceNotFound
+	}
+	return result.Error
+}
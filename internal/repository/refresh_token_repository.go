@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-api-starter/internal/model"
+
+	"gorm.io/gorm"
+)
+
+var ErrRefreshTokenNotFound = errors.New("refresh token not found")
+
+// Compile-time interface check
+var _ RefreshTokenRepositoryInterface = (*RefreshTokenRepository)(nil)
+
+// RefreshTokenRepository handles refresh token data operations
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository creates a new RefreshTokenRepository
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// Create creates a new refresh token record
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *model.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+// FindByJTI finds a refresh token by its JWT ID
+func (r *RefreshTokenRepository) FindByJTI(ctx context.Context, jti string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := r.db.WithContext(ctx).Where("jti = ?", jti).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRefreshTokenNotFound
+	}
+	return &token, err
+}
+
+// RevokeIfActive revokes a token only if it is not revoked yet.
+// It returns false when another request already revoked (rotated) the token.
+func (r *RefreshTokenRepository) RevokeIfActive(ctx context.Context, jti string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.RefreshToken{}).
+		Where("jti = ? AND revoked_at IS NULL", jti).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeFamily revokes every token in a family
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeByUserID revokes every refresh token of a user
+func (r *RefreshTokenRepository) RevokeByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).
+		Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -50,6 +50,11 @@ func (r *RolePermissionRepository) DeleteByRoleID(ctx context.Context, roleID ui
 	return r.db.WithContext(ctx).Where("role_id = ?", roleID).Delete(&model.RolePermission{}).Error
 }
 
+// DeleteByPermissionID removes a permission from every role holding it
+func (r *RolePermissionRepository) DeleteByPermissionID(ctx context.Context, permissionID uint) error {
+	return r.db.WithContext(ctx).Where("permission_id = ?", permissionID).Delete(&model.RolePermission{}).Error
+}
+
 // FindByRoleID finds all permissions for a role
 func (r *RolePermissionRepository) FindByRoleID(ctx context.Context, roleID uint) ([]model.RolePermission, error) {
 	var rolePermissions []model.RolePermission
@@ -86,6 +86,14 @@ func (r *RoleRepository) Delete(ctx context.Context, id uint) error {
 	return result.Error
 }
 
+// DetachChildren removes parentID as the parent of its child roles
+func (r *RoleRepository) DetachChildren(ctx context.Context, parentID uint) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Role{}).
+		Where("parent_id = ?", parentID).
+		Update("parent_id", nil).Error
+}
+
 // Exists checks if a role with the given name exists
 func (r *RoleRepository) Exists(ctx context.Context, name string) (bool, error) {
 	var count int64
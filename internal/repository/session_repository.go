@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-api-starter/internal/model"
+
+	"gorm.io/gorm"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// Compile-time interface check
+var _ SessionRepositoryInterface = (*SessionRepository)(nil)
+
+// SessionRepository handles login session data operations
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new SessionRepository
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Create creates a new session
+func (r *SessionRepository) Create(ctx context.Context, session *model.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+// FindBySecUID finds a session by its public ID
+func (r *SessionRepository) FindBySecUID(ctx context.Context, secUID string) (*model.Session, error) {
+	var session model.Session
+	err := r.db.WithContext(ctx).Where("sec_uid = ?", secUID).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSessionNotFound
+	}
+	return &session, err
+}
+
+// FindByFamilyID finds the session that owns a refresh-token family
+func (r *SessionRepository) FindByFamilyID(ctx context.Context, familyID string) (*model.Session, error) {
+	var session model.Session
+	err := r.db.WithContext(ctx).Where("family_id = ?", familyID).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSessionNotFound
+	}
+	return &session, err
+}
+
+// FindActiveByUserID lists a user's sessions that are neither revoked nor expired, most recent first
+func (r *SessionRepository) FindActiveByUserID(ctx context.Context, userID uint) ([]model.Session, error) {
+	var sessions []model.Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Touch updates the last-seen time and, when non-zero, the expiry of a session
+func (r *SessionRepository) Touch(ctx context.Context, id uint, lastSeenAt, expiresAt time.Time) error {
+	updates := map[string]interface{}{"last_seen_at": lastSeenAt}
+	if !expiresAt.IsZero() {
+		updates["expires_at"] = expiresAt
+	}
+	return r.db.WithContext(ctx).
+		Model(&model.Session{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+// Revoke revokes a single session
+func (r *SessionRepository) Revoke(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeByUserID revokes every session of a user
+func (r *SessionRepository) RevokeByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"go-api-starter/internal/model"
 
@@ -62,6 +63,11 @@ func (r *UserPermissionCacheRepository) DeleteByUserIDs(ctx context.Context, use
 	return r.db.WithContext(ctx).Where("user_id IN ?", userIDs).Delete(&model.UserPermissionCache{}).Error
 }
 
+// DeleteAll deletes the cache entries of every user
+func (r *UserPermissionCacheRepository) DeleteAll(ctx context.Context) error {
+	return r.db.WithContext(ctx).Where("1 = 1").Delete(&model.UserPermissionCache{}).Error
+}
+
 // GetUserSpaceValues returns all space values for a user
 func (r *UserPermissionCacheRepository) GetUserSpaceValues(ctx context.Context, userID uint) (map[uint]uint64, error) {
 	var caches []model.UserPermissionCache
@@ -76,3 +82,32 @@ func (r *UserPermissionCacheRepository) GetUserSpaceValues(ctx context.Context,
 	}
 	return spaceValues, nil
 }
+
+// FindGenerations returns the generation token of each scope that has one
+func (r *UserPermissionCacheRepository) FindGenerations(ctx context.Context, scopes []string) (map[string]string, error) {
+	var rows []model.PermissionCacheGeneration
+	if err := r.db.WithContext(ctx).Where("scope IN ?", scopes).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	tokens := make(map[string]string, len(rows))
+	for _, row := range rows {
+		tokens[row.Scope] = row.Token
+	}
+	return tokens, nil
+}
+
+// SetGeneration stores token as the generation of each scope
+func (r *UserPermissionCacheRepository) SetGeneration(ctx context.Context, scopes []string, token string) error {
+	if len(scopes) == 0 {
+		return nil
+	}
+	now := time.Now()
+	rows := make([]model.PermissionCacheGeneration, len(scopes))
+	for i, scope := range scopes {
+		rows[i] = model.PermissionCacheGeneration{Scope: scope, Token: token, UpdatedAt: now}
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "scope"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token", "updated_at"}),
+	}).Create(&rows).Error
+}
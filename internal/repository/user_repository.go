@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"go-api-starter/internal/model"
 
@@ -29,6 +30,30 @@ func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
 	return r.db.WithContext(ctx).Create(user).Error
 }
 
+// CreateWithDefaultRoles creates a new user and assigns it the active default roles in one transaction
+func (r *UserRepository) CreateWithDefaultRoles(ctx context.Context, user *model.User) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createWithDefaultRoles(tx, user)
+	})
+}
+
+// createWithDefaultRoles inserts the user and its default role assignments with tx
+func createWithDefaultRoles(tx *gorm.DB, user *model.User) error {
+	if err := tx.Create(user).Error; err != nil {
+		return err
+	}
+	var roleIDs []uint
+	if err := tx.Model(&model.Role{}).Where("is_default = ? AND is_active = ?", true, true).Pluck("id", &roleIDs).Error; err != nil {
+		return err
+	}
+	for _, roleID := range roleIDs {
+		if err := tx.Create(&model.UserRole{UserID: user.ID, RoleID: roleID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // FindAll returns all users with pagination and sorting
 func (r *UserRepository) FindAll(ctx context.Context, offset, limit int, sort string) ([]model.User, int64, error) {
 	var users []model.User
@@ -60,14 +85,15 @@ func (r *UserRepository) FindByID(ctx context.Context, id uint) (*model.User, er
 	return &user, err
 }
 
-// FindByEmail finds a user by email
+// FindByEmail finds a user by email, ignoring case: callers look addresses up lowercased,
+// while addresses stored before normalization may still be mixed-case
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
 	err := r.db.WithContext(ctx).
 		Preload("AvatarFile").
 		Preload("BackgroundFile").
 		Preload("Roles").
-		Where("email = ?", email).First(&user).Error
+		Where("LOWER(email) = LOWER(?)", email).First(&user).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrUserNotFound
 	}
@@ -127,6 +153,37 @@ func (r *UserRepository) Update(ctx context.Context, user *model.User) error {
 	return r.db.WithContext(ctx).Save(user).Error
 }
 
+// UpdateFreeze writes only the freeze columns of a user
+func (r *UserRepository) UpdateFreeze(ctx context.Context, user *model.User) error {
+	return r.db.WithContext(ctx).Model(user).
+		Select("freezed", "frozen_reason", "frozen_by", "frozen_at", "frozen_until").
+		Updates(user).Error
+}
+
+// FindExpiredFreezes returns up to limit users whose temporary freeze ended before now
+func (r *UserRepository) FindExpiredFreezes(ctx context.Context, now time.Time, limit int) ([]model.User, error) {
+	var users []model.User
+	err := r.db.WithContext(ctx).
+		Where("freezed = ? AND frozen_until IS NOT NULL AND frozen_until <= ?", true, now).
+		Order("frozen_until").Limit(limit).Find(&users).Error
+	return users, err
+}
+
+// ClearExpiredFreeze unfreezes the user if the freeze is still the expired one.
+// It reports false when an administrator changed the freeze in the meantime.
+func (r *UserRepository) ClearExpiredFreeze(ctx context.Context, id uint, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND freezed = ? AND frozen_until IS NOT NULL AND frozen_until <= ?", id, true, now).
+		Updates(map[string]any{
+			"freezed":       false,
+			"frozen_reason": "",
+			"frozen_by":     nil,
+			"frozen_at":     nil,
+			"frozen_until":  nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
 // Delete soft deletes a user by ID
 func (r *UserRepository) Delete(ctx context.Context, id uint) error {
 	result := r.db.WithContext(ctx).Delete(&model.User{}, id)
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"go-api-starter/internal/model"
 
@@ -32,6 +33,14 @@ func (r *UserRoleRepository) Create(ctx context.Context, userRole *model.UserRol
 	return r.db.WithContext(ctx).Create(userRole).Error
 }
 
+// Update saves the validity window of a user role association
+func (r *UserRoleRepository) Update(ctx context.Context, userRole *model.UserRole) error {
+	return r.db.WithContext(ctx).
+		Model(userRole).
+		Select("starts_at", "expires_at").
+		Updates(userRole).Error
+}
+
 // Delete deletes a user role association
 func (r *UserRoleRepository) Delete(ctx context.Context, userID, roleID uint) error {
 	result := r.db.WithContext(ctx).
@@ -79,3 +88,38 @@ func (r *UserRoleRepository) GetUserIDsByRoleID(ctx context.Context, roleID uint
 		Pluck("user_id", &userIDs).Error
 	return userIDs, err
 }
+
+// GetUserIDsByRoleIDs returns the distinct user IDs holding any of the roles
+func (r *UserRoleRepository) GetUserIDsByRoleIDs(ctx context.Context, roleIDs []uint) ([]uint, error) {
+	var userIDs []uint
+	if len(roleIDs) == 0 {
+		return userIDs, nil
+	}
+	err := r.db.WithContext(ctx).
+		Model(&model.UserRole{}).
+		Where("role_id IN ?", roleIDs).
+		Distinct().
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// FindExpired returns up to limit assignments whose expires_at is at or before now
+func (r *UserRoleRepository) FindExpired(ctx context.Context, now time.Time, limit int) ([]model.UserRole, error) {
+	var userRoles []model.UserRole
+	err := r.db.WithContext(ctx).
+		Preload("Role").
+		Where("expires_at IS NOT NULL AND expires_at <= ?", now).
+		Order("expires_at").
+		Limit(limit).
+		Find(&userRoles).Error
+	return userRoles, err
+}
+
+// DeleteExpired deletes an assignment only if it is still expired at now, so one that was
+// renewed in the meantime is kept. It reports whether a row was deleted.
+func (r *UserRoleRepository) DeleteExpired(ctx context.Context, id uint, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND expires_at IS NOT NULL AND expires_at <= ?", id, now).
+		Delete(&model.UserRole{})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-api-starter/internal/model"
+
+	"gorm.io/gorm"
+)
+
+var ErrWebAuthnCredentialNotFound = errors.New("webauthn credential not found")
+
+// Compile-time interface check
+var _ WebAuthnCredentialRepositoryInterface = (*WebAuthnCredentialRepository)(nil)
+
+// WebAuthnCredentialRepository handles passkey data operations
+type WebAuthnCredentialRepository struct {
+	db *gorm.DB
+}
+
+// NewWebAuthnCredentialRepository creates a new WebAuthnCredentialRepository
+func NewWebAuthnCredentialRepository(db *gorm.DB) *WebAuthnCredentialRepository {
+	return &WebAuthnCredentialRepository{db: db}
+}
+
+// Create creates a new credential
+func (r *WebAuthnCredentialRepository) Create(ctx context.Context, credential *model.WebAuthnCredential) error {
+	return r.db.WithContext(ctx).Create(credential).Error
+}
+
+// FindByCredentialHash finds a credential by the hash of its credential ID, with its user
+func (r *WebAuthnCredentialRepository) FindByCredentialHash(ctx context.Context, credentialHash string) (*model.WebAuthnCredential, error) {
+	var credential model.WebAuthnCredential
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("credential_hash = ?", credentialHash).
+		First(&credential).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWebAuthnCredentialNotFound
+	}
+	return &credential, err
+}
+
+// FindByUserID lists the credentials of a user, newest first
+func (r *WebAuthnCredentialRepository) FindByUserID(ctx context.Context, userID uint) ([]model.WebAuthnCredential, error) {
+	var credentials []model.WebAuthnCredential
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&credentials).Error
+	return credentials, err
+}
+
+// UpdateUsage stores the new signature counter after a successful login
+func (r *WebAuthnCredentialRepository) UpdateUsage(ctx context.Context, id uint, signCount uint32, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&model.WebAuthnCredential{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"sign_count": signCount, "last_used_at": at}).Error
+}
+
+// DeleteBySecUID deletes one credential of a user; false means the user has no such credential
+func (r *WebAuthnCredentialRepository) DeleteBySecUID(ctx context.Context, userID uint, secUID string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND sec_uid = ?", userID, secUID).
+		Delete(&model.WebAuthnCredential{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
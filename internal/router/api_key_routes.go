@@ -0,0 +1,21 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-api-starter/internal/container"
+	"go-api-starter/internal/middleware"
+)
+
+func registerAPIKeyRoutes(api *gin.RouterGroup, c *container.Container, authMw *middleware.AuthMiddleware) {
+	h := c.APIKeyHandler()
+
+	// API Key 不能用来创建或管理 API Key
+	keys := api.Group("/auth/api-keys")
+	keys.Use(authMw.RequireSessionAuth())
+	{
+		keys.POST("", h.Create)
+		keys.GET("", h.List)
+		keys.DELETE("/:id", h.Revoke)
+	}
+}
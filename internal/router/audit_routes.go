@@ -0,0 +1,18 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-api-starter/internal/container"
+	"go-api-starter/internal/middleware"
+)
+
+func registerAuditRoutes(api *gin.RouterGroup, c *container.Container, authMw *middleware.AuthMiddleware, permMw *middleware.PermissionMiddleware) {
+	h := c.AuditHandler()
+
+	audit := api.Group("/audit-logs")
+	audit.Use(authMw.RequireAuth())
+	{
+		audit.GET("", permMw.RequirePermission("audit.read"), h.List)
+	}
+}
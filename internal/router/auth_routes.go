@@ -7,16 +7,42 @@ import (
 	"go-api-starter/internal/middleware"
 )
 
-func registerAuthRoutes(api *gin.RouterGroup, c *container.Container, authMw *middleware.AuthMiddleware) {
+func registerAuthRoutes(api *gin.RouterGroup, c *container.Container, authMw *middleware.AuthMiddleware, permMw *middleware.PermissionMiddleware) {
 	h := c.AuthHandler()
 
+	// 登录会话、两步验证等凭据管理接口只接受登录令牌，不接受 API Key
 	auth := api.Group("/auth")
 	{
+		auth.POST("/code", h.SendCode)
 		auth.POST("/register", h.Register)
 		auth.POST("/login", h.Login)
+		auth.POST("/mfa/verify", h.VerifyMFA)
+		auth.GET("/oidc/providers", h.OIDCProviders)
+		auth.GET("/oidc/:provider/authorize", h.OIDCAuthorize)
+		auth.POST("/oidc/:provider/callback", h.OIDCCallback)
+		auth.POST("/passkey/login/begin", h.BeginPasskeyLogin)
+		auth.POST("/passkey/login/finish", h.PasskeyLogin)
+		auth.POST("/magic-link", h.RequestMagicLink)
+		auth.POST("/magic-link/verify", h.MagicLinkLogin)
+		auth.POST("/qr", h.CreateQRLogin)
+		auth.POST("/qr/poll", h.PollQRLogin)
+		auth.POST("/qr/scan", authMw.RequireSessionAuth(), h.ScanQRLogin)
+		auth.POST("/qr/confirm", authMw.RequireSessionAuth(), h.ConfirmQRLogin)
+		auth.POST("/qr/cancel", authMw.RequireSessionAuth(), h.CancelQRLogin)
 		auth.POST("/refresh", h.RefreshToken)
-		auth.POST("/reset-password/:id", authMw.RequireAuth(), h.ResetPassword)
-		auth.POST("/logout", authMw.RequireAuth(), h.Logout)
-		auth.POST("/logout-all", authMw.RequireAuth(), h.LogoutAllDevices)
+		auth.POST("/forgot-password", h.SelfResetPassword)
+		auth.POST("/reset-password/:id", authMw.RequireSessionAuth(), permMw.RequirePermission("user.update:any"), h.ResetPassword)
+		// 模拟登录令牌也可以登出，管理员借此提前结束模拟
+		auth.POST("/logout", authMw.RequireUserAuth(), h.Logout)
+		auth.POST("/logout-all", authMw.RequireSessionAuth(), h.LogoutAllDevices)
+		auth.GET("/sessions", authMw.RequireSessionAuth(), h.ListSessions)
+		auth.DELETE("/sessions/:id", authMw.RequireSessionAuth(), h.RevokeSession)
+		auth.POST("/mfa/totp/enroll", authMw.RequireSessionAuth(), h.EnrollTOTP)
+		auth.POST("/mfa/totp/activate", authMw.RequireSessionAuth(), h.ActivateTOTP)
+		auth.POST("/mfa/totp/disable", authMw.RequireSessionAuth(), h.DisableTOTP)
+		auth.GET("/passkeys", authMw.RequireSessionAuth(), h.ListPasskeys)
+		auth.POST("/passkeys/register/begin", authMw.RequireSessionAuth(), h.BeginPasskeyRegistration)
+		auth.POST("/passkeys/register/finish", authMw.RequireSessionAuth(), h.FinishPasskeyRegistration)
+		auth.DELETE("/passkeys/:id", authMw.RequireSessionAuth(), h.DeletePasskey)
 	}
 }
@@ -9,7 +9,7 @@ import (
 	"go-api-starter/internal/middleware"
 )
 
-func registerFileRoutes(api *gin.RouterGroup, c *container.Container, authMw *middleware.AuthMiddleware) {
+func registerFileRoutes(api *gin.RouterGroup, c *container.Container, authMw *middleware.AuthMiddleware, permMw *middleware.PermissionMiddleware) {
 	h := c.OSSHandler()
 
 	file := api.Group("/file")
@@ -22,9 +22,9 @@ func registerFileRoutes(api *gin.RouterGroup, c *container.Container, authMw *mi
 	file.GET("/:sec_uid", authMw.OptionalAuth(), h.GetFile)
 
 	// 需要认证
-	file.Use(authMw.RequireAuth())
 	{
-		upload := file.Group("/upload")
+		// 上传需要 file.upload（默认角色持有），只接受用户本人的令牌，不接受 API Key 和客户端令牌
+		upload := file.Group("/upload", authMw.RequireUserAuth(), permMw.RequirePermission("file.upload"))
 		{
 			cfg := c.Config()
 			limit := 120
@@ -52,7 +52,9 @@ func registerFileRoutes(api *gin.RouterGroup, c *container.Container, authMw *mi
 			upload.POST("/abort", h.AbortMultipart)
 		}
 
-		file.PUT("/:sec_uid", h.UpdateFile)
-		file.DELETE("/:sec_uid", h.DeleteFile)
+		// 上传者持有 :own 即可修改 / 删除自己的文件，:any 可操作所有文件
+		owner := c.FileOwnerResolver()
+		file.PUT("/:sec_uid", authMw.RequireAuth(), permMw.RequireResourcePermission("file.update", owner), h.UpdateFile)
+		file.DELETE("/:sec_uid", authMw.RequireAuth(), permMw.RequireResourcePermission("file.delete", owner), h.DeleteFile)
 	}
 }
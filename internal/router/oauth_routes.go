@@ -0,0 +1,21 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-api-starter/internal/container"
+	"go-api-starter/internal/middleware"
+)
+
+func registerOAuthRoutes(api *gin.RouterGroup, c *container.Container, authMw *middleware.AuthMiddleware, permMw *middleware.PermissionMiddleware) {
+	h := c.OAuthHandler()
+
+	// 客户端凭据与 API Key 一样只能用登录令牌管理
+	clients := api.Group("/oauth/clients")
+	clients.Use(authMw.RequireSessionAuth())
+	{
+		clients.POST("", permMw.RequirePermission("oauth.manage"), h.CreateClient)
+		clients.GET("", permMw.RequirePermission("oauth.manage"), h.ListClients)
+		clients.DELETE("/:client_id", permMw.RequirePermission("oauth.manage"), h.RevokeClient)
+	}
+}
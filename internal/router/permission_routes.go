@@ -10,34 +10,39 @@ import (
 func registerPermissionRoutes(api *gin.RouterGroup, c *container.Container, authMw *middleware.AuthMiddleware, permMw *middleware.PermissionMiddleware) {
 	h := c.PermissionHandler()
 
+	// 写操作都检查 role.manage；读接口不检查权限，只接受用户本人的令牌
 	permissions := api.Group("/permissions")
-	permissions.Use(authMw.RequireAuth())
+	auth, userAuth := authMw.RequireAuth(), authMw.RequireUserAuth()
 	{
 		// Permission spaces
-		permissions.POST("/spaces", permMw.RequirePermission("role.manage"), h.CreateSpace)
-		permissions.GET("/spaces", h.GetAllSpaces)
+		permissions.POST("/spaces", auth, permMw.RequirePermission("role.manage"), h.CreateSpace)
+		permissions.GET("/spaces", userAuth, h.GetAllSpaces)
+		permissions.PUT("/spaces/:id", auth, permMw.RequirePermission("role.manage"), h.UpdateSpace)
+		permissions.DELETE("/spaces/:id", auth, permMw.RequirePermission("role.manage"), h.DeleteSpace)
+		permissions.POST("/spaces/:id/compact", auth, permMw.RequirePermission("role.manage"), h.CompactSpace)
 
 		// Permissions
-		permissions.POST("/permissions", permMw.RequirePermission("role.manage"), h.CreatePermission)
-		permissions.GET("/permissions", h.GetAllPermissions)
-		permissions.GET("/permissions/:id", h.GetPermission)
-		permissions.PUT("/permissions/:id", permMw.RequirePermission("role.manage"), h.UpdatePermission)
-		permissions.DELETE("/permissions/:id", permMw.RequirePermission("role.manage"), h.DeletePermission)
+		permissions.POST("/permissions", auth, permMw.RequirePermission("role.manage"), h.CreatePermission)
+		permissions.GET("/permissions", userAuth, h.GetAllPermissions)
+		permissions.GET("/permissions/:id", userAuth, h.GetPermission)
+		permissions.PUT("/permissions/:id", auth, permMw.RequirePermission("role.manage"), h.UpdatePermission)
+		permissions.DELETE("/permissions/:id", auth, permMw.RequirePermission("role.manage"), h.DeletePermission)
+		permissions.POST("/permissions/:id/move", auth, permMw.RequirePermission("role.manage"), h.MovePermission)
 
 		// Roles
-		permissions.POST("/roles", permMw.RequirePermission("role.manage"), h.CreateRole)
-		permissions.GET("/roles", h.GetAllRoles)
-		permissions.GET("/roles/:id", h.GetRole)
-		permissions.PUT("/roles/:id", permMw.RequirePermission("role.manage"), h.UpdateRole)
-		permissions.DELETE("/roles/:id", permMw.RequirePermission("role.manage"), h.DeleteRole)
-		permissions.GET("/roles/:id/permissions", h.GetRolePermissions)
-		permissions.POST("/roles/:id/permissions", permMw.RequirePermission("role.manage"), h.AddRolePermissions)
-		permissions.DELETE("/roles/:id/permissions", permMw.RequirePermission("role.manage"), h.RemoveRolePermissions)
+		permissions.POST("/roles", auth, permMw.RequirePermission("role.manage"), h.CreateRole)
+		permissions.GET("/roles", userAuth, h.GetAllRoles)
+		permissions.GET("/roles/:id", userAuth, h.GetRole)
+		permissions.PUT("/roles/:id", auth, permMw.RequirePermission("role.manage"), h.UpdateRole)
+		permissions.DELETE("/roles/:id", auth, permMw.RequirePermission("role.manage"), h.DeleteRole)
+		permissions.GET("/roles/:id/permissions", userAuth, h.GetRolePermissions)
+		permissions.POST("/roles/:id/permissions", auth, permMw.RequirePermission("role.manage"), h.AddRolePermissions)
+		permissions.DELETE("/roles/:id/permissions", auth, permMw.RequirePermission("role.manage"), h.RemoveRolePermissions)
 
 		// User roles
-		permissions.GET("/users/:sec_uid/roles", h.GetUserRolesBySecUID)
-		permissions.POST("/users/:sec_uid/roles", permMw.RequirePermission("role.manage"), h.AssignUserRoleBySecUID)
-		permissions.DELETE("/users/:sec_uid/roles/:roleId", permMw.RequirePermission("role.manage"), h.RemoveUserRoleBySecUID)
-		permissions.GET("/me/permissions", h.GetMyPermissions)
+		permissions.GET("/users/:sec_uid/roles", userAuth, h.GetUserRolesBySecUID)
+		permissions.POST("/users/:sec_uid/roles", auth, permMw.RequirePermission("role.manage"), h.AssignUserRoleBySecUID)
+		permissions.DELETE("/users/:sec_uid/roles/:roleId", auth, permMw.RequirePermission("role.manage"), h.RemoveUserRoleBySecUID)
+		permissions.GET("/me/permissions", userAuth, h.GetMyPermissions)
 	}
 }
@@ -44,6 +44,7 @@ func Setup(db *gorm.DB) (*gin.Engine, *middleware.PermissionMiddleware, *contain
 	corsConfig.AllowOrigins = cfg.CORS.AllowOrigins
 	corsConfig.AllowMethods = cfg.CORS.AllowMethods
 	corsConfig.AllowHeaders = cfg.CORS.AllowHeaders
+	corsConfig.AllowCredentials = cfg.CORS.AllowCredentials
 	corsConfig.ExposeHeaders = []string{"X-Request-ID", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"}
 	r.Use(cors.New(corsConfig))
 
@@ -52,7 +53,12 @@ func Setup(db *gorm.DB) (*gin.Engine, *middleware.PermissionMiddleware, *contain
 		redisRateLimiter := middleware.NewMultiLevelRateLimiter(c.CacheBackend()).
 			SetGlobalLimit(cfg.RateLimit.GlobalPerMinute, time.Minute).
 			SetUserLimit(cfg.RateLimit.UserPerMinute, time.Minute).
-			SetEndpointLimit("/api/v1/auth/login", cfg.RateLimit.LoginPerMinute, time.Minute)
+			SetEndpointLimit("/api/v1/auth/login", cfg.RateLimit.LoginPerMinute, time.Minute).
+			SetEndpointLimit("/api/v1/auth/code", cfg.RateLimit.LoginPerMinute, time.Minute).
+			SetEndpointLimit("/api/v1/auth/mfa/verify", cfg.RateLimit.LoginPerMinute, time.Minute).
+			SetEndpointLimit("/api/v1/auth/passkey/login/finish", cfg.RateLimit.LoginPerMinute, time.Minute).
+			SetEndpointLimit("/api/v1/auth/magic-link", cfg.RateLimit.LoginPerMinute, time.Minute).
+			SetEndpointLimit("/api/v1/auth/magic-link/verify", cfg.RateLimit.LoginPerMinute, time.Minute)
 		r.Use(redisRateLimiter.RateLimit())
 	} else {
 		rateLimiter := middleware.NewRateLimiter(rate.Limit(cfg.RateLimit.FallbackRPS), cfg.RateLimit.FallbackBurst)
@@ -65,21 +71,34 @@ func Setup(db *gorm.DB) (*gin.Engine, *middleware.PermissionMiddleware, *contain
 	}
 
 	// Build shared middleware
-	authMw := middleware.NewAuthMiddleware(c.JWTSecret(), c.AuthService(), c.UserRepository())
+	authMw := middleware.NewAuthMiddleware(
+		c.JWTManager(), c.AuthService(), c.UserRepository(), c.APIKeyService(), c.ImpersonationService(), c.OAuthService(),
+	)
 	permMw := middleware.NewPermissionMiddleware(c.PermissionService())
 
 	// Health check routes (no auth)
 	r.GET("/health", c.HealthHandler().Health)
 	r.GET("/health/ready", c.HealthHandler().Ready)
 
+	// Public verification keys for downstream services
+	r.GET("/.well-known/jwks.json", c.JWKSHandler().JWKS)
+
+	// Client-credentials tokens, introspection and revocation for backend services (standard OAuth responses)
+	r.POST("/oauth/token", c.OAuthHandler().Token)
+	r.POST("/oauth/introspect", c.OAuthHandler().Introspect)
+	r.POST("/oauth/revoke", c.OAuthHandler().Revoke)
+
 	// API routes
 	api := r.Group("/api/v1")
 
 	// Register module routes
-	registerAuthRoutes(api, c, authMw)
+	registerAuthRoutes(api, c, authMw, permMw)
+	registerAPIKeyRoutes(api, c, authMw)
 	registerUserRoutes(api, c, authMw, permMw)
-	registerFileRoutes(api, c, authMw)
+	registerFileRoutes(api, c, authMw, permMw)
 	registerPermissionRoutes(api, c, authMw, permMw)
+	registerAuditRoutes(api, c, authMw, permMw)
+	registerOAuthRoutes(api, c, authMw, permMw)
 
 	// Documentation routes (protected by Basic Auth)
 	docs.SwaggerInfo.BasePath = "/"
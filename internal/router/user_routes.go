@@ -15,17 +15,29 @@ func registerUserRoutes(api *gin.RouterGroup, c *container.Container, authMw *mi
 	// 公开接口（可选认证）— 查看用户公开资料
 	users.GET("/:sec_uid", authMw.OptionalAuth(), userH.Get)
 
-	// 需要认证的接口
+	// 模拟登录只能由管理员本人的登录会话发起，API Key 和模拟登录令牌都不行
+	users.POST("/:sec_uid/impersonate", authMw.RequireSessionAuth(), permMw.RequirePermission("user.impersonate"), userH.Impersonate)
+
+	// 更换登录用的邮箱 / 手机号同样只接受本人的登录会话
+	users.POST("/me/contact-change", authMw.RequireSessionAuth(), userH.RequestContactChange)
+	users.POST("/me/contact-change/confirm", authMw.RequireSessionAuth(), userH.ConfirmContactChange)
+
+	// 本人资料不检查权限，API Key 的 scope 在这里无从限制，因此只接受用户本人的令牌
+	users.GET("/me", authMw.RequireUserAuth(), userH.GetMe)
+	users.PUT("/me", authMw.RequireUserAuth(), userH.UpdateMe)
+
+	// 需要认证的接口，每个都检查权限
 	users.Use(authMw.RequireAuth())
 	{
-		// Current user endpoints (self-service)
-		users.GET("/me", userH.GetMe)
-		users.PUT("/me", userH.UpdateMe)
-
 		// User management endpoints (需要权限)
 		users.POST("", permMw.RequirePermission("user.create"), userH.Create)
 		users.GET("", permMw.RequirePermission("user.read"), userH.List)
-		users.PUT("/:sec_uid", permMw.RequirePermission("user.update"), userH.Update)
+		// 本人持有 user.update:own 即可编辑自己的资料，?force=true 只对 :any 开放
+		users.PUT("/:sec_uid", permMw.RequireResourcePermission("user.update", c.UserOwnerResolver()), userH.Update)
 		users.DELETE("/:sec_uid", permMw.RequirePermission("user.delete"), userH.Delete)
+		users.POST("/:sec_uid/unlock", permMw.RequirePermission("user.update:any"), userH.Unlock)
+		users.POST("/unlock-ip", permMw.RequirePermission("user.update:any"), userH.UnlockIP)
+		users.POST("/:sec_uid/freeze", permMw.RequirePermission("user.freeze"), userH.Freeze)
+		users.POST("/:sec_uid/unfreeze", permMw.RequirePermission("user.freeze"), userH.Unfreeze)
 	}
 }
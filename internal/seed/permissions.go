@@ -15,20 +15,45 @@ import (
 // 如果 code 不在这里，会自动用 code 本身作为 name
 var permMeta = map[string][2]string{
 	// [0]=中文名称  [1]=描述
-	"user.create": {"创建用户", "允许创建新用户"},
-	"user.read":   {"查看用户", "允许查看用户列表和详情"},
-	"user.update": {"编辑用户", "允许编辑用户信息"},
-	"user.delete": {"删除用户", "允许删除用户"},
-	"role.manage": {"角色管理", "允许管理角色、权限和用户角色分配"},
-	"file.upload": {"上传文件", "允许上传和编辑文件"},
-	"file.delete": {"删除文件", "允许删除文件"},
+	"user.create":      {"创建用户", "允许创建新用户"},
+	"user.read":        {"查看用户", "允许查看用户列表和详情"},
+	"user.update:any":  {"编辑用户", "允许编辑任意用户的信息，可强制修改邮箱 / 手机号"},
+	"user.update:own":  {"编辑本人资料", "允许通过用户管理接口编辑自己的信息"},
+	"user.delete":      {"删除用户", "允许删除用户"},
+	"user.impersonate": {"模拟登录", "允许以其他用户身份登录排查问题，所有操作记入审计日志"},
+	"user.freeze":      {"冻结用户", "允许冻结 / 解冻用户，冻结时注销其全部登录会话"},
+	"role.manage":      {"角色管理", "允许管理角色、权限和用户角色分配"},
+	"file.upload":      {"上传文件", "允许上传文件"},
+	"file.update:any":  {"编辑文件", "允许编辑任意用户上传的文件"},
+	"file.update:own":  {"编辑本人文件", "允许编辑自己上传的文件"},
+	"file.delete:any":  {"删除文件", "允许删除任意用户上传的文件"},
+	"file.delete:own":  {"删除本人文件", "允许删除自己上传的文件"},
+	"audit.read":       {"查看审计日志", "允许查看登录锁定等安全审计日志"},
+	"oauth.manage":     {"OAuth 客户端管理", "允许注册和吊销调用令牌内省 / 吊销接口的后端服务"},
 }
 
 // moduleToSpace 将 module 映射到权限空间
 var moduleToSpace = map[string]string{
-	"user": "system",
-	"role": "system",
-	"file": "content",
+	"user":  "system",
+	"role":  "system",
+	"file":  "content",
+	"audit": "system",
+	"oauth": "system",
+}
+
+// renamedPermissions 记录改名前的 code：改为资源级 code 后原权限原地改名，
+// 保留其权限位和已有的角色授权
+var renamedPermissions = map[string]string{
+	"user.update": "user.update:any",
+	"file.delete": "file.delete:any",
+}
+
+// DefaultRoleName 新用户自动获得的角色，持有所有 :own 权限和 defaultRoleCodes
+const DefaultRoleName = "member"
+
+// defaultRoleCodes 不带 :own 但同样自动授予默认角色的权限
+var defaultRoleCodes = map[string]struct{}{
+	"file.upload": {},
 }
 
 // SyncPermissions 根据路由中实际使用的权限 code 自动同步到数据库
@@ -38,6 +63,7 @@ func SyncPermissions(db *gorm.DB, codes []string) {
 		return
 	}
 	ctx := context.Background()
+	renameLegacyPermissions(db)
 
 	// 1. 查出数据库已有的 code，避免重复创建
 	var existingPerms []model.Permission
@@ -78,14 +104,21 @@ func SyncPermissions(db *gorm.DB, codes []string) {
 		spaceCache[spaceName] = &space
 	}
 
-	// 3. 统计各空间已有权限数量（用于 position）
+	// 3. 各空间下一个可用的 position：删除权限会留下空洞，所以取最大值 + 1 而不是数量
 	posMap := make(map[uint]int)
 	for _, space := range spaceCache {
-		var count int64
-		db.WithContext(ctx).Model(&model.Permission{}).Where("space_id = ?", space.ID).Count(&count)
-		posMap[space.ID] = int(count)
+		var maxPos *int
+		db.WithContext(ctx).Model(&model.Permission{}).Where("space_id = ?", space.ID).Select("MAX(position)").Scan(&maxPos)
+		if maxPos != nil {
+			posMap[space.ID] = *maxPos + 1
+		}
 	}
 
+	// 默认角色自动获得新建的 :own 权限和 defaultRoleCodes；管理员之后撤销的不会被重新授予
+	var defaultRoles []model.Role
+	db.WithContext(ctx).Where("is_default = ?", true).Find(&defaultRoles)
+	granted := 0
+
 	// 4. 创建新权限
 	for _, code := range newCodes {
 		spaceName := spaceNameFromCode(code)
@@ -97,6 +130,10 @@ func SyncPermissions(db *gorm.DB, codes []string) {
 		name, desc := metaFromCode(code)
 		module := moduleFromCode(code)
 		pos := posMap[space.ID]
+		if pos >= 64 {
+			log.Printf("[seed] 权限空间 %s 已满，跳过权限 %s（可先压缩该空间）", spaceName, code)
+			continue
+		}
 
 		perm := model.Permission{
 			Code:        code,
@@ -114,9 +151,100 @@ func SyncPermissions(db *gorm.DB, codes []string) {
 		}
 		posMap[space.ID] = pos + 1
 		log.Printf("[seed] 自动创建权限: %s (%s)", code, name)
+
+		if _, ok := defaultRoleCodes[code]; !ok && !strings.HasSuffix(code, ":"+model.ScopeOwn) {
+			continue
+		}
+		for _, role := range defaultRoles {
+			rp := model.RolePermission{RoleID: role.ID, PermissionID: perm.ID, SpaceID: perm.SpaceID, Value: perm.Value}
+			if err := db.WithContext(ctx).Create(&rp).Error; err != nil {
+				log.Printf("[seed] 给 %s 角色添加权限 %s 失败: %v", role.Name, code, err)
+				continue
+			}
+			granted++
+		}
+	}
+
+	// 权限缓存由启动流程在全部 seed 完成后统一清空
+	if granted > 0 {
+		log.Printf("[seed] 给默认角色授予 %d 个新权限", granted)
 	}
 }
 
+// renameLegacyPermissions 把旧 code 原地改名，同时更新 API Key 和 OAuth 客户端 scopes 中的旧 code
+func renameLegacyPermissions(db *gorm.DB) {
+	ctx := context.Background()
+	for oldCode, newCode := range renamedPermissions {
+		var count int64
+		db.WithContext(ctx).Model(&model.Permission{}).Where("code = ?", newCode).Count(&count)
+		if count > 0 {
+			continue
+		}
+		name, desc := metaFromCode(newCode)
+		result := db.WithContext(ctx).Model(&model.Permission{}).Where("code = ?", oldCode).
+			Updates(map[string]any{"code": newCode, "name": name, "description": desc})
+		if result.Error != nil {
+			log.Printf("[seed] 权限 %s 改名为 %s 失败: %v", oldCode, newCode, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		log.Printf("[seed] 权限 %s 已改名为 %s", oldCode, newCode)
+
+		var keys []model.APIKey
+		db.WithContext(ctx).Where("scopes LIKE ?", "%\""+oldCode+"\"%").Find(&keys)
+		for i := range keys {
+			keys[i].Scopes = replaceScope(keys[i].Scopes, oldCode, newCode)
+			db.WithContext(ctx).Model(&keys[i]).Update("scopes", keys[i].Scopes)
+		}
+		var clients []model.OAuthClient
+		db.WithContext(ctx).Where("scopes LIKE ?", "%\""+oldCode+"\"%").Find(&clients)
+		for i := range clients {
+			clients[i].Scopes = replaceScope(clients[i].Scopes, oldCode, newCode)
+			db.WithContext(ctx).Model(&clients[i]).Update("scopes", clients[i].Scopes)
+		}
+	}
+}
+
+func replaceScope(scopes []string, oldCode, newCode string) []string {
+	for i, code := range scopes {
+		if code == oldCode {
+			scopes[i] = newCode
+		}
+	}
+	return scopes
+}
+
+// SyncDefaultRole 确保默认角色存在。首次创建时分配给所有已有用户，之后的新用户由 UserRepository.CreateWithDefaultRoles 在创建事务中分配
+func SyncDefaultRole(db *gorm.DB) {
+	ctx := context.Background()
+
+	var role model.Role
+	if err := db.WithContext(ctx).Where("name = ?", DefaultRoleName).First(&role).Error; err == nil {
+		return
+	}
+	role = model.Role{Name: DefaultRoleName, Description: "普通用户，管理自己的资料和文件", IsActive: true, IsSystem: true, IsDefault: true}
+	if err := db.WithContext(ctx).Create(&role).Error; err != nil {
+		log.Printf("[seed] 创建 %s 角色失败: %v", DefaultRoleName, err)
+		return
+	}
+
+	var userIDs []uint
+	db.WithContext(ctx).Model(&model.User{}).Pluck("id", &userIDs)
+	userRoles := make([]model.UserRole, 0, len(userIDs))
+	for _, id := range userIDs {
+		userRoles = append(userRoles, model.UserRole{UserID: id, RoleID: role.ID})
+	}
+	if len(userRoles) > 0 {
+		if err := db.WithContext(ctx).CreateInBatches(userRoles, 500).Error; err != nil {
+			log.Printf("[seed] 给已有用户分配 %s 角色失败: %v", DefaultRoleName, err)
+			return
+		}
+	}
+	log.Printf("[seed] 创建 %s 角色并分配给 %d 个已有用户", DefaultRoleName, len(userIDs))
+}
+
 // moduleFromCode 从 "topic.create" 提取 "topic"
 func moduleFromCode(code string) string {
 	parts := strings.SplitN(code, ".", 2)
@@ -167,7 +295,7 @@ func SyncAdminUser(db *gorm.DB, email, password string) {
 	log.Printf("[seed] 已创建默认管理员账号: %s", email)
 }
 
-// SyncAdminRole 确保 admin 角色拥有所有权限，并分配给指定邮箱的用户
+// SyncAdminRole 确保 admin 角色拥有所有权限，并分配给指定邮箱的用户；调用方需在之后清空权限缓存
 func SyncAdminRole(db *gorm.DB, adminEmail string) {
 	ctx := context.Background()
 
@@ -235,7 +363,4 @@ func SyncAdminRole(db *gorm.DB, adminEmail string) {
 		}
 		log.Printf("[seed] 已将 admin 角色分配给 %s", adminEmail)
 	}
-
-	// 6. 清除该用户的权限缓存，使新权限立即生效
-	db.WithContext(ctx).Where("user_id = ?", user.ID).Delete(&model.UserPermissionCache{})
 }
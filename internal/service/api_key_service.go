@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/repository"
+	"go-api-starter/pkg/apperrors"
+	"go-api-starter/pkg/i18n"
+)
+
+const (
+	maxAPIKeysPerUser   = 20
+	apiKeySecretBytes   = 32
+	apiKeyDisplayLen    = 12 // 列表中展示的前缀长度（含 gak_）
+	apiKeyTouchInterval = time.Minute
+)
+
+// APIKeyService manages personal API keys and authenticates requests made with them
+type APIKeyService struct {
+	apiKeyRepo  repository.APIKeyRepositoryInterface
+	permChecker *PermissionChecker
+}
+
+// NewAPIKeyService creates a new APIKeyService
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepositoryInterface, permChecker *PermissionChecker) *APIKeyService {
+	return &APIKeyService{apiKeyRepo: apiKeyRepo, permChecker: permChecker}
+}
+
+// Create issues a new key limited to scopes the user currently holds. The plaintext key is returned only here.
+func (s *APIKeyService) Create(ctx context.Context, userID uint, req *model.CreateAPIKeyRequest) (*model.CreateAPIKeyResponse, error) {
+	count, err := s.apiKeyRepo.CountActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrAPIKeyStoreFailed)
+	}
+	if count >= maxAPIKeysPerUser {
+		return nil, apperrors.ConflictCode(i18n.ErrAPIKeyLimitReached)
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	seen := make(map[string]bool, len(req.Scopes))
+	for _, code := range req.Scopes {
+		code = strings.TrimSpace(code)
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		ok, err := s.permChecker.HasPermission(ctx, userID, code)
+		if err != nil {
+			return nil, apperrors.InternalCode(err, i18n.ErrAPIKeyStoreFailed)
+		}
+		if !ok {
+			return nil, apperrors.ForbiddenCode(i18n.ErrAPIKeyScopeDenied)
+		}
+		scopes = append(scopes, code)
+	}
+	if len(scopes) == 0 {
+		return nil, apperrors.BadRequestCode(i18n.ErrAPIKeyScopeDenied)
+	}
+
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrAPIKeyStoreFailed)
+	}
+	plaintext := model.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	key := &model.APIKey{
+		UserID:  userID,
+		Name:    req.Name,
+		Prefix:  plaintext[:apiKeyDisplayLen],
+		KeyHash: hashToken(plaintext),
+		Scopes:  scopes,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrAPIKeyStoreFailed)
+	}
+
+	return &model.CreateAPIKeyResponse{Key: plaintext, APIKeyResponse: key.ToResponse()}, nil
+}
+
+// List returns the user's active keys
+func (s *APIKeyService) List(ctx context.Context, userID uint) ([]*model.APIKeyResponse, error) {
+	keys, err := s.apiKeyRepo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrAPIKeyStoreFailed)
+	}
+	result := make([]*model.APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		result = append(result, keys[i].ToResponse())
+	}
+	return result, nil
+}
+
+// Revoke revokes one of the user's keys
+func (s *APIKeyService) Revoke(ctx context.Context, userID uint, id string) error {
+	revoked, err := s.apiKeyRepo.RevokeBySecUID(ctx, userID, id)
+	if err != nil {
+		return apperrors.InternalCode(err, i18n.ErrAPIKeyStoreFailed)
+	}
+	if !revoked {
+		return apperrors.NotFoundCode(i18n.ErrAPIKeyNotFound)
+	}
+	return nil
+}
+
+// Authenticate resolves a plaintext key to an active API key
+func (s *APIKeyService) Authenticate(ctx context.Context, plaintext string) (*model.APIKey, error) {
+	if !strings.HasPrefix(plaintext, model.APIKeyPrefix) {
+		return nil, apperrors.UnauthorizedCode(i18n.ErrAPIKeyInvalid)
+	}
+	key, err := s.apiKeyRepo.FindByHash(ctx, hashToken(plaintext))
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, apperrors.UnauthorizedCode(i18n.ErrAPIKeyInvalid)
+		}
+		return nil, apperrors.InternalCode(err, i18n.ErrAPIKeyStoreFailed)
+	}
+	if !key.IsActive() {
+		return nil, apperrors.UnauthorizedCode(i18n.ErrAPIKeyInvalid)
+	}
+
+	// 最近使用时间只需分钟级精度，避免每个请求都写库
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		_ = s.apiKeyRepo.TouchLastUsed(ctx, key.ID, now)
+		key.LastUsedAt = &now
+	}
+	return key, nil
+}
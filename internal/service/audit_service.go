@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/repository"
+	"go-api-starter/pkg/apperrors"
+	"go-api-starter/pkg/i18n"
+	"go-api-starter/pkg/logger"
+)
+
+// AuditService records security events and lets administrators review them
+type AuditService struct {
+	auditRepo repository.AuditLogRepositoryInterface
+}
+
+// NewAuditService creates a new AuditService
+func NewAuditService(auditRepo repository.AuditLogRepositoryInterface) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+// Record stores an audit entry. A failed write is logged but never fails the operation being audited.
+func (s *AuditService) Record(ctx context.Context, entry *model.AuditLog) {
+	if err := s.auditRepo.Create(ctx, entry); err != nil && logger.Log != nil {
+		logger.Log.Errorf("failed to write audit log: action=%s subject=%s err=%v", entry.Action, entry.Subject, err)
+	}
+}
+
+// List returns audit logs matching the filter, newest first by default
+func (s *AuditService) List(ctx context.Context, filter model.AuditLogFilter, offset, limit int, sort string) ([]model.AuditLog, int64, error) {
+	logs, total, err := s.auditRepo.List(ctx, filter, offset, limit, sort)
+	if err != nil {
+		return nil, 0, apperrors.InternalCode(err, i18n.ErrAuditQueryFailed)
+	}
+	return logs, total, nil
+}
@@ -10,6 +10,7 @@ import (
 	"go-api-starter/pkg/apperrors"
 	"go-api-starter/pkg/auth"
 	"go-api-starter/pkg/i18n"
+	"go-api-starter/pkg/logger"
 )
 
 // AuthService handles authentication business logic
@@ -17,21 +18,48 @@ type AuthService struct {
 	userRepo       repository.UserRepositoryInterface
 	jwtManager     *auth.JWTManager
 	passwordHasher *auth.PasswordHasher
-	tokenBlacklist TokenBlacklist
+	tokenBlacklist   TokenBlacklist
+	codeService      *VerificationCodeService
+	refreshTokenRepo repository.RefreshTokenRepositoryInterface
+	sessionService   *SessionService
+	mfaService       *MFAService
+	oidcService      *OIDCService
+	qrLoginService   *QRLoginService
+	loginGuard       *LoginGuard
+	passwordService  *PasswordService
+	passkeyService   *PasskeyService
+	magicLinkService *MagicLinkService
+	permChecker      *PermissionChecker
 }
 
 // NewAuthService creates a new AuthService
-func NewAuthService(userRepo repository.UserRepositoryInterface, jwtManager *auth.JWTManager, blacklist TokenBlacklist) *AuthService {
+func NewAuthService(userRepo repository.UserRepositoryInterface, jwtManager *auth.JWTManager, blacklist TokenBlacklist, codeService *VerificationCodeService, refreshTokenRepo repository.RefreshTokenRepositoryInterface, sessionService *SessionService, mfaService *MFAService, oidcService *OIDCService, qrLoginService *QRLoginService, loginGuard *LoginGuard, passwordService *PasswordService, passkeyService *PasskeyService, magicLinkService *MagicLinkService, permChecker *PermissionChecker) *AuthService {
 	return &AuthService{
-		userRepo:       userRepo,
-		jwtManager:     jwtManager,
-		passwordHasher: auth.NewPasswordHasher(),
-		tokenBlacklist: blacklist,
+		userRepo:         userRepo,
+		jwtManager:       jwtManager,
+		passwordHasher:   auth.NewPasswordHasher(),
+		tokenBlacklist:   blacklist,
+		codeService:      codeService,
+		refreshTokenRepo: refreshTokenRepo,
+		sessionService:   sessionService,
+		mfaService:       mfaService,
+		oidcService:      oidcService,
+		qrLoginService:   qrLoginService,
+		loginGuard:       loginGuard,
+		passwordService:  passwordService,
+		passkeyService:   passkeyService,
+		magicLinkService: magicLinkService,
+		permChecker:      permChecker,
 	}
 }
 
+// SendVerificationCode issues a one-time code for login or registration
+func (s *AuthService) SendVerificationCode(ctx context.Context, req *model.SendCodeRequest, clientIP string) (*model.SendCodeResponse, error) {
+	return s.codeService.Send(ctx, req, clientIP)
+}
+
 // Register creates a new user account
-func (s *AuthService) Register(ctx context.Context, req *model.RegisterRequest) (*model.LoginResponse, error) {
+func (s *AuthService) Register(ctx context.Context, req *model.RegisterRequest, client model.ClientInfo) (*model.LoginResponse, error) {
 	// Validate that at least one of mobile or email is provided
 	if req.Mobile == nil && req.Email == nil {
 		return nil, apperrors.BadRequestCode(i18n.ErrMobileOrEmailRequired)
@@ -59,6 +87,16 @@ func (s *AuthService) Register(ctx context.Context, req *model.RegisterRequest)
 		}
 	}
 
+	// Check the password before the code is consumed
+	if err := s.passwordService.CheckPolicy("password", req.Password); err != nil {
+		return nil, err
+	}
+
+	// Verify the code sent to the new account
+	if err := s.codeService.Verify(ctx, model.CodePurposeRegister, req.CodeTarget(), req.Code); err != nil {
+		return nil, err
+	}
+
 	// Hash password using Argon2
 	hashedPassword, err := s.passwordHasher.HashPassword(req.Password)
 	if err != nil {
@@ -73,31 +111,40 @@ func (s *AuthService) Register(ctx context.Context, req *model.RegisterRequest)
 		Freezed:  false,
 	}
 
-	if err := s.userRepo.Create(ctx, user); err != nil {
+	if err := s.userRepo.CreateWithDefaultRoles(ctx, user); err != nil {
 		return nil, apperrors.InternalCode(err, i18n.ErrCreateUserFailed)
 	}
+	s.passwordService.Remember(ctx, user.ID, hashedPassword)
 
 	// Generate JWT tokens for the newly registered user
-	accessToken, refreshToken, err := s.jwtManager.GenerateTokenPair(user.ID)
-	if err != nil {
-		return nil, apperrors.InternalCode(err, i18n.ErrGenerateTokenFailed)
-	}
-
-	return &model.LoginResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    s.jwtManager.AccessTokenExpiresIn(),
-		User:         user.ToResponse(),
-	}, nil
+	return s.issueTokens(ctx, user, client)
 }
 
 // Login authenticates a user and returns JWT tokens
-func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest) (*model.LoginResponse, error) {
+func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest, client model.ClientInfo) (*model.LoginResponse, error) {
 	// Validate that at least one of mobile or email is provided
 	if req.Mobile == nil && req.Email == nil {
 		return nil, apperrors.BadRequestCode(i18n.ErrMobileOrEmailRequired)
 	}
 
+	// Locked accounts and IPs are rejected before any credential is checked
+	account := normalizeAccount(req.Account)
+	if err := s.loginGuard.Check(ctx, client.IP, account); err != nil {
+		return nil, err
+	}
+
+	// Verification-code login checks the code before revealing anything about the account.
+	// Wrong guesses count towards the lockout like wrong passwords.
+	if req.LoginType == model.LoginTypeCode {
+		if err := s.codeService.Verify(ctx, model.CodePurposeLogin, req.Account, req.Code); err != nil {
+			var appErr *apperrors.AppError
+			if errors.As(err, &appErr) && (appErr.Code == i18n.ErrCodeInvalid || appErr.Code == i18n.ErrCodeExpired) {
+				s.loginGuard.RecordFailure(ctx, client.IP, 0, account)
+			}
+			return nil, err
+		}
+	}
+
 	var user *model.User
 	var err error
 
@@ -110,7 +157,7 @@ func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest) (*mode
 
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
-			return nil, apperrors.UnauthorizedCode(i18n.ErrWrongCredentials)
+			return nil, s.loginFailed(ctx, account, client.IP, 0)
 		}
 		return nil, apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
 	}
@@ -120,22 +167,264 @@ func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest) (*mode
 		return nil, apperrors.ForbiddenCode(i18n.ErrAccountFrozen)
 	}
 
-	// Verify password using Argon2
-	if user.Password == nil {
-		return nil, apperrors.UnauthorizedCode(i18n.ErrWrongCredentials)
+	// Verify password using Argon2 (skipped when the code was already verified)
+	if req.LoginType != model.LoginTypeCode {
+		if user.Password == nil {
+			return nil, s.loginFailed(ctx, account, client.IP, user.ID)
+		}
+		valid, err := s.passwordHasher.VerifyPassword(req.Password, *user.Password)
+		if err != nil {
+			return nil, apperrors.InternalCode(err, i18n.ErrVerifyPasswordFailed)
+		}
+		if !valid {
+			return nil, s.loginFailed(ctx, account, client.IP, user.ID)
+		}
+		s.rehashPassword(ctx, user, req.Password)
 	}
-	valid, err := s.passwordHasher.VerifyPassword(req.Password, *user.Password)
+
+	// Accounts with a second factor get an mfa_pending token instead of real tokens.
+	// The failure counter is only cleared once the second factor has passed too.
+	pending, err := s.mfaService.Challenge(ctx, user)
 	if err != nil {
-		return nil, apperrors.InternalCode(err, i18n.ErrVerifyPasswordFailed)
+		return nil, err
 	}
-	if !valid {
-		return nil, apperrors.UnauthorizedCode(i18n.ErrWrongCredentials)
+	if pending != nil {
+		return pending, nil
 	}
+	s.loginGuard.RecordSuccess(ctx, account)
 
 	// Generate JWT tokens
-	accessToken, refreshToken, err := s.jwtManager.GenerateTokenPair(user.ID)
+	return s.issueTokens(ctx, user, client)
+}
+
+// rehashPassword upgrades a verified hash that uses a legacy algorithm or outdated
+// Argon2 parameters. Failures are only logged; the login itself already succeeded.
+func (s *AuthService) rehashPassword(ctx context.Context, user *model.User, password string) {
+	if !s.passwordHasher.NeedsRehash(*user.Password) {
+		return
+	}
+	hashedPassword, err := s.passwordHasher.HashPassword(password)
+	if err == nil {
+		user.Password = &hashedPassword
+		err = s.userRepo.Update(ctx, user)
+	}
+	if err != nil && logger.Log != nil {
+		logger.Log.Warnf("rehash password of user %d: %v", user.ID, err)
+	}
+}
+
+// loginFailed counts a failed login towards the lockout thresholds
+func (s *AuthService) loginFailed(ctx context.Context, account, ip string, userID uint) error {
+	s.loginGuard.RecordFailure(ctx, ip, userID, account)
+	return apperrors.UnauthorizedCode(i18n.ErrWrongCredentials)
+}
+
+// VerifyMFA completes a two-step login: the mfa_pending token plus a TOTP or recovery code.
+// Wrong codes count towards the lockout of the user's email and mobile and of the IP.
+func (s *AuthService) VerifyMFA(ctx context.Context, req *model.MFAVerifyRequest, client model.ClientInfo) (*model.LoginResponse, error) {
+	userID, err := s.mfaService.PendingUserID(req.MFAToken)
 	if err != nil {
-		return nil, apperrors.InternalCode(err, i18n.ErrGenerateTokenFailed)
+		return nil, err
+	}
+	pendingUser, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, apperrors.UnauthorizedCode(i18n.ErrMFATokenInvalid)
+		}
+		return nil, apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+	accounts := loginAccounts(pendingUser)
+	if err := s.loginGuard.Check(ctx, client.IP, accounts...); err != nil {
+		return nil, err
+	}
+
+	user, err := s.mfaService.VerifyChallenge(ctx, req.MFAToken, req.Code)
+	if err != nil {
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) && appErr.Code == i18n.ErrMFACodeInvalid {
+			s.loginGuard.RecordFailure(ctx, client.IP, userID, accounts...)
+		}
+		return nil, err
+	}
+	s.loginGuard.RecordSuccess(ctx, accounts...)
+	return s.issueTokens(ctx, user, client)
+}
+
+// OIDCProviders lists the configured external identity providers
+func (s *AuthService) OIDCProviders() []model.OIDCProviderResponse {
+	return s.oidcService.Providers()
+}
+
+// OIDCAuthorize starts a login with an external identity provider
+func (s *AuthService) OIDCAuthorize(ctx context.Context, provider string) (*model.OIDCAuthorizeResponse, error) {
+	return s.oidcService.Authorize(ctx, provider)
+}
+
+// OIDCLogin completes an external login. It goes through the same frozen-account
+// and second-factor checks as a password login.
+func (s *AuthService) OIDCLogin(ctx context.Context, provider string, req *model.OIDCCallbackRequest, client model.ClientInfo) (*model.LoginResponse, error) {
+	user, err := s.oidcService.Authenticate(ctx, provider, req.Code, req.State)
+	if err != nil {
+		return nil, err
+	}
+	if user.Freezed {
+		return nil, apperrors.ForbiddenCode(i18n.ErrAccountFrozen)
+	}
+
+	pending, err := s.mfaService.Challenge(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		return pending, nil
+	}
+	return s.issueTokens(ctx, user, client)
+}
+
+// BeginPasskeyLogin returns the WebAuthn options for signing in with a passkey
+func (s *AuthService) BeginPasskeyLogin(ctx context.Context, req *model.PasskeyLoginBeginRequest) (*model.PasskeyRequestResponse, error) {
+	return s.passkeyService.BeginLogin(ctx, req)
+}
+
+// PasskeyLogin completes a passkey login. The authenticator has verified the user
+// (biometrics or PIN) on a device they own, so no second factor is asked for.
+func (s *AuthService) PasskeyLogin(ctx context.Context, req *model.PasskeyLoginRequest, client model.ClientInfo) (*model.LoginResponse, error) {
+	user, err := s.passkeyService.Authenticate(ctx, &req.Credential)
+	if err != nil {
+		return nil, err
+	}
+	if user.Freezed {
+		return nil, apperrors.ForbiddenCode(i18n.ErrAccountFrozen)
+	}
+	return s.issueTokens(ctx, user, client)
+}
+
+// RequestMagicLink emails a sign-in link and returns the nonce that binds it to the requesting browser
+func (s *AuthService) RequestMagicLink(ctx context.Context, req *model.MagicLinkRequest) (*model.MagicLinkResponse, string, error) {
+	return s.magicLinkService.Request(ctx, req.Email)
+}
+
+// MagicLinkLogin completes an email link login. Opening the link only proves access to
+// the mailbox, so the second factor is still asked for as with a password.
+func (s *AuthService) MagicLinkLogin(ctx context.Context, req *model.MagicLinkVerifyRequest, nonce string, client model.ClientInfo) (*model.LoginResponse, error) {
+	user, err := s.magicLinkService.Verify(ctx, req.Token, nonce)
+	if err != nil {
+		return nil, err
+	}
+	if user.Freezed {
+		return nil, apperrors.ForbiddenCode(i18n.ErrAccountFrozen)
+	}
+
+	pending, err := s.mfaService.Challenge(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		return pending, nil
+	}
+	return s.issueTokens(ctx, user, client)
+}
+
+// CreateQRLogin starts a QR login on the desktop
+func (s *AuthService) CreateQRLogin(ctx context.Context, client model.ClientInfo) (*model.QRCreateResponse, error) {
+	return s.qrLoginService.Create(ctx, client)
+}
+
+// PollQRLogin reports the QR login state to the desktop and, once the mobile user
+// confirmed, issues a session for the desktop. The confirming device is already
+// signed in, so no second factor is asked for here.
+func (s *AuthService) PollQRLogin(ctx context.Context, req *model.QRPollRequest) (*model.QRPollResponse, error) {
+	session, err := s.qrLoginService.Poll(ctx, req.Key, req.PollToken)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != model.QRStatusConfirmed {
+		return &model.QRPollResponse{Status: session.Status}, nil
+	}
+
+	user, err := s.userRepo.FindByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, apperrors.NotFoundCode(i18n.ErrUserNotFound)
+		}
+		return nil, apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+	if user.Freezed {
+		return nil, apperrors.ForbiddenCode(i18n.ErrAccountFrozen)
+	}
+
+	login, err := s.issueTokens(ctx, user, session.Client)
+	if err != nil {
+		return nil, err
+	}
+	return &model.QRPollResponse{Status: session.Status, Login: login}, nil
+}
+
+// ScanQRLogin binds a QR login to the signed-in mobile user
+func (s *AuthService) ScanQRLogin(ctx context.Context, userID uint, key string) (*model.QRScanResponse, error) {
+	return s.qrLoginService.Scan(ctx, key, userID)
+}
+
+// ConfirmQRLogin approves a scanned QR login
+func (s *AuthService) ConfirmQRLogin(ctx context.Context, userID uint, key string) error {
+	return s.qrLoginService.Confirm(ctx, key, userID)
+}
+
+// CancelQRLogin rejects a scanned QR login
+func (s *AuthService) CancelQRLogin(ctx context.Context, userID uint, key string) error {
+	return s.qrLoginService.Cancel(ctx, key, userID)
+}
+
+// EnrollTOTP starts TOTP setup for the current user
+func (s *AuthService) EnrollTOTP(ctx context.Context, userID uint) (*model.MFAEnrollResponse, error) {
+	return s.mfaService.Enroll(ctx, userID)
+}
+
+// ActivateTOTP enables TOTP after the user proves the authenticator app works
+func (s *AuthService) ActivateTOTP(ctx context.Context, userID uint, code string) error {
+	return s.mfaService.Activate(ctx, userID, code)
+}
+
+// DisableTOTP turns TOTP off for the current user
+func (s *AuthService) DisableTOTP(ctx context.Context, userID uint, code string) error {
+	return s.mfaService.Disable(ctx, userID, code)
+}
+
+// BeginPasskeyRegistration returns the WebAuthn options for adding a passkey
+func (s *AuthService) BeginPasskeyRegistration(ctx context.Context, userID uint) (*model.PasskeyCreationResponse, error) {
+	return s.passkeyService.BeginRegistration(ctx, userID)
+}
+
+// FinishPasskeyRegistration stores the passkey created by the browser
+func (s *AuthService) FinishPasskeyRegistration(ctx context.Context, userID uint, req *model.PasskeyRegisterRequest) (*model.PasskeyResponse, error) {
+	return s.passkeyService.FinishRegistration(ctx, userID, req)
+}
+
+// ListPasskeys returns the user's passkeys
+func (s *AuthService) ListPasskeys(ctx context.Context, userID uint) ([]*model.PasskeyResponse, error) {
+	return s.passkeyService.List(ctx, userID)
+}
+
+// DeletePasskey removes one of the user's passkeys
+func (s *AuthService) DeletePasskey(ctx context.Context, userID uint, id string) error {
+	return s.passkeyService.Delete(ctx, userID, id)
+}
+
+// issueTokens starts a new session for the client and returns its access token
+// and the first refresh token of the session's family
+func (s *AuthService) issueTokens(ctx context.Context, user *model.User, client model.ClientInfo) (*model.LoginResponse, error) {
+	session, err := s.sessionService.Start(ctx, user.ID, client, s.refreshExpiry())
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.issueAccessToken(user.ID, session.SecUID)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := s.issueRefreshToken(ctx, user.ID, session.FamilyID, "")
+	if err != nil {
+		return nil, err
 	}
 
 	return &model.LoginResponse{
@@ -146,16 +435,114 @@ func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest) (*mode
 	}, nil
 }
 
-// RefreshToken generates a new access token from a refresh token
-func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (string, error) {
-	accessToken, err := s.jwtManager.RefreshAccessToken(refreshToken)
+// issueAccessToken generates an access token bound to a session
+func (s *AuthService) issueAccessToken(userID uint, sessionID string) (string, error) {
+	accessToken, err := s.jwtManager.GenerateSessionAccessToken(userID, sessionID)
+	if err != nil {
+		return "", apperrors.InternalCode(err, i18n.ErrGenerateTokenFailed)
+	}
+	return accessToken, nil
+}
+
+// refreshExpiry returns when a refresh token issued now expires
+func (s *AuthService) refreshExpiry() time.Time {
+	return time.Now().Add(time.Duration(s.jwtManager.RefreshTokenExpiresIn()) * time.Second)
+}
+
+// issueRefreshToken generates a refresh token in the given family and persists it for rotation tracking
+func (s *AuthService) issueRefreshToken(ctx context.Context, userID uint, familyID, parentJTI string) (string, error) {
+	refreshToken, claims, err := s.jwtManager.IssueRefreshToken(userID)
+	if err != nil {
+		return "", apperrors.InternalCode(err, i18n.ErrGenerateTokenFailed)
+	}
+
+	record := &model.RefreshToken{
+		JTI:       claims.ID,
+		FamilyID:  familyID,
+		ParentJTI: parentJTI,
+		UserID:    userID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	if err := s.refreshTokenRepo.Create(ctx, record); err != nil {
+		return "", apperrors.InternalCode(err, i18n.ErrGenerateTokenFailed)
+	}
+	return refreshToken, nil
+}
+
+// RefreshToken rotates a refresh token: the presented token is revoked and a new
+// access token plus a child refresh token in the same family are returned.
+// Presenting a token that was already rotated revokes the whole family (OAuth 2.0 Security BCP).
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*model.RefreshTokenResponse, error) {
+	claims, err := s.jwtManager.ValidateRefreshToken(refreshToken)
 	if err != nil {
 		if errors.Is(err, auth.ErrTokenExpired) {
-			return "", apperrors.UnauthorizedCode(i18n.ErrRefreshTokenExpired)
+			return nil, apperrors.UnauthorizedCode(i18n.ErrRefreshTokenExpired)
 		}
-		return "", apperrors.UnauthorizedCode(i18n.ErrInvalidRefreshToken)
+		return nil, apperrors.UnauthorizedCode(i18n.ErrInvalidRefreshToken)
 	}
-	return accessToken, nil
+
+	record, err := s.refreshTokenRepo.FindByJTI(ctx, claims.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return nil, apperrors.UnauthorizedCode(i18n.ErrInvalidRefreshToken)
+		}
+		return nil, apperrors.InternalCode(err, i18n.ErrGenerateTokenFailed)
+	}
+	if record.UserID != claims.UserID {
+		return nil, apperrors.UnauthorizedCode(i18n.ErrInvalidRefreshToken)
+	}
+
+	session, err := s.sessionService.FindByFamily(ctx, record.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	if !session.IsActive() {
+		return nil, apperrors.UnauthorizedCode(i18n.ErrSessionRevoked)
+	}
+
+	rotated, err := s.refreshTokenRepo.RevokeIfActive(ctx, record.JTI)
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrGenerateTokenFailed)
+	}
+	if !rotated {
+		// Reuse of a rotated (or revoked) token: assume it was stolen and kill the family
+		if err := s.sessionService.RevokeFamily(ctx, record.FamilyID); err != nil {
+			return nil, err
+		}
+		if logger.Log != nil {
+			logger.Log.Warnf("refresh token reuse detected: user=%d family=%s jti=%s", record.UserID, record.FamilyID, record.JTI)
+		}
+		return nil, apperrors.UnauthorizedCode(i18n.ErrRefreshTokenReused)
+	}
+
+	user, err := s.userRepo.FindByID(ctx, record.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, apperrors.UnauthorizedCode(i18n.ErrInvalidRefreshToken)
+		}
+		return nil, apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+	if user.Freezed {
+		return nil, apperrors.ForbiddenCode(i18n.ErrAccountFrozen)
+	}
+
+	accessToken, err := s.issueAccessToken(user.ID, session.SecUID)
+	if err != nil {
+		return nil, err
+	}
+	newRefreshToken, err := s.issueRefreshToken(ctx, user.ID, record.FamilyID, record.JTI)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionService.Extend(ctx, session, s.refreshExpiry()); err != nil {
+		return nil, err
+	}
+
+	return &model.RefreshTokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    s.jwtManager.AccessTokenExpiresIn(),
+	}, nil
 }
 
 // AccessTokenExpiresIn returns the access token duration in seconds
@@ -175,8 +562,10 @@ func (s *AuthService) GetCurrentUser(ctx context.Context, userID uint) (*model.U
 	return user, nil
 }
 
-// ResetPassword resets a user's password (admin)
-func (s *AuthService) ResetPassword(ctx context.Context, userID uint, req *model.ResetPasswordRequest) error {
+// ResetPassword resets a user's password (admin). Only a role manager may reset the
+// password of another role manager, otherwise taking over such an account would be
+// enough to grant oneself any role.
+func (s *AuthService) ResetPassword(ctx context.Context, actorID, userID uint, req *model.ResetPasswordRequest) error {
 	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
@@ -184,6 +573,16 @@ func (s *AuthService) ResetPassword(ctx context.Context, userID uint, req *model
 		}
 		return apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
 	}
+	if err := s.permChecker.checkPrivileged(ctx, actorID, user.ID, i18n.ErrResetPasswordDenied); err != nil {
+		return err
+	}
+
+	if err := s.passwordService.CheckPolicy("new_password", req.NewPassword); err != nil {
+		return err
+	}
+	if err := s.passwordService.CheckHistory(ctx, user, "new_password", req.NewPassword); err != nil {
+		return err
+	}
 
 	hashedPassword, err := s.passwordHasher.HashPassword(req.NewPassword)
 	if err != nil {
@@ -194,21 +593,76 @@ func (s *AuthService) ResetPassword(ctx context.Context, userID uint, req *model
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return apperrors.InternalCode(err, i18n.ErrResetPasswordFailed)
 	}
+	s.passwordService.Remember(ctx, user.ID, hashedPassword)
 	return nil
 }
 
-// Logout invalidates the current token
-func (s *AuthService) Logout(ctx context.Context, token string) error {
-	if s.tokenBlacklist == nil {
-		return nil
+// SelfResetPassword resets a forgotten password after verifying a one-time code,
+// then revokes every existing session of the account
+func (s *AuthService) SelfResetPassword(ctx context.Context, req *model.SelfResetPasswordRequest) error {
+	req.ResolveAccount()
+	if req.Mobile == nil && req.Email == nil {
+		return apperrors.BadRequestCode(i18n.ErrProvideMobileOrEmail)
+	}
+
+	// Check the password before the code is consumed
+	if err := s.passwordService.CheckPolicy("new_password", req.NewPassword); err != nil {
+		return err
+	}
+
+	if err := s.codeService.Verify(ctx, model.CodePurposeResetPassword, req.Account, req.Code); err != nil {
+		return err
+	}
+
+	var user *model.User
+	var err error
+	if req.Mobile != nil {
+		user, err = s.userRepo.FindByMobile(ctx, *req.Mobile)
+	} else {
+		user, err = s.userRepo.FindByEmail(ctx, *req.Email)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return apperrors.NotFoundCode(i18n.ErrAccountNotRegistered)
+		}
+		return apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+	if err := s.passwordService.CheckHistory(ctx, user, "new_password", req.NewPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.passwordHasher.HashPassword(req.NewPassword)
+	if err != nil {
+		return apperrors.InternalCode(err, i18n.ErrHashPasswordFailed)
 	}
 
+	user.Password = &hashedPassword
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return apperrors.InternalCode(err, i18n.ErrResetPasswordFailed)
+	}
+	s.passwordService.Remember(ctx, user.ID, hashedPassword)
+
+	return s.LogoutAllDevices(ctx, user.ID)
+}
+
+// Logout ends the session of the current token and blacklists the token itself.
+// Impersonation tokens have no session, so for them this only ends the impersonation.
+func (s *AuthService) Logout(ctx context.Context, token string) error {
 	claims, err := s.jwtManager.ValidateToken(token)
 	if err != nil {
 		// Token already invalid, no need to blacklist
 		return nil
 	}
 
+	if claims.SessionID != "" {
+		if err := s.sessionService.End(ctx, claims.UserID, claims.SessionID); err != nil {
+			return err
+		}
+	}
+	if s.tokenBlacklist == nil {
+		return nil
+	}
+
 	ttl := time.Until(claims.ExpiresAt.Time)
 	if ttl <= 0 {
 		return nil
@@ -217,20 +671,49 @@ func (s *AuthService) Logout(ctx context.Context, token string) error {
 	return s.tokenBlacklist.Add(ctx, token, ttl)
 }
 
-// LogoutAllDevices invalidates all tokens for a user
+// LogoutAllDevices revokes every session of a user; their access tokens stop working immediately
 func (s *AuthService) LogoutAllDevices(ctx context.Context, userID uint) error {
-	if s.tokenBlacklist == nil {
-		return nil
+	return s.sessionService.RevokeAll(ctx, userID)
+}
+
+// ListSessions lists the user's active sessions, marking the one the request was made from
+func (s *AuthService) ListSessions(ctx context.Context, userID uint, currentSessionID string) ([]*model.SessionResponse, error) {
+	sessions, err := s.sessionService.List(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
-	return s.tokenBlacklist.InvalidateUserTokens(ctx, userID)
+
+	result := make([]*model.SessionResponse, 0, len(sessions))
+	for i := range sessions {
+		result = append(result, sessions[i].ToResponse(sessions[i].SecUID == currentSessionID))
+	}
+	return result, nil
+}
+
+// RevokeSession revokes one of the user's sessions (signs that device out)
+func (s *AuthService) RevokeSession(ctx context.Context, userID uint, sessionID string) error {
+	return s.sessionService.Revoke(ctx, userID, sessionID)
 }
 
-// IsTokenBlacklisted checks if a token is blacklisted
+// IsTokenBlacklisted reports whether a token was blacklisted on logout or belongs to a revoked session
 func (s *AuthService) IsTokenBlacklisted(ctx context.Context, token string) (bool, error) {
-	if s.tokenBlacklist == nil {
+	if s.tokenBlacklist != nil {
+		blacklisted, err := s.tokenBlacklist.IsBlacklisted(ctx, token)
+		if err != nil || blacklisted {
+			return blacklisted, err
+		}
+	}
+
+	claims, err := s.jwtManager.ValidateToken(token)
+	if err != nil || claims.SessionID == "" {
+		// Invalid tokens are rejected by the caller's own validation
 		return false, nil
 	}
-	return s.tokenBlacklist.IsBlacklisted(ctx, token)
+	active, err := s.sessionService.IsActive(ctx, claims.UserID, claims.SessionID)
+	if err != nil {
+		return false, err
+	}
+	return !active, nil
 }
 
 // ValidateToken validates a JWT token and returns the user ID
@@ -13,6 +13,7 @@ var (
 	ErrPermissionSpaceNotFound   = errors.New("permission space not found")
 	ErrPermissionSpaceNameExists = errors.New("permission space name already exists")
 	ErrPermissionSpaceFull       = errors.New("permission space has reached maximum capacity (64)")
+	ErrPermissionSpaceNotEmpty   = errors.New("permission space still has permissions")
 	ErrPermissionNotFound        = errors.New("permission not found")
 	ErrPermissionCodeExists      = errors.New("permission code already exists")
 	ErrRoleNotFound              = errors.New("role not found")
@@ -20,6 +21,9 @@ var (
 	ErrSystemRoleCannotBeDeleted = errors.New("system role cannot be deleted")
 	ErrUserRoleNotFound          = errors.New("user role not found")
 	ErrUserRoleAlreadyExists     = errors.New("user already has this role")
+	ErrRoleParentNotFound        = errors.New("parent role not found")
+	ErrRoleHierarchyCycle        = errors.New("role cannot inherit from itself or its descendants")
+	ErrUserRoleWindowInvalid     = errors.New("role assignment must expire in the future and after it starts")
 )
 
 type BitPermissionManager struct {
@@ -28,11 +32,11 @@ type BitPermissionManager struct {
 	roleRepo     *repository.RoleRepository
 	userRoleRepo *repository.UserRoleRepository
 	rolePermRepo *repository.RolePermissionRepository
-	cacheRepo    *repository.UserPermissionCacheRepository
+	cache        *PermissionCache
 }
 
-func NewBitPermissionManager(spaceRepo *repository.PermissionSpaceRepository, permRepo *repository.PermissionRepository, roleRepo *repository.RoleRepository, userRoleRepo *repository.UserRoleRepository, rolePermRepo *repository.RolePermissionRepository, cacheRepo *repository.UserPermissionCacheRepository) *BitPermissionManager {
-	return &BitPermissionManager{spaceRepo: spaceRepo, permRepo: permRepo, roleRepo: roleRepo, userRoleRepo: userRoleRepo, rolePermRepo: rolePermRepo, cacheRepo: cacheRepo}
+func NewBitPermissionManager(spaceRepo *repository.PermissionSpaceRepository, permRepo *repository.PermissionRepository, roleRepo *repository.RoleRepository, userRoleRepo *repository.UserRoleRepository, rolePermRepo *repository.RolePermissionRepository, cache *PermissionCache) *BitPermissionManager {
+	return &BitPermissionManager{spaceRepo: spaceRepo, permRepo: permRepo, roleRepo: roleRepo, userRoleRepo: userRoleRepo, rolePermRepo: rolePermRepo, cache: cache}
 }
 
 func (m *BitPermissionManager) CreateSpace(ctx context.Context, name, description string) (*model.PermissionSpace, error) {
@@ -55,6 +59,94 @@ func (m *BitPermissionManager) GetSpaceByID(ctx context.Context, id uint) (*mode
 	return space, err
 }
 
+func (m *BitPermissionManager) UpdateSpace(ctx context.Context, id uint, name, description string, isActive *bool) (*model.PermissionSpace, error) {
+	space, err := m.spaceRepo.FindByID(ctx, id)
+	if errors.Is(err, repository.ErrPermissionSpaceNotFound) {
+		return nil, ErrPermissionSpaceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if name != "" && name != space.Name {
+		if exists, _ := m.spaceRepo.Exists(ctx, name); exists {
+			return nil, ErrPermissionSpaceNameExists
+		}
+		space.Name = name
+	}
+	if description != "" {
+		space.Description = description
+	}
+	if isActive != nil {
+		space.IsActive = *isActive
+	}
+	return space, m.spaceRepo.Update(ctx, space)
+}
+
+// DeleteSpace deletes an empty space; its permissions have to be moved or deleted first
+func (m *BitPermissionManager) DeleteSpace(ctx context.Context, id uint) error {
+	if _, err := m.spaceRepo.FindByID(ctx, id); errors.Is(err, repository.ErrPermissionSpaceNotFound) {
+		return ErrPermissionSpaceNotFound
+	} else if err != nil {
+		return err
+	}
+	count, err := m.permRepo.CountBySpaceID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrPermissionSpaceNotEmpty
+	}
+	// 清掉已删除权限残留的角色授权
+	noPermissions := func([]model.Permission) ([]model.Permission, error) { return nil, nil }
+	if err := m.permRepo.Relayout(ctx, id, noPermissions); err != nil {
+		return err
+	}
+	if err := m.spaceRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return m.cache.InvalidateAll(ctx)
+}
+
+// CompactSpace renumbers the permissions of a space to positions 0..n-1 in their current
+// order, closing the holes left by deleted or moved permissions. The permissions are loaded
+// and renumbered under the space lock, so concurrent creates and moves wait for it.
+func (m *BitPermissionManager) CompactSpace(ctx context.Context, id uint) (*model.CompactSpaceResult, error) {
+	var count, moved int
+	err := m.permRepo.Relayout(ctx, id, func(perms []model.Permission) ([]model.Permission, error) {
+		count = len(perms)
+		for i := range perms {
+			if perms[i].Position != uint8(i) {
+				moved++
+			}
+			perms[i].Position = uint8(i)
+			perms[i].Value = uint64(1) << uint(i)
+		}
+		return perms, nil
+	})
+	if errors.Is(err, repository.ErrPermissionSpaceNotFound) {
+		return nil, ErrPermissionSpaceNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	// 位置变了，该空间所有用户的缓存值都已失效
+	if err := m.cache.InvalidateAll(ctx); err != nil {
+		return nil, err
+	}
+	return &model.CompactSpaceResult{SpaceID: id, PermissionCount: count, Moved: moved}, nil
+}
+
+// nextPosition returns the position after the last of a space's permissions
+func nextPosition(current []model.Permission) (int, error) {
+	next := 0
+	if len(current) > 0 {
+		next = int(current[len(current)-1].Position) + 1
+	}
+	if next >= 64 {
+		return 0, ErrPermissionSpaceFull
+	}
+	return next, nil
+}
+
 
 func (m *BitPermissionManager) CreatePermission(ctx context.Context, code, name, description string, spaceID uint, module string) (*model.Permission, error) {
 	if _, err := m.spaceRepo.FindByID(ctx, spaceID); errors.Is(err, repository.ErrPermissionSpaceNotFound) {
@@ -63,13 +155,21 @@ func (m *BitPermissionManager) CreatePermission(ctx context.Context, code, name,
 	if exists, _ := m.permRepo.Exists(ctx, code); exists {
 		return nil, ErrPermissionCodeExists
 	}
-	maxPos, _ := m.permRepo.GetMaxPositionInSpace(ctx, spaceID)
-	nextPos := maxPos + 1
-	if nextPos >= 64 {
-		return nil, ErrPermissionSpaceFull
+	p := &model.Permission{Code: code, Name: name, Description: description, SpaceID: spaceID, Module: module, IsActive: true}
+	err := m.permRepo.CreateInSpace(ctx, p, func(current []model.Permission) error {
+		nextPos, err := nextPosition(current)
+		if err != nil {
+			return err
+		}
+		p.Position, p.Value = uint8(nextPos), uint64(1)<<uint(nextPos)
+		return nil
+	})
+	if errors.Is(err, repository.ErrPermissionSpaceNotFound) {
+		return nil, ErrPermissionSpaceNotFound
+	} else if err != nil {
+		return nil, err
 	}
-	p := &model.Permission{Code: code, Name: name, Description: description, SpaceID: spaceID, Position: uint8(nextPos), Value: uint64(1) << uint(nextPos), Module: module, IsActive: true}
-	return p, m.permRepo.Create(ctx, p)
+	return p, nil
 }
 
 func (m *BitPermissionManager) UpdatePermission(ctx context.Context, id uint, name, description string, isActive *bool) (*model.Permission, error) {
@@ -93,11 +193,46 @@ func (m *BitPermissionManager) UpdatePermission(ctx context.Context, id uint, na
 }
 
 func (m *BitPermissionManager) DeletePermission(ctx context.Context, id uint) error {
-	err := m.permRepo.SoftDelete(ctx, id)
-	if errors.Is(err, repository.ErrPermissionNotFound) {
+	if _, err := m.permRepo.FindByID(ctx, id); errors.Is(err, repository.ErrPermissionNotFound) {
 		return ErrPermissionNotFound
+	} else if err != nil {
+		return err
 	}
-	return err
+	if err := m.permRepo.SoftDelete(ctx, id); err != nil {
+		return err
+	}
+	// 收回所有角色的授权，否则该位置以后分配给新权限时会被直接继承
+	if err := m.rolePermRepo.DeleteByPermissionID(ctx, id); err != nil {
+		return err
+	}
+	return m.cache.InvalidateAll(ctx)
+}
+
+// MovePermission moves a permission to the next free position of another space, keeping
+// every role grant of it. The permission is re-read under the space locks, so a concurrent
+// move or compaction cannot be undone by a stale copy.
+func (m *BitPermissionManager) MovePermission(ctx context.Context, id, spaceID uint) (*model.Permission, error) {
+	moved := false
+	p, err := m.permRepo.MoveToSpace(ctx, id, spaceID, func(p *model.Permission, current []model.Permission) error {
+		nextPos, err := nextPosition(current)
+		if err != nil {
+			return err
+		}
+		p.Position, p.Value = uint8(nextPos), uint64(1)<<uint(nextPos)
+		moved = true
+		return nil
+	})
+	if errors.Is(err, repository.ErrPermissionNotFound) {
+		return nil, ErrPermissionNotFound
+	} else if errors.Is(err, repository.ErrPermissionSpaceNotFound) {
+		return nil, ErrPermissionSpaceNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if !moved {
+		return p, nil
+	}
+	return p, m.cache.InvalidateAll(ctx)
 }
 
 
@@ -133,11 +268,22 @@ func (m *BitPermissionManager) GetPermissionByID(ctx context.Context, id uint) (
 }
 
 
-func (m *BitPermissionManager) CreateRoleWithPermissions(ctx context.Context, name, description string, codes []string) (*model.Role, error) {
+func (m *BitPermissionManager) CreateRoleWithPermissions(ctx context.Context, name, description string, parentID *uint, codes []string) (*model.Role, error) {
 	if exists, _ := m.roleRepo.Exists(ctx, name); exists {
 		return nil, ErrRoleNameExists
 	}
-	role := &model.Role{Name: name, Description: description, IsActive: true, IsSystem: false}
+	if parentID != nil && *parentID == 0 {
+		parentID = nil
+	}
+	if parentID != nil {
+		// 新角色还没有子角色，不会成环，只需确认父角色存在
+		if _, err := m.roleRepo.FindByID(ctx, *parentID); errors.Is(err, repository.ErrRoleNotFound) {
+			return nil, ErrRoleParentNotFound
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	role := &model.Role{Name: name, Description: description, IsActive: true, IsSystem: false, ParentID: parentID}
 	if err := m.roleRepo.Create(ctx, role); err != nil {
 		return nil, err
 	}
@@ -149,7 +295,7 @@ func (m *BitPermissionManager) CreateRoleWithPermissions(ctx context.Context, na
 	return role, nil
 }
 
-func (m *BitPermissionManager) UpdateRole(ctx context.Context, id uint, name, description string, isActive *bool) (*model.Role, error) {
+func (m *BitPermissionManager) UpdateRole(ctx context.Context, id uint, name, description string, isActive *bool, parentID *uint) (*model.Role, error) {
 	role, err := m.roleRepo.FindByID(ctx, id)
 	if errors.Is(err, repository.ErrRoleNotFound) {
 		return nil, ErrRoleNotFound
@@ -169,7 +315,45 @@ func (m *BitPermissionManager) UpdateRole(ctx context.Context, id uint, name, de
 	if isActive != nil {
 		role.IsActive = *isActive
 	}
-	return role, m.roleRepo.Update(ctx, role)
+	parentChanged := false
+	if parentID != nil {
+		if *parentID == 0 {
+			parentChanged = role.ParentID != nil
+			role.ParentID = nil
+		} else if role.ParentID == nil || *role.ParentID != *parentID {
+			if err := m.checkParent(ctx, id, *parentID); err != nil {
+				return nil, err
+			}
+			pid := *parentID
+			role.ParentID = &pid
+			parentChanged = true
+		}
+	}
+	if err := m.roleRepo.Update(ctx, role); err != nil {
+		return nil, err
+	}
+	if parentChanged {
+		// 继承关系变了，该角色及其所有子孙角色的用户权限都要重算
+		return role, m.clearCacheForRole(ctx, id)
+	}
+	return role, nil
+}
+
+// checkParent verifies that parentID exists and is not roleID or one of its descendants
+func (m *BitPermissionManager) checkParent(ctx context.Context, roleID, parentID uint) error {
+	if _, err := m.roleRepo.FindByID(ctx, parentID); errors.Is(err, repository.ErrRoleNotFound) {
+		return ErrRoleParentNotFound
+	} else if err != nil {
+		return err
+	}
+	g, err := loadRoleGraph(ctx, m.roleRepo)
+	if err != nil {
+		return err
+	}
+	if g.createsCycle(roleID, parentID) {
+		return ErrRoleHierarchyCycle
+	}
+	return nil
 }
 
 func (m *BitPermissionManager) DeleteRole(ctx context.Context, id uint) error {
@@ -183,11 +367,16 @@ func (m *BitPermissionManager) DeleteRole(ctx context.Context, id uint) error {
 	if role.IsSystem {
 		return ErrSystemRoleCannotBeDeleted
 	}
-	if uids, _ := m.userRoleRepo.GetUserIDsByRoleID(ctx, id); len(uids) > 0 {
-		m.cacheRepo.DeleteByUserIDs(ctx, uids)
+	// 子孙角色失去从该角色继承的权限，先记下受影响的用户
+	uids, _ := roleTreeUserIDs(ctx, m.roleRepo, m.userRoleRepo, id)
+	if err := m.roleRepo.DetachChildren(ctx, id); err != nil {
+		return err
 	}
 	m.rolePermRepo.DeleteByRoleID(ctx, id)
-	return m.roleRepo.Delete(ctx, id)
+	if err := m.roleRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return m.cache.InvalidateByRole(ctx, id, uids)
 }
 
 func (m *BitPermissionManager) GetAllRoles(ctx context.Context) ([]model.Role, error) {
@@ -211,7 +400,37 @@ func (m *BitPermissionManager) GetRoleByID(ctx context.Context, id uint) (*model
 			perms = append(perms, model.PermissionDetail{ID: rp.Permission.ID, Code: rp.Permission.Code, Name: rp.Permission.Name, SpaceID: rp.Permission.SpaceID, Position: rp.Permission.Position, Value: rp.Permission.Value, Module: rp.Permission.Module, IsActive: rp.Permission.IsActive})
 		}
 	}
-	return &model.RoleDetail{ID: role.ID, Name: role.Name, Description: role.Description, IsActive: role.IsActive, IsSystem: role.IsSystem, PermissionCodes: codes, Permissions: perms}, nil
+	inherited, err := m.inheritedPermissionCodes(ctx, role.ID, codes)
+	if err != nil {
+		return nil, err
+	}
+	return &model.RoleDetail{ID: role.ID, Name: role.Name, Description: role.Description, IsActive: role.IsActive, IsSystem: role.IsSystem, IsDefault: role.IsDefault, ParentID: role.ParentID, PermissionCodes: codes, InheritedCodes: inherited, Permissions: perms}, nil
+}
+
+// inheritedPermissionCodes returns the codes a role gets from its ancestors and does not hold itself
+func (m *BitPermissionManager) inheritedPermissionCodes(ctx context.Context, roleID uint, own []string) ([]string, error) {
+	g, err := loadRoleGraph(ctx, m.roleRepo)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(own))
+	for _, c := range own {
+		seen[c] = true
+	}
+	codes := make([]string, 0)
+	for _, ancestorID := range g.withAncestors([]uint{roleID})[1:] {
+		rps, err := m.rolePermRepo.FindByRoleID(ctx, ancestorID)
+		if err != nil {
+			return nil, err
+		}
+		for _, rp := range rps {
+			if rp.Permission != nil && !seen[rp.Permission.Code] {
+				seen[rp.Permission.Code] = true
+				codes = append(codes, rp.Permission.Code)
+			}
+		}
+	}
+	return codes, nil
 }
 
 func (m *BitPermissionManager) AddPermissionToRole(ctx context.Context, roleID uint, code string) error {
@@ -280,25 +499,45 @@ func (m *BitPermissionManager) GetRolePermissions(ctx context.Context, roleID ui
 	return codes, nil
 }
 
+// clearCacheForRole clears the cache of every user of the role and of the roles inheriting from it
 func (m *BitPermissionManager) clearCacheForRole(ctx context.Context, roleID uint) error {
-	if uids, _ := m.userRoleRepo.GetUserIDsByRoleID(ctx, roleID); len(uids) > 0 {
-		return m.cacheRepo.DeleteByUserIDs(ctx, uids)
-	}
-	return nil
+	uids, _ := roleTreeUserIDs(ctx, m.roleRepo, m.userRoleRepo, roleID)
+	return m.cache.InvalidateByRole(ctx, roleID, uids)
 }
 
 
-func (m *BitPermissionManager) AssignRoleToUser(ctx context.Context, userID, roleID uint) error {
+// AssignRoleToUser assigns a role, optionally only from startsAt and/or until expiresAt.
+// An assignment that has expired but not been swept yet is renewed with the new window.
+func (m *BitPermissionManager) AssignRoleToUser(ctx context.Context, userID, roleID uint, startsAt, expiresAt *time.Time) error {
+	now := time.Now()
+	if expiresAt != nil && (!expiresAt.After(now) || (startsAt != nil && !expiresAt.After(*startsAt))) {
+		return ErrUserRoleWindowInvalid
+	}
 	if _, err := m.roleRepo.FindByID(ctx, roleID); errors.Is(err, repository.ErrRoleNotFound) {
 		return ErrRoleNotFound
 	}
-	if exists, _ := m.userRoleRepo.Exists(ctx, userID, roleID); exists {
-		return ErrUserRoleAlreadyExists
+	urs, err := m.userRoleRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for i := range urs {
+		ur := &urs[i]
+		if ur.RoleID != roleID {
+			continue
+		}
+		if !ur.EndedAt(now) {
+			return ErrUserRoleAlreadyExists
+		}
+		ur.StartsAt, ur.ExpiresAt = startsAt, expiresAt
+		if err := m.userRoleRepo.Update(ctx, ur); err != nil {
+			return err
+		}
+		return m.cache.InvalidateUser(ctx, userID)
 	}
-	if err := m.userRoleRepo.Create(ctx, &model.UserRole{UserID: userID, RoleID: roleID}); err != nil {
+	if err := m.userRoleRepo.Create(ctx, &model.UserRole{UserID: userID, RoleID: roleID, StartsAt: startsAt, ExpiresAt: expiresAt}); err != nil {
 		return err
 	}
-	return m.cacheRepo.DeleteByUserID(ctx, userID)
+	return m.cache.InvalidateUser(ctx, userID)
 }
 
 func (m *BitPermissionManager) RemoveRoleFromUser(ctx context.Context, userID, roleID uint) error {
@@ -307,17 +546,19 @@ func (m *BitPermissionManager) RemoveRoleFromUser(ctx context.Context, userID, r
 	} else if err != nil {
 		return err
 	}
-	return m.cacheRepo.DeleteByUserID(ctx, userID)
+	return m.cache.InvalidateUser(ctx, userID)
 }
 
+// GetUserRoles returns the roles assigned to a user that are in effect now
 func (m *BitPermissionManager) GetUserRoles(ctx context.Context, userID uint) ([]model.Role, error) {
 	urs, err := m.userRoleRepo.FindByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	now := time.Now()
 	roles := make([]model.Role, 0)
 	for _, ur := range urs {
-		if ur.Role != nil {
+		if ur.Role != nil && ur.ActiveAt(now) {
 			roles = append(roles, *ur.Role)
 		}
 	}
@@ -333,51 +574,59 @@ func (m *BitPermissionManager) HasPermission(ctx context.Context, userID uint, c
 	if err != nil {
 		return false, err
 	}
-	cache, err := m.cacheRepo.FindByUserAndSpace(ctx, userID, p.SpaceID)
+	cached, err := m.cache.Get(ctx, userID, p.SpaceID)
 	if err != nil {
 		return false, err
 	}
-	if cache == nil {
-		if err := m.CalculateUserPermissions(ctx, userID); err != nil {
-			return false, err
-		}
-		cache, _ = m.cacheRepo.FindByUserAndSpace(ctx, userID, p.SpaceID)
-		if cache == nil {
-			return false, nil
-		}
+	if cached != nil {
+		return (*cached & p.Value) == p.Value, nil
 	}
-	return (cache.Value & p.Value) == p.Value, nil
+	load := m.cache.beginLoad(ctx, userID)
+	sv, changesAt, err := m.spaceValues(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if err := m.cache.set(ctx, userID, withSpace(sv, p.SpaceID), load, changesAt); err != nil {
+		return false, err
+	}
+	return (sv[p.SpaceID] & p.Value) == p.Value, nil
 }
 
 func (m *BitPermissionManager) CalculateUserPermissions(ctx context.Context, userID uint) error {
-	m.cacheRepo.DeleteByUserID(ctx, userID)
-	urs, err := m.userRoleRepo.FindByUserID(ctx, userID)
+	load := m.cache.beginLoad(ctx, userID)
+	sv, changesAt, err := m.spaceValues(ctx, userID)
 	if err != nil {
 		return err
 	}
+	return m.cache.set(ctx, userID, sv, load, changesAt)
+}
+
+// spaceValues ORs the grants of the user's effective roles into one value per space. The
+// values hold until changesAt, when a role assignment starts or ends (zero: no such time).
+func (m *BitPermissionManager) spaceValues(ctx context.Context, userID uint) (map[uint]uint64, time.Time, error) {
+	roleIDs, changesAt, err := effectiveRoleIDs(ctx, m.userRoleRepo, m.roleRepo, userID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
 	sv := make(map[uint]uint64)
-	for _, ur := range urs {
-		rps, _ := m.rolePermRepo.FindByRoleID(ctx, ur.RoleID)
+	for _, roleID := range roleIDs {
+		rps, _ := m.rolePermRepo.FindByRoleID(ctx, roleID)
 		for _, rp := range rps {
 			sv[rp.SpaceID] |= rp.Value
 		}
 	}
-	now := time.Now()
-	for sid, v := range sv {
-		m.cacheRepo.Upsert(ctx, &model.UserPermissionCache{UserID: userID, SpaceID: sid, Value: v, CreatedAt: now, UpdatedAt: now})
-	}
-	return nil
+	return sv, changesAt, nil
 }
 
 
 func (m *BitPermissionManager) GetUserPermissions(ctx context.Context, userID uint) ([]string, error) {
-	urs, err := m.userRoleRepo.FindByUserID(ctx, userID)
+	roleIDs, _, err := effectiveRoleIDs(ctx, m.userRoleRepo, m.roleRepo, userID)
 	if err != nil {
 		return nil, err
 	}
 	cs := make(map[string]struct{})
-	for _, ur := range urs {
-		rps, _ := m.rolePermRepo.FindByRoleID(ctx, ur.RoleID)
+	for _, roleID := range roleIDs {
+		rps, _ := m.rolePermRepo.FindByRoleID(ctx, roleID)
 		for _, rp := range rps {
 			if rp.Permission != nil {
 				cs[rp.Permission.Code] = struct{}{}
@@ -392,5 +641,5 @@ func (m *BitPermissionManager) GetUserPermissions(ctx context.Context, userID ui
 }
 
 func (m *BitPermissionManager) ClearUserPermissionCache(ctx context.Context, userID uint) error {
-	return m.cacheRepo.DeleteByUserID(ctx, userID)
+	return m.cache.InvalidateUser(ctx, userID)
 }
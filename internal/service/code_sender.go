@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go-api-starter/internal/model"
+	"go-api-starter/pkg/logger"
+	"go-api-starter/pkg/notify"
+)
+
+// CodeSender delivers verification codes and security notices to an account (email or mobile)
+type CodeSender interface {
+	SendCode(ctx context.Context, account, code, purpose string) error
+	SendNotice(ctx context.Context, account, message string) error
+}
+
+// noticeSubject is the email subject of security notices
+const noticeSubject = "账号安全提醒"
+
+// codePurposeLabels maps a code purpose to the text shown to the user
+var codePurposeLabels = map[string]string{
+	model.CodePurposeLogin:         "登录",
+	model.CodePurposeRegister:      "注册",
+	model.CodePurposeResetPassword: "重置密码",
+	model.CodePurposeChangeContact: "更换绑定",
+}
+
+func codeMessage(code, purpose string) string {
+	label := codePurposeLabels[purpose]
+	if label == "" {
+		label = "身份验证"
+	}
+	return fmt.Sprintf("您的%s验证码为 %s，请勿泄露给他人。", label, code)
+}
+
+// EmailCodeSender sends codes by email
+type EmailCodeSender struct {
+	email notify.EmailSender
+}
+
+// NewEmailCodeSender creates a new EmailCodeSender
+func NewEmailCodeSender(email notify.EmailSender) *EmailCodeSender {
+	return &EmailCodeSender{email: email}
+}
+
+// SendCode sends the code to an email address
+func (s *EmailCodeSender) SendCode(ctx context.Context, account, code, purpose string) error {
+	return s.email.SendEmail(ctx, account, "验证码", codeMessage(code, purpose))
+}
+
+// SendNotice emails a security notice
+func (s *EmailCodeSender) SendNotice(ctx context.Context, account, message string) error {
+	return s.email.SendEmail(ctx, account, noticeSubject, message)
+}
+
+// SMSCodeSender sends codes by SMS
+type SMSCodeSender struct {
+	sms notify.SMSSender
+}
+
+// NewSMSCodeSender creates a new SMSCodeSender
+func NewSMSCodeSender(sms notify.SMSSender) *SMSCodeSender {
+	return &SMSCodeSender{sms: sms}
+}
+
+// SendCode sends the code to a mobile number
+func (s *SMSCodeSender) SendCode(ctx context.Context, account, code, purpose string) error {
+	return s.sms.SendSMS(ctx, account, codeMessage(code, purpose))
+}
+
+// SendNotice texts a security notice
+func (s *SMSCodeSender) SendNotice(ctx context.Context, account, message string) error {
+	return s.sms.SendSMS(ctx, account, message)
+}
+
+// ConsoleCodeSender writes codes to the application log (development only)
+type ConsoleCodeSender struct{}
+
+// NewConsoleCodeSender creates a new ConsoleCodeSender
+func NewConsoleCodeSender() *ConsoleCodeSender {
+	return &ConsoleCodeSender{}
+}
+
+// SendCode logs the code instead of delivering it
+func (s *ConsoleCodeSender) SendCode(ctx context.Context, account, code, purpose string) error {
+	if logger.Log != nil {
+		logger.Log.Infof("[verify-code] purpose=%s account=%s code=%s", purpose, account, code)
+	}
+	return nil
+}
+
+// SendNotice logs the notice instead of delivering it
+func (s *ConsoleCodeSender) SendNotice(ctx context.Context, account, message string) error {
+	if logger.Log != nil {
+		logger.Log.Infof("[notice] account=%s message=%s", account, message)
+	}
+	return nil
+}
+
+// ConsoleEmailSender writes whole emails to the application log (development only)
+type ConsoleEmailSender struct{}
+
+// NewConsoleEmailSender creates a new ConsoleEmailSender
+func NewConsoleEmailSender() *ConsoleEmailSender {
+	return &ConsoleEmailSender{}
+}
+
+// SendEmail logs the email instead of delivering it
+func (s *ConsoleEmailSender) SendEmail(ctx context.Context, to, subject, body string) error {
+	if logger.Log != nil {
+		logger.Log.Infof("[email] to=%s subject=%s\n%s", to, subject, body)
+	}
+	return nil
+}
+
+// AccountCodeSender routes codes to the email or SMS sender based on the account format
+type AccountCodeSender struct {
+	email CodeSender
+	sms   CodeSender
+}
+
+// NewAccountCodeSender creates a sender that picks email or SMS per account
+func NewAccountCodeSender(email, sms CodeSender) *AccountCodeSender {
+	return &AccountCodeSender{email: email, sms: sms}
+}
+
+// SendCode dispatches the code to the matching channel
+func (s *AccountCodeSender) SendCode(ctx context.Context, account, code, purpose string) error {
+	if strings.Contains(account, "@") {
+		return s.email.SendCode(ctx, account, code, purpose)
+	}
+	return s.sms.SendCode(ctx, account, code, purpose)
+}
+
+// SendNotice dispatches the notice to the matching channel
+func (s *AccountCodeSender) SendNotice(ctx context.Context, account, message string) error {
+	if strings.Contains(account, "@") {
+		return s.email.SendNotice(ctx, account, message)
+	}
+	return s.sms.SendNotice(ctx, account, message)
+}
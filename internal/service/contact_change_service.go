@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-api-starter/internal/config"
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/repository"
+	"go-api-starter/pkg/apperrors"
+	"go-api-starter/pkg/cache"
+	"go-api-starter/pkg/i18n"
+	"go-api-starter/pkg/logger"
+)
+
+// contactChangePrefix keys the pending change of one field of one user
+const contactChangePrefix = "contact:change:"
+
+// contactFieldLabels names a contact field in notices
+var contactFieldLabels = map[string]string{
+	model.ContactFieldEmail:  "邮箱",
+	model.ContactFieldMobile: "手机号",
+}
+
+// ContactChangeService changes a user's email or mobile only after the new address has been
+// verified with a one-time code, and notifies the old address once the change is made
+type ContactChangeService struct {
+	cache       cache.CacheBackend
+	codeService *VerificationCodeService
+	sender      CodeSender
+	userRepo    repository.UserRepositoryInterface
+	audit       *AuditService
+	ttl         time.Duration
+}
+
+// NewContactChangeService creates a new ContactChangeService
+func NewContactChangeService(
+	cacheBackend cache.CacheBackend,
+	codeService *VerificationCodeService,
+	sender CodeSender,
+	userRepo repository.UserRepositoryInterface,
+	audit *AuditService,
+	cfg config.VerifyConfig,
+) *ContactChangeService {
+	return &ContactChangeService{
+		cache:       cacheBackend,
+		codeService: codeService,
+		sender:      sender,
+		userRepo:    userRepo,
+		audit:       audit,
+		ttl:         cfg.CodeTTL,
+	}
+}
+
+func (s *ContactChangeService) pendingKey(userID uint, field string) string {
+	return contactChangePrefix + strconv.FormatUint(uint64(userID), 10) + ":" + field
+}
+
+// Request records the new address as a pending change and sends a code to it.
+// A new request for the same field replaces the previous one.
+func (s *ContactChangeService) Request(ctx context.Context, userID uint, req *model.ContactChangeRequest, clientIP string) (*model.ContactChangeResponse, error) {
+	value := normalizeAccount(req.Value)
+	if isEmail := strings.Contains(value, "@"); isEmail != (req.Field == model.ContactFieldEmail) || (!isEmail && !isValidMobile(value)) {
+		return nil, apperrors.BadRequestCode(i18n.ErrProvideMobileOrEmail)
+	}
+
+	// Send applies the rate limits; an address already in use gets no code, and Confirm
+	// checks availability again in any case
+	sent, err := s.codeService.Send(ctx, &model.SendCodeRequest{Account: value, Purpose: model.CodePurposeChangeContact}, clientIP)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.Set(ctx, s.pendingKey(userID, req.Field), []byte(value), s.ttl); err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrCodeStoreFailed)
+	}
+
+	return &model.ContactChangeResponse{
+		Field:      req.Field,
+		Value:      value,
+		ExpiresIn:  sent.ExpiresIn,
+		RetryAfter: sent.RetryAfter,
+	}, nil
+}
+
+// Confirm applies the pending change once the code sent to the new address is verified
+func (s *ContactChangeService) Confirm(ctx context.Context, userID uint, req *model.ConfirmContactChangeRequest, clientIP string) (*model.User, error) {
+	key := s.pendingKey(userID, req.Field)
+	data, err := s.cache.Get(ctx, key)
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return nil, apperrors.BadRequestCode(i18n.ErrContactChangeNotFound)
+	}
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrCodeStoreFailed)
+	}
+	value := string(data)
+
+	if err := s.codeService.Verify(ctx, model.CodePurposeChangeContact, value, req.Code); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, apperrors.NotFoundCode(i18n.ErrUserNotFound)
+		}
+		return nil, apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+
+	// The address may have been registered since the code was sent
+	if err := s.checkAvailable(ctx, user.ID, req.Field, value); err != nil {
+		return nil, err
+	}
+	oldValue := contactValue(user, req.Field)
+	if req.Field == model.ContactFieldEmail {
+		user.Email = &value
+	} else {
+		user.Mobile = &value
+	}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, apperrors.Wrap(err, "failed to update user")
+	}
+	_ = s.cache.Delete(ctx, key)
+
+	s.announce(ctx, user.ID, user, req.Field, oldValue, clientIP, false)
+	return user, nil
+}
+
+// checkAvailable rejects an address that belongs to another account
+func (s *ContactChangeService) checkAvailable(ctx context.Context, userID uint, field, value string) error {
+	var existing *model.User
+	var err error
+	if field == model.ContactFieldEmail {
+		existing, err = s.userRepo.FindByEmail(ctx, value)
+	} else {
+		existing, err = s.userRepo.FindByMobile(ctx, value)
+	}
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+	if existing.ID == userID {
+		return nil
+	}
+	if field == model.ContactFieldEmail {
+		return apperrors.ConflictCode(i18n.ErrEmailTaken)
+	}
+	return apperrors.ConflictCode(i18n.ErrMobileTaken)
+}
+
+// announce tells the old address about a change that has been made and records it in the audit log.
+// forced marks an administrator changing the address without verification.
+func (s *ContactChangeService) announce(ctx context.Context, actorID uint, user *model.User, field, oldValue, clientIP string, forced bool) {
+	newValue := contactValue(user, field)
+	if oldValue != "" {
+		message := fmt.Sprintf("您账号的%s已于 %s 更换为 %s，此后不能再用本%s登录。如非本人操作，请立即联系客服。",
+			contactFieldLabels[field], time.Now().Format("2006-01-02 15:04"), maskContact(newValue), contactFieldLabels[field])
+		if err := s.sender.SendNotice(ctx, oldValue, message); err != nil && logger.Log != nil {
+			logger.Log.Warnf("failed to notify old %s of user %d: %v", field, user.ID, err)
+		}
+	}
+
+	s.audit.Record(ctx, &model.AuditLog{
+		Action:  model.AuditActionContactChanged,
+		ActorID: &actorID,
+		UserID:  &user.ID,
+		IP:      clientIP,
+		Metadata: map[string]any{
+			"field":  field,
+			"old":    oldValue,
+			"new":    newValue,
+			"forced": forced,
+		},
+	})
+}
+
+// contactValue returns the user's current email or mobile, or "" when unset
+func contactValue(user *model.User, field string) string {
+	v := user.Mobile
+	if field == model.ContactFieldEmail {
+		v = user.Email
+	}
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+// maskContact hides the middle of an address shown to its previous owner
+func maskContact(value string) string {
+	if value == "" {
+		return "（空）"
+	}
+	if at := strings.Index(value, "@"); at >= 0 {
+		name := value[:at]
+		if len(name) > 2 {
+			name = name[:2]
+		}
+		return name + "***" + value[at:]
+	}
+	if len(value) > 7 {
+		return value[:3] + "****" + value[len(value)-4:]
+	}
+	return "****"
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/repository"
+	"go-api-starter/pkg/apperrors"
+)
+
+// fakeUserRepo keeps users in memory. Methods the tests do not need panic through the
+// embedded nil interface.
+type fakeUserRepo struct {
+	repository.UserRepositoryInterface
+
+	mu    sync.Mutex
+	users map[uint]*model.User
+}
+
+func newFakeUserRepo(users ...*model.User) *fakeUserRepo {
+	r := &fakeUserRepo{users: make(map[uint]*model.User)}
+	for _, u := range users {
+		r.users[u.ID] = u
+	}
+	return r
+}
+
+func (r *fakeUserRepo) find(match func(*model.User) bool) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if match(u) {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *fakeUserRepo) FindByID(ctx context.Context, id uint) (*model.User, error) {
+	return r.find(func(u *model.User) bool { return u.ID == id })
+}
+
+func (r *fakeUserRepo) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	return r.find(func(u *model.User) bool { return u.Email != nil && strings.EqualFold(*u.Email, email) })
+}
+
+func (r *fakeUserRepo) FindByMobile(ctx context.Context, mobile string) (*model.User, error) {
+	return r.find(func(u *model.User) bool { return u.Mobile != nil && *u.Mobile == mobile })
+}
+
+func (r *fakeUserRepo) Update(ctx context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+// fakeAuditRepo keeps audit entries in memory
+type fakeAuditRepo struct {
+	repository.AuditLogRepositoryInterface
+
+	mu      sync.Mutex
+	entries []model.AuditLog
+}
+
+func (r *fakeAuditRepo) Create(ctx context.Context, log *model.AuditLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, *log)
+	return nil
+}
+
+func (r *fakeAuditRepo) actions() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	actions := make([]string, 0, len(r.entries))
+	for _, e := range r.entries {
+		actions = append(actions, e.Action)
+	}
+	return actions
+}
+
+// fakeCodeSender records the codes it was asked to send
+type fakeCodeSender struct {
+	mu    sync.Mutex
+	codes map[string]string // account → last code
+}
+
+func newFakeCodeSender() *fakeCodeSender {
+	return &fakeCodeSender{codes: make(map[string]string)}
+}
+
+func (s *fakeCodeSender) SendCode(ctx context.Context, account, code, purpose string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.codes[account] = code
+	return nil
+}
+
+func (s *fakeCodeSender) SendNotice(ctx context.Context, account, message string) error {
+	return nil
+}
+
+func (s *fakeCodeSender) code(account string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code, ok := s.codes[account]
+	return code, ok
+}
+
+// errorCode returns the code of an AppError, or "" for any other error
+func errorCode(err error) string {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return ""
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -10,17 +10,61 @@ import (
 
 // AuthServiceInterface defines the interface for authentication service operations
 type AuthServiceInterface interface {
-	Register(ctx context.Context, req *model.RegisterRequest) (*model.LoginResponse, error)
-	Login(ctx context.Context, req *model.LoginRequest) (*model.LoginResponse, error)
-	RefreshToken(ctx context.Context, refreshToken string) (string, error)
+	SendVerificationCode(ctx context.Context, req *model.SendCodeRequest, clientIP string) (*model.SendCodeResponse, error)
+	Register(ctx context.Context, req *model.RegisterRequest, client model.ClientInfo) (*model.LoginResponse, error)
+	Login(ctx context.Context, req *model.LoginRequest, client model.ClientInfo) (*model.LoginResponse, error)
+	VerifyMFA(ctx context.Context, req *model.MFAVerifyRequest, client model.ClientInfo) (*model.LoginResponse, error)
+	OIDCProviders() []model.OIDCProviderResponse
+	OIDCAuthorize(ctx context.Context, provider string) (*model.OIDCAuthorizeResponse, error)
+	OIDCLogin(ctx context.Context, provider string, req *model.OIDCCallbackRequest, client model.ClientInfo) (*model.LoginResponse, error)
+	BeginPasskeyLogin(ctx context.Context, req *model.PasskeyLoginBeginRequest) (*model.PasskeyRequestResponse, error)
+	PasskeyLogin(ctx context.Context, req *model.PasskeyLoginRequest, client model.ClientInfo) (*model.LoginResponse, error)
+	RequestMagicLink(ctx context.Context, req *model.MagicLinkRequest) (*model.MagicLinkResponse, string, error)
+	MagicLinkLogin(ctx context.Context, req *model.MagicLinkVerifyRequest, nonce string, client model.ClientInfo) (*model.LoginResponse, error)
+	CreateQRLogin(ctx context.Context, client model.ClientInfo) (*model.QRCreateResponse, error)
+	PollQRLogin(ctx context.Context, req *model.QRPollRequest) (*model.QRPollResponse, error)
+	ScanQRLogin(ctx context.Context, userID uint, key string) (*model.QRScanResponse, error)
+	ConfirmQRLogin(ctx context.Context, userID uint, key string) error
+	CancelQRLogin(ctx context.Context, userID uint, key string) error
+	EnrollTOTP(ctx context.Context, userID uint) (*model.MFAEnrollResponse, error)
+	ActivateTOTP(ctx context.Context, userID uint, code string) error
+	DisableTOTP(ctx context.Context, userID uint, code string) error
+	BeginPasskeyRegistration(ctx context.Context, userID uint) (*model.PasskeyCreationResponse, error)
+	FinishPasskeyRegistration(ctx context.Context, userID uint, req *model.PasskeyRegisterRequest) (*model.PasskeyResponse, error)
+	ListPasskeys(ctx context.Context, userID uint) ([]*model.PasskeyResponse, error)
+	DeletePasskey(ctx context.Context, userID uint, id string) error
+	RefreshToken(ctx context.Context, refreshToken string) (*model.RefreshTokenResponse, error)
 	AccessTokenExpiresIn() int64
 	GetCurrentUser(ctx context.Context, userID uint) (*model.User, error)
-	ResetPassword(ctx context.Context, userID uint, req *model.ResetPasswordRequest) error
+	ResetPassword(ctx context.Context, actorID, userID uint, req *model.ResetPasswordRequest) error
+	SelfResetPassword(ctx context.Context, req *model.SelfResetPasswordRequest) error
 	Logout(ctx context.Context, token string) error
 	LogoutAllDevices(ctx context.Context, userID uint) error
+	ListSessions(ctx context.Context, userID uint, currentSessionID string) ([]*model.SessionResponse, error)
+	RevokeSession(ctx context.Context, userID uint, sessionID string) error
 	IsTokenBlacklisted(ctx context.Context, token string) (bool, error)
 }
 
+// APIKeyServiceInterface defines the interface for API key operations
+type APIKeyServiceInterface interface {
+	Create(ctx context.Context, userID uint, req *model.CreateAPIKeyRequest) (*model.CreateAPIKeyResponse, error)
+	List(ctx context.Context, userID uint) ([]*model.APIKeyResponse, error)
+	Revoke(ctx context.Context, userID uint, id string) error
+	Authenticate(ctx context.Context, plaintext string) (*model.APIKey, error)
+}
+
+// OAuthServiceInterface defines the interface for OAuth clients, their tokens, introspection and revocation
+type OAuthServiceInterface interface {
+	CreateClient(ctx context.Context, actorID uint, req *model.CreateOAuthClientRequest) (*model.CreateOAuthClientResponse, error)
+	ListClients(ctx context.Context) ([]*model.OAuthClientResponse, error)
+	RevokeClient(ctx context.Context, clientID string) error
+	AuthenticateClient(ctx context.Context, clientID, secret string) (*model.OAuthClient, error)
+	ActiveClient(ctx context.Context, clientID string) (*model.OAuthClient, error)
+	IssueClientToken(ctx context.Context, client *model.OAuthClient, scope string) (*model.ClientTokenResponse, error)
+	Introspect(ctx context.Context, token string) (*model.IntrospectionResponse, error)
+	Revoke(ctx context.Context, token string) error
+}
+
 // UserServiceInterface defines the interface for user service operations
 type UserServiceInterface interface {
 	Create(ctx context.Context, req *model.CreateUserRequest) (*model.User, error)
@@ -28,7 +72,32 @@ type UserServiceInterface interface {
 	GetBySecUID(ctx context.Context, secUID string) (*model.User, error)
 	List(ctx context.Context, offset, limit int, sort string) ([]model.User, int64, error)
 	Update(ctx context.Context, id uint, req *model.UpdateUserRequest) (*model.User, error)
+	ForceUpdate(ctx context.Context, actorID, id uint, req *model.UpdateUserRequest, ip string) (*model.User, error)
 	Delete(ctx context.Context, id uint) error
+	Unlock(ctx context.Context, actorID uint, secUID, ip string) error
+	UnlockIP(ctx context.Context, actorID uint, lockedIP, ip string) error
+}
+
+// ContactChangeServiceInterface defines the interface for verified email / mobile changes
+type ContactChangeServiceInterface interface {
+	Request(ctx context.Context, userID uint, req *model.ContactChangeRequest, clientIP string) (*model.ContactChangeResponse, error)
+	Confirm(ctx context.Context, userID uint, req *model.ConfirmContactChangeRequest, clientIP string) (*model.User, error)
+}
+
+// ImpersonationServiceInterface defines the interface for acting as another user
+type ImpersonationServiceInterface interface {
+	Start(ctx context.Context, actorID uint, targetSecUID string, client model.ClientInfo) (*model.ImpersonationResponse, error)
+}
+
+// FreezeServiceInterface defines the interface for freezing and unfreezing accounts
+type FreezeServiceInterface interface {
+	Freeze(ctx context.Context, actorID uint, secUID string, req *model.FreezeUserRequest, ip string) (*model.UserResponse, error)
+	Unfreeze(ctx context.Context, actorID uint, secUID string, req *model.UnfreezeUserRequest, ip string) (*model.UserResponse, error)
+}
+
+// AuditServiceInterface defines the interface for reviewing audit logs
+type AuditServiceInterface interface {
+	List(ctx context.Context, filter model.AuditLogFilter, offset, limit int, sort string) ([]model.AuditLog, int64, error)
 }
 
 // PermissionServiceInterface defines the interface for permission service operations
@@ -36,6 +105,9 @@ type PermissionServiceInterface interface {
 	// Space operations
 	CreateSpace(ctx context.Context, req *model.CreateSpaceRequest) (*model.PermissionSpace, error)
 	GetAllSpaces(ctx context.Context) ([]model.SpaceWithCount, error)
+	UpdateSpace(ctx context.Context, id uint, req *model.UpdateSpaceRequest) (*model.PermissionSpace, error)
+	DeleteSpace(ctx context.Context, id uint) error
+	CompactSpace(ctx context.Context, id uint) (*model.CompactSpaceResult, error)
 
 	// Permission operations
 	CreatePermission(ctx context.Context, req *model.CreatePermissionRequest) (*model.Permission, error)
@@ -43,6 +115,7 @@ type PermissionServiceInterface interface {
 	GetPermissionByID(ctx context.Context, id uint) (*model.PermissionDetail, error)
 	UpdatePermission(ctx context.Context, id uint, req *model.UpdatePermissionRequest) (*model.Permission, error)
 	DeletePermission(ctx context.Context, id uint) error
+	MovePermission(ctx context.Context, id uint, req *model.MovePermissionRequest) (*model.Permission, error)
 
 	// Role operations
 	CreateRole(ctx context.Context, req *model.CreateRoleRequest) (*model.Role, error)
@@ -58,7 +131,7 @@ type PermissionServiceInterface interface {
 
 	// User role operations
 	GetUserRoles(ctx context.Context, userID uint) ([]model.Role, error)
-	AssignUserRole(ctx context.Context, userID, roleID uint) error
+	AssignUserRole(ctx context.Context, userID uint, req *model.AssignRoleRequest) error
 	RemoveUserRole(ctx context.Context, userID, roleID uint) error
 
 	// Permission check operations
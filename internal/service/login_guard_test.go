@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-api-starter/internal/config"
+	"go-api-starter/internal/model"
+	"go-api-starter/pkg/cache"
+	"go-api-starter/pkg/i18n"
+)
+
+func newTestLoginGuard(t *testing.T, cfg config.LoginGuardConfig) (*LoginGuard, *fakeAuditRepo) {
+	memCache := cache.NewMemoryCache()
+	t.Cleanup(func() { memCache.Close() })
+	auditRepo := &fakeAuditRepo{}
+	return NewLoginGuard(memCache, NewAuditService(auditRepo), cfg), auditRepo
+}
+
+// TestLoginGuardLocksAccount tests the account lockout, its reset on success and the admin unlock
+func TestLoginGuardLocksAccount(t *testing.T) {
+	ctx := context.Background()
+	guard, auditRepo := newTestLoginGuard(t, config.LoginGuardConfig{AccountMaxFailures: 2, IPMaxFailures: 100, BaseLockout: time.Minute})
+
+	// A success before the threshold clears the counter
+	guard.RecordFailure(ctx, "203.0.113.7", 1, "a@example.com")
+	guard.RecordSuccess(ctx, "a@example.com")
+	guard.RecordFailure(ctx, "203.0.113.7", 1, "a@example.com")
+	require.NoError(t, guard.Check(ctx, "203.0.113.7", "a@example.com"))
+
+	guard.RecordFailure(ctx, "203.0.113.7", 1, "a@example.com")
+	assert.Equal(t, i18n.ErrAccountLocked, errorCode(guard.Check(ctx, "198.51.100.1", "a@example.com")))
+	assert.NoError(t, guard.Check(ctx, "198.51.100.1", "b@example.com"))
+	assert.Equal(t, []string{model.AuditActionAccountLocked}, auditRepo.actions())
+
+	require.NoError(t, guard.Unlock(ctx, "a@example.com"))
+	assert.NoError(t, guard.Check(ctx, "198.51.100.1", "a@example.com"))
+}
+
+// TestLoginGuardLocksIP tests that an IP lock has its own error code and can be lifted
+func TestLoginGuardLocksIP(t *testing.T) {
+	ctx := context.Background()
+	guard, auditRepo := newTestLoginGuard(t, config.LoginGuardConfig{AccountMaxFailures: 100, IPMaxFailures: 2, BaseLockout: time.Minute})
+
+	guard.RecordFailure(ctx, "203.0.113.7", 0, "a@example.com")
+	guard.RecordFailure(ctx, "203.0.113.7", 0, "b@example.com")
+	assert.Equal(t, i18n.ErrIPLocked, errorCode(guard.Check(ctx, "203.0.113.7", "c@example.com")))
+	assert.NoError(t, guard.Check(ctx, "198.51.100.1", "a@example.com"))
+	assert.Equal(t, []string{model.AuditActionIPLocked}, auditRepo.actions())
+
+	// Account unlocks leave the IP lock in place
+	require.NoError(t, guard.Unlock(ctx, "c@example.com"))
+	assert.Equal(t, i18n.ErrIPLocked, errorCode(guard.Check(ctx, "203.0.113.7", "c@example.com")))
+
+	require.NoError(t, guard.UnlockIP(ctx, "203.0.113.7"))
+	assert.NoError(t, guard.Check(ctx, "203.0.113.7", "c@example.com"))
+}
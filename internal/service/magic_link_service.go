@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go-api-starter/internal/config"
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/repository"
+	"go-api-starter/pkg/apperrors"
+	"go-api-starter/pkg/cache"
+	"go-api-starter/pkg/i18n"
+	"go-api-starter/pkg/notify"
+)
+
+const (
+	magicLinkTokenPrefix    = "magic:token:"    // + token 的 SHA-256
+	magicLinkClaimPrefix    = "magic:claim:"    // + token 的 SHA-256，保证一个链接只能登录一次
+	magicLinkCooldownPrefix = "magic:cooldown:" // + email
+	magicLinkHourlyPrefix   = "magic:hourly:"   // + email
+
+	magicLinkSubject = "登录链接"
+)
+
+// MagicLinkService signs users in with a single-use link sent to their email address.
+// The link is bound to the browser that asked for it: the caller keeps a nonce in that
+// browser (a cookie) and the link only works together with it, so a forwarded or leaked
+// email cannot be used from another device.
+type MagicLinkService struct {
+	userRepo repository.UserRepositoryInterface
+	cache    cache.CacheBackend
+	email    notify.EmailSender
+	config   config.MagicLinkConfig
+}
+
+// NewMagicLinkService creates a new MagicLinkService
+func NewMagicLinkService(
+	userRepo repository.UserRepositoryInterface,
+	cacheBackend cache.CacheBackend,
+	email notify.EmailSender,
+	cfg config.MagicLinkConfig,
+) *MagicLinkService {
+	if cfg.TTL <= 0 {
+		cfg.TTL = 10 * time.Minute
+	}
+	return &MagicLinkService{userRepo: userRepo, cache: cacheBackend, email: email, config: cfg}
+}
+
+// Request emails a sign-in link and returns the nonce to keep in the requesting browser.
+// An unknown or frozen address gets the same answer but no email, so the response does
+// not reveal which addresses have accounts.
+func (s *MagicLinkService) Request(ctx context.Context, email string) (*model.MagicLinkResponse, string, error) {
+	email = normalizeAccount(email)
+	if err := s.checkSendLimits(ctx, email); err != nil {
+		return nil, "", err
+	}
+
+	resp := &model.MagicLinkResponse{
+		ExpiresIn:  int64(s.config.TTL.Seconds()),
+		RetryAfter: int64(s.config.Cooldown.Seconds()),
+	}
+	nonce := model.GenerateSecUID()
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return resp, nonce, nil
+	}
+	if err != nil {
+		return nil, "", apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+	if user.Freezed {
+		return resp, nonce, nil
+	}
+
+	token := model.GenerateSecUID()
+	link, err := s.link(token)
+	if err != nil {
+		return nil, "", apperrors.InternalCode(err, i18n.ErrMagicLinkStoreFailed)
+	}
+	data, err := json.Marshal(&model.MagicLinkToken{UserID: user.ID, NonceHash: hashToken(nonce)})
+	if err != nil {
+		return nil, "", apperrors.InternalCode(err, i18n.ErrMagicLinkStoreFailed)
+	}
+	key := magicLinkTokenPrefix + hashToken(token)
+	if err := s.cache.Set(ctx, key, data, s.config.TTL); err != nil {
+		return nil, "", apperrors.InternalCode(err, i18n.ErrMagicLinkStoreFailed)
+	}
+
+	body := fmt.Sprintf("点击以下链接登录，链接 %d 分钟内有效且只能使用一次，请在申请登录的浏览器中打开：\n\n%s\n\n如果不是您本人操作，请忽略本邮件。",
+		int(s.config.TTL.Minutes()), link)
+	if err := s.email.SendEmail(ctx, email, magicLinkSubject, body); err != nil {
+		_ = s.cache.Delete(ctx, key)
+		return nil, "", apperrors.InternalCode(err, i18n.ErrMailSendFailed)
+	}
+	return resp, nonce, nil
+}
+
+// Verify consumes a link opened in the browser holding nonce and returns its user.
+// A nonce mismatch leaves the link usable, so it can still be opened in the right browser.
+func (s *MagicLinkService) Verify(ctx context.Context, token, nonce string) (*model.User, error) {
+	hash := hashToken(token)
+	data, err := s.cache.Get(ctx, magicLinkTokenPrefix+hash)
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return nil, apperrors.BadRequestCode(i18n.ErrMagicLinkInvalid)
+	}
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrMagicLinkStoreFailed)
+	}
+	var record model.MagicLinkToken
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrMagicLinkStoreFailed)
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(record.NonceHash), []byte(hashToken(nonce))) != 1 {
+		return nil, apperrors.ForbiddenCode(i18n.ErrMagicLinkBrowserMismatch)
+	}
+
+	claimed, err := s.cache.IncrWithExpire(ctx, magicLinkClaimPrefix+hash, s.config.TTL)
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrMagicLinkStoreFailed)
+	}
+	if claimed > 1 {
+		return nil, apperrors.BadRequestCode(i18n.ErrMagicLinkInvalid)
+	}
+	_ = s.cache.Delete(ctx, magicLinkTokenPrefix+hash)
+
+	user, err := s.userRepo.FindByID(ctx, record.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, apperrors.BadRequestCode(i18n.ErrMagicLinkInvalid)
+		}
+		return nil, apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+	return user, nil
+}
+
+// checkSendLimits applies the resend cooldown and the hourly quota of an address.
+// Unknown addresses count too, so the limits cannot be used to probe for accounts.
+func (s *MagicLinkService) checkSendLimits(ctx context.Context, email string) error {
+	if s.config.Cooldown > 0 {
+		n, err := s.cache.IncrWithExpire(ctx, magicLinkCooldownPrefix+email, s.config.Cooldown)
+		if err != nil {
+			return apperrors.InternalCode(err, i18n.ErrMagicLinkStoreFailed)
+		}
+		if n > 1 {
+			return apperrors.TooManyRequestsCode(i18n.ErrMagicLinkRateLimit)
+		}
+	}
+
+	if s.config.PerHour > 0 {
+		n, err := s.cache.IncrWithExpire(ctx, magicLinkHourlyPrefix+email, time.Hour)
+		if err != nil {
+			return apperrors.InternalCode(err, i18n.ErrMagicLinkStoreFailed)
+		}
+		if n > int64(s.config.PerHour) {
+			return apperrors.TooManyRequestsCode(i18n.ErrMagicLinkRateLimit)
+		}
+	}
+	return nil
+}
+
+// link adds the token to the configured landing page URL
+func (s *MagicLinkService) link(token string) (string, error) {
+	u, err := url.Parse(s.config.URL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"go-api-starter/internal/config"
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/repository"
+	"go-api-starter/pkg/apperrors"
+	"go-api-starter/pkg/auth"
+	"go-api-starter/pkg/cache"
+	"go-api-starter/pkg/i18n"
+)
+
+const (
+	mfaAttemptsPrefix   = "mfa:attempts:"
+	mfaUsedPrefix       = "mfa:used:"
+	mfaTOTPSkew         = 1 // 允许前后各一个时间步的时钟偏差
+	recoveryCodeCount   = 10
+	recoveryCodeAlpha   = "abcdefghjkmnpqrstuvwxyz23456789"
+	recoveryCodeHalfLen = 4
+)
+
+// MFAService manages TOTP enrollment and the second step of login
+type MFAService struct {
+	mfaRepo      repository.MFARepositoryInterface
+	userRepo     repository.UserRepositoryInterface
+	userRoleRepo repository.UserRoleRepositoryInterface
+	jwtManager   *auth.JWTManager
+	cache        cache.CacheBackend
+	config       config.MFAConfig
+}
+
+// NewMFAService creates a new MFAService
+func NewMFAService(mfaRepo repository.MFARepositoryInterface, userRepo repository.UserRepositoryInterface, userRoleRepo repository.UserRoleRepositoryInterface, jwtManager *auth.JWTManager, cacheBackend cache.CacheBackend, cfg config.MFAConfig) *MFAService {
+	return &MFAService{
+		mfaRepo:      mfaRepo,
+		userRepo:     userRepo,
+		userRoleRepo: userRoleRepo,
+		jwtManager:   jwtManager,
+		cache:        cacheBackend,
+		config:       cfg,
+	}
+}
+
+// Enroll creates a new (not yet enabled) TOTP secret and recovery codes for the user
+func (s *MFAService) Enroll(ctx context.Context, userID uint) (*model.MFAEnrollResponse, error) {
+	existing, err := s.mfaRepo.FindByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrMFANotFound) {
+		return nil, apperrors.InternalCode(err, i18n.ErrMFAStoreFailed)
+	}
+	if existing != nil && existing.IsEnabled() {
+		return nil, apperrors.ConflictCode(i18n.ErrMFAAlreadyEnabled)
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, apperrors.NotFoundCode(i18n.ErrUserNotFound)
+		}
+		return nil, apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+	return s.enroll(ctx, user)
+}
+
+func (s *MFAService) enroll(ctx context.Context, user *model.User) (*model.MFAEnrollResponse, error) {
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrMFAStoreFailed)
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrMFAStoreFailed)
+	}
+
+	if err := s.mfaRepo.ReplacePending(ctx, &model.UserMFA{UserID: user.ID, Secret: secret}, hashes); err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrMFAStoreFailed)
+	}
+
+	return &model.MFAEnrollResponse{
+		Secret:        secret,
+		OTPAuthURI:    auth.TOTPURI(s.config.Issuer, accountLabel(user), secret),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// Activate confirms enrollment with the first code from the authenticator app
+func (s *MFAService) Activate(ctx context.Context, userID uint, code string) error {
+	record, err := s.findRecord(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if record.IsEnabled() {
+		return apperrors.ConflictCode(i18n.ErrMFAAlreadyEnabled)
+	}
+
+	step, ok := auth.ValidateTOTP(record.Secret, code, time.Now(), mfaTOTPSkew)
+	if !ok {
+		return apperrors.BadRequestCode(i18n.ErrMFACodeInvalid)
+	}
+	if err := s.mfaRepo.Enable(ctx, record.ID, step); err != nil {
+		return apperrors.InternalCode(err, i18n.ErrMFAStoreFailed)
+	}
+	return nil
+}
+
+// Disable removes the second factor after checking a TOTP or recovery code.
+// Users whose role requires MFA cannot disable it.
+func (s *MFAService) Disable(ctx context.Context, userID uint, code string) error {
+	record, err := s.findRecord(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !record.IsEnabled() {
+		return apperrors.BadRequestCode(i18n.ErrMFANotEnrolled)
+	}
+
+	required, err := s.requiredByRole(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return apperrors.ForbiddenCode(i18n.ErrMFARequiredByRole)
+	}
+
+	ok, err := s.checkCode(ctx, record, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apperrors.BadRequestCode(i18n.ErrMFACodeInvalid)
+	}
+
+	if err := s.mfaRepo.DeleteByUserID(ctx, userID); err != nil {
+		return apperrors.InternalCode(err, i18n.ErrMFAStoreFailed)
+	}
+	return nil
+}
+
+// Challenge returns an mfa_pending login response when the user must pass a second factor,
+// or nil when the password alone is enough. Users that must use MFA but have not set it up
+// receive enrollment data; their first valid code enables MFA and completes the login.
+func (s *MFAService) Challenge(ctx context.Context, user *model.User) (*model.LoginResponse, error) {
+	record, err := s.mfaRepo.FindByUserID(ctx, user.ID)
+	if err != nil && !errors.Is(err, repository.ErrMFANotFound) {
+		return nil, apperrors.InternalCode(err, i18n.ErrMFAStoreFailed)
+	}
+
+	var enrollment *model.MFAEnrollResponse
+	if record == nil || !record.IsEnabled() {
+		required, err := s.requiredByRole(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			return nil, nil
+		}
+		if enrollment, err = s.enroll(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := s.jwtManager.GenerateMFAToken(user.ID, s.config.PendingTTL)
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrGenerateTokenFailed)
+	}
+	return &model.LoginResponse{
+		MFARequired:   true,
+		MFAToken:      token,
+		MFAEnrollment: enrollment,
+	}, nil
+}
+
+// PendingUserID returns the user an mfa_pending token was issued to, without using up the token
+func (s *MFAService) PendingUserID(mfaToken string) (uint, error) {
+	claims, err := s.jwtManager.ValidateMFAToken(mfaToken)
+	if err != nil {
+		return 0, apperrors.UnauthorizedCode(i18n.ErrMFATokenInvalid)
+	}
+	return claims.UserID, nil
+}
+
+// VerifyChallenge checks the second-factor code for an mfa_pending token and returns the user.
+// Each token can complete a login once and allows at most MaxAttempts wrong codes.
+func (s *MFAService) VerifyChallenge(ctx context.Context, mfaToken, code string) (*model.User, error) {
+	claims, err := s.jwtManager.ValidateMFAToken(mfaToken)
+	if err != nil {
+		return nil, apperrors.UnauthorizedCode(i18n.ErrMFATokenInvalid)
+	}
+	ttl := time.Until(claims.ExpiresAt.Time)
+
+	if s.config.MaxAttempts > 0 {
+		attempts, err := s.cache.IncrWithExpire(ctx, mfaAttemptsPrefix+claims.ID, ttl)
+		if err != nil {
+			return nil, apperrors.InternalCode(err, i18n.ErrMFAStoreFailed)
+		}
+		if attempts > int64(s.config.MaxAttempts) {
+			return nil, apperrors.UnauthorizedCode(i18n.ErrMFATokenInvalid)
+		}
+	}
+
+	record, err := s.findRecord(ctx, claims.UserID)
+	if err != nil {
+		return nil, apperrors.UnauthorizedCode(i18n.ErrMFATokenInvalid)
+	}
+
+	if record.IsEnabled() {
+		ok, err := s.checkCode(ctx, record, code)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, apperrors.UnauthorizedCode(i18n.ErrMFACodeInvalid)
+		}
+	} else {
+		// Mandatory enrollment started at login: the first valid code enables MFA
+		step, ok := auth.ValidateTOTP(record.Secret, code, time.Now(), mfaTOTPSkew)
+		if !ok {
+			return nil, apperrors.UnauthorizedCode(i18n.ErrMFACodeInvalid)
+		}
+		if err := s.mfaRepo.Enable(ctx, record.ID, step); err != nil {
+			return nil, apperrors.InternalCode(err, i18n.ErrMFAStoreFailed)
+		}
+	}
+
+	// One successful exchange per token
+	used, err := s.cache.IncrWithExpire(ctx, mfaUsedPrefix+claims.ID, ttl)
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrMFAStoreFailed)
+	}
+	if used > 1 {
+		return nil, apperrors.UnauthorizedCode(i18n.ErrMFATokenInvalid)
+	}
+	_ = s.cache.Delete(ctx, mfaAttemptsPrefix+claims.ID)
+
+	user, err := s.userRepo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, apperrors.UnauthorizedCode(i18n.ErrMFATokenInvalid)
+		}
+		return nil, apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+	if user.Freezed {
+		return nil, apperrors.ForbiddenCode(i18n.ErrAccountFrozen)
+	}
+	return user, nil
+}
+
+func (s *MFAService) findRecord(ctx context.Context, userID uint) (*model.UserMFA, error) {
+	record, err := s.mfaRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMFANotFound) {
+			return nil, apperrors.BadRequestCode(i18n.ErrMFANotEnrolled)
+		}
+		return nil, apperrors.InternalCode(err, i18n.ErrMFAStoreFailed)
+	}
+	return record, nil
+}
+
+// checkCode accepts a TOTP code (each time step only once) or an unused recovery code
+func (s *MFAService) checkCode(ctx context.Context, record *model.UserMFA, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if step, ok := auth.ValidateTOTP(record.Secret, code, time.Now(), mfaTOTPSkew); ok {
+		fresh, err := s.mfaRepo.ConsumeStep(ctx, record.ID, step)
+		if err != nil {
+			return false, apperrors.InternalCode(err, i18n.ErrMFAStoreFailed)
+		}
+		return fresh, nil
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != recoveryCodeHalfLen*2 {
+		return false, nil
+	}
+	used, err := s.mfaRepo.UseRecoveryCode(ctx, record.UserID, hashToken(normalized))
+	if err != nil {
+		return false, apperrors.InternalCode(err, i18n.ErrMFAStoreFailed)
+	}
+	return used, nil
+}
+
+// requiredByRole reports whether one of the user's roles is listed in mfa.required_roles
+func (s *MFAService) requiredByRole(ctx context.Context, userID uint) (bool, error) {
+	if len(s.config.RequiredRoles) == 0 {
+		return false, nil
+	}
+	userRoles, err := s.userRoleRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return false, apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+	now := time.Now()
+	for _, ur := range userRoles {
+		if ur.Role == nil || !ur.ActiveAt(now) {
+			continue
+		}
+		for _, name := range s.config.RequiredRoles {
+			if ur.Role.Name == name {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// accountLabel picks the account name shown in the authenticator app
+func accountLabel(user *model.User) string {
+	switch {
+	case user.Email != nil && *user.Email != "":
+		return *user.Email
+	case user.Mobile != nil && *user.Mobile != "":
+		return *user.Mobile
+	case user.Username != nil:
+		return *user.Username
+	}
+	return user.SecUID
+}
+
+// generateRecoveryCodes returns display codes ("xxxx-xxxx") and their hashes
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	max := big.NewInt(int64(len(recoveryCodeAlpha)))
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, recoveryCodeHalfLen*2)
+		for j := range b {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return nil, nil, err
+			}
+			b[j] = recoveryCodeAlpha[n.Int64()]
+		}
+		raw := string(b)
+		codes = append(codes, raw[:recoveryCodeHalfLen]+"-"+raw[recoveryCodeHalfLen:])
+		hashes = append(hashes, hashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode lowercases a recovery code and strips separators
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
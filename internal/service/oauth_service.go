@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"go-api-starter/internal/config"
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/repository"
+	"go-api-starter/pkg/apperrors"
+	"go-api-starter/pkg/auth"
+	"go-api-starter/pkg/i18n"
+)
+
+const (
+	oauthClientSecretBytes   = 32
+	oauthClientTouchInterval = time.Minute
+)
+
+// OAuthService manages OAuth clients and answers their token introspection (RFC 7662)
+// and revocation (RFC 7009) requests, so other services can honour logouts, revoked
+// sessions and frozen accounts without sharing our token blacklist. Clients also obtain
+// their own access tokens through the client_credentials grant.
+type OAuthService struct {
+	clientRepo       repository.OAuthClientRepositoryInterface
+	jwtManager       *auth.JWTManager
+	tokenBlacklist   TokenBlacklist
+	refreshTokenRepo repository.RefreshTokenRepositoryInterface
+	sessionService   *SessionService
+	userRepo         repository.UserRepositoryInterface
+	permChecker      *PermissionChecker
+	clientTokenTTL   time.Duration
+}
+
+// NewOAuthService creates a new OAuthService
+func NewOAuthService(
+	clientRepo repository.OAuthClientRepositoryInterface,
+	jwtManager *auth.JWTManager,
+	blacklist TokenBlacklist,
+	refreshTokenRepo repository.RefreshTokenRepositoryInterface,
+	sessionService *SessionService,
+	userRepo repository.UserRepositoryInterface,
+	permChecker *PermissionChecker,
+	cfg config.OAuthConfig,
+) *OAuthService {
+	ttl := cfg.ClientTokenTTL
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+	return &OAuthService{
+		clientRepo:       clientRepo,
+		jwtManager:       jwtManager,
+		tokenBlacklist:   blacklist,
+		refreshTokenRepo: refreshTokenRepo,
+		sessionService:   sessionService,
+		userRepo:         userRepo,
+		permChecker:      permChecker,
+		clientTokenTTL:   ttl,
+	}
+}
+
+// CreateClient registers a client with scopes the administrator holds. The plaintext secret is returned only here.
+func (s *OAuthService) CreateClient(ctx context.Context, actorID uint, req *model.CreateOAuthClientRequest) (*model.CreateOAuthClientResponse, error) {
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, code := range req.Scopes {
+		code = strings.TrimSpace(code)
+		if code == "" || slices.Contains(scopes, code) {
+			continue
+		}
+		// 持有 secret 的人能以客户端身份行使这些权限，不能借此越权
+		ok, err := s.permChecker.HasPermission(ctx, actorID, code)
+		if err != nil {
+			return nil, apperrors.InternalCode(err, i18n.ErrOAuthClientStoreFailed)
+		}
+		if !ok {
+			return nil, apperrors.ForbiddenCode(i18n.ErrOAuthScopeDenied)
+		}
+		scopes = append(scopes, code)
+	}
+
+	secret := make([]byte, oauthClientSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrOAuthClientStoreFailed)
+	}
+	plaintext := model.OAuthClientSecretPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	client := &model.OAuthClient{
+		ClientID:   model.GenerateSecUID(),
+		Name:       strings.TrimSpace(req.Name),
+		SecretHash: hashToken(plaintext),
+		Scopes:     scopes,
+		CreatedBy:  actorID,
+	}
+	if err := s.clientRepo.Create(ctx, client); err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrOAuthClientStoreFailed)
+	}
+	return &model.CreateOAuthClientResponse{ClientSecret: plaintext, OAuthClientResponse: client.ToResponse()}, nil
+}
+
+// ListClients returns the clients that have not been revoked
+func (s *OAuthService) ListClients(ctx context.Context) ([]*model.OAuthClientResponse, error) {
+	clients, err := s.clientRepo.FindActive(ctx)
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrOAuthClientStoreFailed)
+	}
+	result := make([]*model.OAuthClientResponse, 0, len(clients))
+	for i := range clients {
+		result = append(result, clients[i].ToResponse())
+	}
+	return result, nil
+}
+
+// RevokeClient revokes a client; its credentials stop working immediately
+func (s *OAuthService) RevokeClient(ctx context.Context, clientID string) error {
+	revoked, err := s.clientRepo.Revoke(ctx, clientID)
+	if err != nil {
+		return apperrors.InternalCode(err, i18n.ErrOAuthClientStoreFailed)
+	}
+	if !revoked {
+		return apperrors.NotFoundCode(i18n.ErrOAuthClientNotFound)
+	}
+	return nil
+}
+
+// AuthenticateClient checks a client_id / client_secret pair
+func (s *OAuthService) AuthenticateClient(ctx context.Context, clientID, secret string) (*model.OAuthClient, error) {
+	if clientID == "" || secret == "" {
+		return nil, apperrors.UnauthorizedCode(i18n.ErrOAuthInvalidClient)
+	}
+	client, err := s.clientRepo.FindByClientID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOAuthClientNotFound) {
+			return nil, apperrors.UnauthorizedCode(i18n.ErrOAuthInvalidClient)
+		}
+		return nil, apperrors.InternalCode(err, i18n.ErrOAuthClientStoreFailed)
+	}
+	if !client.IsActive() || subtle.ConstantTimeCompare([]byte(client.SecretHash), []byte(hashToken(secret))) != 1 {
+		return nil, apperrors.UnauthorizedCode(i18n.ErrOAuthInvalidClient)
+	}
+
+	now := time.Now()
+	if client.LastUsedAt == nil || now.Sub(*client.LastUsedAt) >= oauthClientTouchInterval {
+		_ = s.clientRepo.TouchLastUsed(ctx, client.ID, now)
+		client.LastUsedAt = &now
+	}
+	return client, nil
+}
+
+// ActiveClient returns a client that has not been revoked
+func (s *OAuthService) ActiveClient(ctx context.Context, clientID string) (*model.OAuthClient, error) {
+	client, err := s.clientRepo.FindByClientID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOAuthClientNotFound) {
+			return nil, apperrors.UnauthorizedCode(i18n.ErrOAuthInvalidClient)
+		}
+		return nil, apperrors.InternalCode(err, i18n.ErrOAuthClientStoreFailed)
+	}
+	if !client.IsActive() {
+		return nil, apperrors.UnauthorizedCode(i18n.ErrOAuthInvalidClient)
+	}
+	return client, nil
+}
+
+// IssueClientToken implements the client_credentials grant. The requested scope must be a
+// subset of the client's scopes; when it is empty every scope of the client is granted.
+func (s *OAuthService) IssueClientToken(ctx context.Context, client *model.OAuthClient, scope string) (*model.ClientTokenResponse, error) {
+	granted := client.Scopes
+	if requested := strings.Fields(scope); len(requested) > 0 {
+		granted = client.AllowedScopes(requested)
+		if len(granted) != len(requested) {
+			return nil, apperrors.BadRequestCode(i18n.ErrOAuthInvalidScope)
+		}
+	}
+
+	token, _, err := s.jwtManager.IssueClientToken(client.ClientID, granted, s.clientTokenTTL)
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrGenerateTokenFailed)
+	}
+	return &model.ClientTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.clientTokenTTL.Seconds()),
+		Scope:       strings.Join(granted, " "),
+	}, nil
+}
+
+// Introspect reports whether a token is currently usable: signature and expiry, the logout
+// blacklist, the state of its session or refresh token, and the state of the user.
+// Every token that fails a check gets the same {"active": false}.
+func (s *OAuthService) Introspect(ctx context.Context, token string) (*model.IntrospectionResponse, error) {
+	inactive := &model.IntrospectionResponse{Active: false}
+
+	claims, err := s.jwtManager.ValidateToken(token)
+	if err != nil {
+		return inactive, nil
+	}
+	if claims.ClientID != "" && claims.TokenType == auth.TokenTypeAccess {
+		return s.introspectClientToken(ctx, token, claims)
+	}
+	if claims.UserID == 0 {
+		return inactive, nil
+	}
+	var active bool
+	switch claims.TokenType {
+	case auth.TokenTypeAccess:
+		active, err = s.accessTokenActive(ctx, token, claims)
+	case auth.TokenTypeRefresh:
+		active, err = s.refreshTokenActive(ctx, claims)
+	}
+	if err != nil || !active {
+		return inactive, err
+	}
+
+	user, err := s.activeUser(ctx, claims.UserID)
+	if err != nil || user == nil {
+		return inactive, err
+	}
+	// 模拟登录令牌随操作人账号一起失效
+	if claims.Act != nil {
+		actor, err := s.activeUser(ctx, claims.Act.UserID)
+		if err != nil || actor == nil {
+			return inactive, err
+		}
+	}
+
+	codes, err := s.permChecker.GetUserPermissions(ctx, user.ID)
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+	resp := &model.IntrospectionResponse{
+		Active:    true,
+		Scope:     strings.Join(codes, " "),
+		TokenType: claims.TokenType + "_token",
+		Sub:       user.SecUID,
+		Jti:       claims.ID,
+		SessionID: claims.SessionID,
+	}
+	if claims.ExpiresAt != nil {
+		resp.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		resp.Iat = claims.IssuedAt.Unix()
+	}
+	if claims.Act != nil {
+		resp.Act = &model.IntrospectionActor{Sub: claims.Act.Subject}
+	}
+	return resp, nil
+}
+
+// Revoke revokes an access token (until it expires) or the session behind a refresh token.
+// Invalid and unknown tokens are ignored, as RFC 7009 requires.
+func (s *OAuthService) Revoke(ctx context.Context, token string) error {
+	claims, err := s.jwtManager.ValidateToken(token)
+	if err != nil {
+		return nil
+	}
+
+	switch claims.TokenType {
+	case auth.TokenTypeAccess:
+		if s.tokenBlacklist == nil || claims.ExpiresAt == nil {
+			return nil
+		}
+		ttl := time.Until(claims.ExpiresAt.Time)
+		if ttl <= 0 {
+			return nil
+		}
+		if err := s.tokenBlacklist.Add(ctx, token, ttl); err != nil {
+			return apperrors.InternalCode(err, i18n.ErrRevokeSessionsFailed)
+		}
+	case auth.TokenTypeRefresh:
+		record, err := s.refreshTokenRepo.FindByJTI(ctx, claims.ID)
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return nil
+		}
+		if err != nil {
+			return apperrors.InternalCode(err, i18n.ErrRevokeSessionsFailed)
+		}
+		if record.UserID == claims.UserID {
+			return s.sessionService.RevokeFamily(ctx, record.FamilyID)
+		}
+	}
+	return nil
+}
+
+// introspectClientToken reports a client_credentials token, which is active while it is not
+// revoked and its client is not; its scope shrinks with the client's current scopes.
+func (s *OAuthService) introspectClientToken(ctx context.Context, token string, claims *auth.Claims) (*model.IntrospectionResponse, error) {
+	inactive := &model.IntrospectionResponse{Active: false}
+	if s.tokenBlacklist != nil {
+		blacklisted, err := s.tokenBlacklist.IsBlacklisted(ctx, token)
+		if err != nil || blacklisted {
+			return inactive, err
+		}
+	}
+	client, err := s.clientRepo.FindByClientID(ctx, claims.ClientID)
+	if errors.Is(err, repository.ErrOAuthClientNotFound) {
+		return inactive, nil
+	}
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrOAuthClientStoreFailed)
+	}
+	if !client.IsActive() {
+		return inactive, nil
+	}
+
+	resp := &model.IntrospectionResponse{
+		Active:    true,
+		Scope:     strings.Join(client.AllowedScopes(claims.Scopes()), " "),
+		TokenType: claims.TokenType + "_token",
+		ClientID:  client.ClientID,
+		Sub:       client.ClientID,
+		Jti:       claims.ID,
+	}
+	if claims.ExpiresAt != nil {
+		resp.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		resp.Iat = claims.IssuedAt.Unix()
+	}
+	return resp, nil
+}
+
+// accessTokenActive checks the logout blacklist and the session the token belongs to
+func (s *OAuthService) accessTokenActive(ctx context.Context, token string, claims *auth.Claims) (bool, error) {
+	if s.tokenBlacklist != nil {
+		blacklisted, err := s.tokenBlacklist.IsBlacklisted(ctx, token)
+		if err != nil || blacklisted {
+			return false, err
+		}
+	}
+	if claims.SessionID == "" {
+		return true, nil
+	}
+	return s.sessionService.IsActive(ctx, claims.UserID, claims.SessionID)
+}
+
+// refreshTokenActive checks that the token has not been rotated or revoked and its session is alive
+func (s *OAuthService) refreshTokenActive(ctx context.Context, claims *auth.Claims) (bool, error) {
+	record, err := s.refreshTokenRepo.FindByJTI(ctx, claims.ID)
+	if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, apperrors.InternalCode(err, i18n.ErrQuerySessionFailed)
+	}
+	if record.UserID != claims.UserID || !record.IsActive() {
+		return false, nil
+	}
+	return s.sessionService.FamilyActive(ctx, record.FamilyID)
+}
+
+// activeUser returns the user, or nil when it no longer exists or is frozen
+func (s *OAuthService) activeUser(ctx context.Context, userID uint) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+	if user.Freezed {
+		return nil, nil
+	}
+	return user, nil
+}
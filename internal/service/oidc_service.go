@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"go-api-starter/internal/config"
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/repository"
+	"go-api-starter/pkg/apperrors"
+	"go-api-starter/pkg/cache"
+	"go-api-starter/pkg/i18n"
+	"go-api-starter/pkg/logger"
+	"go-api-starter/pkg/oidc"
+)
+
+const (
+	oidcStatePrefix     = "oidc:state:"
+	oidcStateUsedPrefix = "oidc:state-used:"
+)
+
+// oidcAuthRequest is what we remember between the redirect to the provider and the callback
+type oidcAuthRequest struct {
+	Provider string `json:"provider"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
+
+// OIDCService runs the authorization-code flow against the configured providers
+// and maps provider accounts to local users
+type OIDCService struct {
+	providers    map[string]*oidc.Provider
+	providerList []model.OIDCProviderResponse
+	identityRepo repository.IdentityRepositoryInterface
+	userRepo     repository.UserRepositoryInterface
+	cache        cache.CacheBackend
+	stateTTL     time.Duration
+}
+
+// NewOIDCService creates a new OIDCService from the provider configuration
+func NewOIDCService(cfg config.OIDCConfig, identityRepo repository.IdentityRepositoryInterface, userRepo repository.UserRepositoryInterface, cacheBackend cache.CacheBackend) *OIDCService {
+	s := &OIDCService{
+		providers:    make(map[string]*oidc.Provider, len(cfg.Providers)),
+		providerList: make([]model.OIDCProviderResponse, 0, len(cfg.Providers)),
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		cache:        cacheBackend,
+		stateTTL:     cfg.StateTTL,
+	}
+	if s.stateTTL <= 0 {
+		s.stateTTL = 10 * time.Minute
+	}
+
+	for _, p := range cfg.Providers {
+		s.providers[p.Name] = oidc.NewProvider(oidc.Config{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: os.ExpandEnv(p.ClientSecret),
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		}, nil)
+
+		displayName := p.DisplayName
+		if displayName == "" {
+			displayName = p.Name
+		}
+		s.providerList = append(s.providerList, model.OIDCProviderResponse{Name: p.Name, DisplayName: displayName})
+	}
+	return s
+}
+
+// Providers lists the configured providers in configuration order
+func (s *OIDCService) Providers() []model.OIDCProviderResponse {
+	return s.providerList
+}
+
+// Authorize creates state, nonce and PKCE verifier and returns the provider's authorization URL
+func (s *OIDCService) Authorize(ctx context.Context, providerName string) (*model.OIDCAuthorizeResponse, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := oidc.RandomString(16)
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrOIDCStateStoreFailed)
+	}
+	nonce, err := oidc.RandomString(16)
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrOIDCStateStoreFailed)
+	}
+	verifier, err := oidc.GenerateVerifier()
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrOIDCStateStoreFailed)
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.S256Challenge(verifier))
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrOIDCProviderUnavailable)
+	}
+
+	data, err := json.Marshal(oidcAuthRequest{Provider: providerName, Nonce: nonce, Verifier: verifier})
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrOIDCStateStoreFailed)
+	}
+	if err := s.cache.Set(ctx, oidcStatePrefix+state, data, s.stateTTL); err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrOIDCStateStoreFailed)
+	}
+
+	return &model.OIDCAuthorizeResponse{AuthorizationURL: authURL, State: state}, nil
+}
+
+// Authenticate completes the flow: it consumes the state, exchanges the code,
+// validates the ID token and returns the local user for the provider account
+func (s *OIDCService) Authenticate(ctx context.Context, providerName, code, state string) (*model.User, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	authReq, err := s.consumeState(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	if authReq.Provider != providerName {
+		return nil, apperrors.BadRequestCode(i18n.ErrOIDCStateInvalid)
+	}
+
+	token, err := provider.Exchange(ctx, code, authReq.Verifier)
+	if err != nil {
+		return nil, s.providerError(providerName, err)
+	}
+	claims, err := provider.VerifyIDToken(ctx, token.IDToken, authReq.Nonce)
+	if err != nil {
+		return nil, s.providerError(providerName, err)
+	}
+
+	return s.resolveUser(ctx, providerName, claims)
+}
+
+// consumeState loads the stored authorization request; each state can be used once
+func (s *OIDCService) consumeState(ctx context.Context, state string) (*oidcAuthRequest, error) {
+	key := oidcStatePrefix + state
+	data, err := s.cache.Get(ctx, key)
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return nil, apperrors.BadRequestCode(i18n.ErrOIDCStateInvalid)
+	}
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrOIDCStateStoreFailed)
+	}
+
+	claimed, err := s.cache.IncrWithExpire(ctx, oidcStateUsedPrefix+state, s.stateTTL)
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrOIDCStateStoreFailed)
+	}
+	if claimed > 1 {
+		return nil, apperrors.BadRequestCode(i18n.ErrOIDCStateInvalid)
+	}
+	_ = s.cache.Delete(ctx, key)
+
+	var authReq oidcAuthRequest
+	if err := json.Unmarshal(data, &authReq); err != nil {
+		return nil, apperrors.BadRequestCode(i18n.ErrOIDCStateInvalid)
+	}
+	return &authReq, nil
+}
+
+// resolveUser applies the account-linking rules:
+//  1. a known provider account logs in as its linked user;
+//  2. otherwise a verified email that belongs to a local user links to that user;
+//  3. an email that belongs to a local user but is not verified by the provider is refused;
+//  4. anyone else gets a new account (with the email only when it is verified).
+func (s *OIDCService) resolveUser(ctx context.Context, providerName string, claims *oidc.IDTokenClaims) (*model.User, error) {
+	identity, err := s.identityRepo.FindByProviderSubject(ctx, providerName, claims.Subject)
+	if err == nil {
+		if claims.Email != "" && claims.Email != identity.Email {
+			_ = s.identityRepo.UpdateEmail(ctx, identity.ID, claims.Email)
+		}
+		user, err := s.userRepo.FindByID(ctx, identity.UserID)
+		if err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return nil, apperrors.UnauthorizedCode(i18n.ErrOIDCLoginFailed)
+			}
+			return nil, apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+		}
+		return user, nil
+	}
+	if !errors.Is(err, repository.ErrIdentityNotFound) {
+		return nil, apperrors.InternalCode(err, i18n.ErrIdentityStoreFailed)
+	}
+
+	identity = &model.UserIdentity{Provider: providerName, Subject: claims.Subject, Email: claims.Email}
+
+	if claims.Email != "" {
+		user, err := s.userRepo.FindByEmail(ctx, claims.Email)
+		if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+			return nil, apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+		}
+		if user != nil {
+			if !claims.EmailIsVerified() {
+				return nil, apperrors.ConflictCode(i18n.ErrOIDCEmailUnverified)
+			}
+			identity.UserID = user.ID
+			if err := s.identityRepo.Create(ctx, identity); err != nil {
+				return nil, apperrors.InternalCode(err, i18n.ErrIdentityStoreFailed)
+			}
+			return user, nil
+		}
+	}
+
+	user := &model.User{}
+	if claims.EmailIsVerified() {
+		email := claims.Email
+		user.Email = &email
+	}
+	if err := s.identityRepo.CreateWithUser(ctx, user, identity); err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrCreateUserFailed)
+	}
+	return user, nil
+}
+
+func (s *OIDCService) provider(name string) (*oidc.Provider, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, apperrors.NotFoundCode(i18n.ErrOIDCProviderNotFound)
+	}
+	return provider, nil
+}
+
+// providerError hides provider details from the client but keeps them in the log
+func (s *OIDCService) providerError(providerName string, err error) error {
+	if errors.Is(err, oidc.ErrDiscovery) {
+		return apperrors.InternalCode(err, i18n.ErrOIDCProviderUnavailable)
+	}
+	if logger.Log != nil {
+		logger.Log.Warnf("oidc login failed: provider=%s err=%v", providerName, err)
+	}
+	return apperrors.UnauthorizedCode(i18n.ErrOIDCLoginFailed)
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-api-starter/internal/config"
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/repository"
+	"go-api-starter/pkg/apperrors"
+	"go-api-starter/pkg/cache"
+	"go-api-starter/pkg/i18n"
+	"go-api-starter/pkg/logger"
+	"go-api-starter/pkg/webauthn"
+)
+
+const (
+	passkeyRegisterPrefix = "passkey:reg:"   // + user ID，一个用户同时只有一个注册仪式
+	passkeyLoginPrefix    = "passkey:login:" // + challenge
+	passkeyClaimPrefix    = "passkey:claim:" // + challenge，保证一个 challenge 只能完成一次登录
+)
+
+// PasskeyService registers passkeys and authenticates users with them. Challenges live in
+// the cache for the duration of one ceremony and are consumed when it completes.
+type PasskeyService struct {
+	rp             *webauthn.RelyingParty
+	credentialRepo repository.WebAuthnCredentialRepositoryInterface
+	userRepo       repository.UserRepositoryInterface
+	cache          cache.CacheBackend
+}
+
+// NewPasskeyService creates a new PasskeyService
+func NewPasskeyService(
+	credentialRepo repository.WebAuthnCredentialRepositoryInterface,
+	userRepo repository.UserRepositoryInterface,
+	cacheBackend cache.CacheBackend,
+	cfg config.WebAuthnConfig,
+) *PasskeyService {
+	return &PasskeyService{
+		rp: webauthn.New(webauthn.Config{
+			RPID:    cfg.RPID,
+			RPName:  cfg.RPName,
+			Origins: cfg.Origins,
+			Timeout: cfg.Timeout,
+		}),
+		credentialRepo: credentialRepo,
+		userRepo:       userRepo,
+		cache:          cacheBackend,
+	}
+}
+
+// BeginRegistration returns the options for creating a passkey for the signed-in user
+func (s *PasskeyService) BeginRegistration(ctx context.Context, userID uint) (*model.PasskeyCreationResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, apperrors.NotFoundCode(i18n.ErrUserNotFound)
+		}
+		return nil, apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+	existing, err := s.credentialRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrPasskeyStoreFailed)
+	}
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrPasskeyStoreFailed)
+	}
+	if err := s.cache.Set(ctx, s.registerKey(userID), challenge, s.rp.Timeout()); err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrPasskeyStoreFailed)
+	}
+
+	// user handle 用 SecUID：不含个人信息，登录时可据此核对凭据归属
+	options := s.rp.CreationOptions(challenge, webauthn.UserEntity{
+		ID:          webauthn.EncodeID([]byte(user.SecUID)),
+		Name:        passkeyUserName(user),
+		DisplayName: passkeyDisplayName(user),
+	}, descriptors(existing))
+	return &model.PasskeyCreationResponse{PublicKey: options}, nil
+}
+
+// FinishRegistration verifies the browser's response and stores the new passkey
+func (s *PasskeyService) FinishRegistration(ctx context.Context, userID uint, req *model.PasskeyRegisterRequest) (*model.PasskeyResponse, error) {
+	key := s.registerKey(userID)
+	challenge, err := s.cache.Get(ctx, key)
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return nil, apperrors.BadRequestCode(i18n.ErrPasskeyChallengeExpired)
+	}
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrPasskeyStoreFailed)
+	}
+
+	verified, err := s.rp.VerifyRegistration(challenge, &req.Credential)
+	if err != nil {
+		return nil, apperrors.BadRequestCode(i18n.ErrPasskeyInvalid)
+	}
+	_ = s.cache.Delete(ctx, key)
+
+	credentialID := webauthn.EncodeID(verified.ID)
+	if _, err := s.credentialRepo.FindByCredentialHash(ctx, hashToken(credentialID)); err == nil {
+		return nil, apperrors.ConflictCode(i18n.ErrPasskeyExists)
+	} else if !errors.Is(err, repository.ErrWebAuthnCredentialNotFound) {
+		return nil, apperrors.InternalCode(err, i18n.ErrPasskeyStoreFailed)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Passkey"
+	}
+	credential := &model.WebAuthnCredential{
+		UserID:         userID,
+		CredentialID:   credentialID,
+		CredentialHash: hashToken(credentialID),
+		PublicKey:      verified.PublicKey,
+		SignCount:      verified.SignCount,
+		Transports:     verified.Transports,
+		Name:           name,
+	}
+	if err := s.credentialRepo.Create(ctx, credential); err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrPasskeyStoreFailed)
+	}
+	return credential.ToResponse(), nil
+}
+
+// List returns the user's passkeys
+func (s *PasskeyService) List(ctx context.Context, userID uint) ([]*model.PasskeyResponse, error) {
+	credentials, err := s.credentialRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrPasskeyStoreFailed)
+	}
+	result := make([]*model.PasskeyResponse, 0, len(credentials))
+	for i := range credentials {
+		result = append(result, credentials[i].ToResponse())
+	}
+	return result, nil
+}
+
+// Delete removes one of the user's passkeys
+func (s *PasskeyService) Delete(ctx context.Context, userID uint, id string) error {
+	deleted, err := s.credentialRepo.DeleteBySecUID(ctx, userID, id)
+	if err != nil {
+		return apperrors.InternalCode(err, i18n.ErrPasskeyStoreFailed)
+	}
+	if !deleted {
+		return apperrors.NotFoundCode(i18n.ErrPasskeyNotFound)
+	}
+	return nil
+}
+
+// BeginLogin returns the options for signing in with a passkey. When an account is given
+// only its passkeys are offered; an unknown account gets the same answer as no account,
+// so the response does not reveal which accounts exist.
+func (s *PasskeyService) BeginLogin(ctx context.Context, req *model.PasskeyLoginBeginRequest) (*model.PasskeyRequestResponse, error) {
+	var allow []webauthn.CredentialDescriptor
+	if account := normalizeAccount(req.Account); account != "" {
+		var user *model.User
+		var err error
+		if strings.Contains(account, "@") {
+			user, err = s.userRepo.FindByEmail(ctx, account)
+		} else {
+			user, err = s.userRepo.FindByMobile(ctx, account)
+		}
+		if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+			return nil, apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+		}
+		if user != nil {
+			credentials, err := s.credentialRepo.FindByUserID(ctx, user.ID)
+			if err != nil {
+				return nil, apperrors.InternalCode(err, i18n.ErrPasskeyStoreFailed)
+			}
+			allow = descriptors(credentials)
+		}
+	}
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrPasskeyStoreFailed)
+	}
+	if err := s.cache.Set(ctx, passkeyLoginPrefix+webauthn.EncodeID(challenge), []byte("1"), s.rp.Timeout()); err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrPasskeyStoreFailed)
+	}
+	return &model.PasskeyRequestResponse{PublicKey: s.rp.RequestOptions(challenge, allow)}, nil
+}
+
+// Authenticate verifies an assertion and returns the user who owns the passkey.
+// The challenge is looked up from the response itself and can be used only once.
+func (s *PasskeyService) Authenticate(ctx context.Context, resp *webauthn.AuthenticationResponse) (*model.User, error) {
+	challenge, err := webauthn.ChallengeOf(resp.Response.ClientDataJSON)
+	if err != nil {
+		return nil, apperrors.BadRequestCode(i18n.ErrPasskeyInvalid)
+	}
+	encoded := webauthn.EncodeID(challenge)
+	if _, err := s.cache.Get(ctx, passkeyLoginPrefix+encoded); err != nil {
+		if errors.Is(err, cache.ErrKeyNotFound) {
+			return nil, apperrors.BadRequestCode(i18n.ErrPasskeyChallengeExpired)
+		}
+		return nil, apperrors.InternalCode(err, i18n.ErrPasskeyStoreFailed)
+	}
+	claimed, err := s.cache.IncrWithExpire(ctx, passkeyClaimPrefix+encoded, s.rp.Timeout())
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrPasskeyStoreFailed)
+	}
+	if claimed > 1 {
+		return nil, apperrors.BadRequestCode(i18n.ErrPasskeyChallengeExpired)
+	}
+	_ = s.cache.Delete(ctx, passkeyLoginPrefix+encoded)
+
+	rawID, err := resp.CredentialID()
+	if err != nil {
+		return nil, apperrors.BadRequestCode(i18n.ErrPasskeyInvalid)
+	}
+	credential, err := s.credentialRepo.FindByCredentialHash(ctx, hashToken(webauthn.EncodeID(rawID)))
+	if err != nil {
+		if errors.Is(err, repository.ErrWebAuthnCredentialNotFound) {
+			return nil, apperrors.UnauthorizedCode(i18n.ErrPasskeyInvalid)
+		}
+		return nil, apperrors.InternalCode(err, i18n.ErrPasskeyStoreFailed)
+	}
+	// 用户已被删除时 Preload 得到零值
+	user := &credential.User
+	if user.ID == 0 {
+		return nil, apperrors.UnauthorizedCode(i18n.ErrPasskeyInvalid)
+	}
+	if handle := resp.Response.UserHandle; handle != "" {
+		if raw, err := webauthn.DecodeID(handle); err != nil || string(raw) != user.SecUID {
+			return nil, apperrors.UnauthorizedCode(i18n.ErrPasskeyInvalid)
+		}
+	}
+
+	signCount, err := s.rp.VerifyAuthentication(challenge, resp, credential.PublicKey, credential.SignCount)
+	if err != nil {
+		if errors.Is(err, webauthn.ErrSignCount) && logger.Log != nil {
+			logger.Log.Warnf("passkey %s of user %d reused a signature counter, it may have been cloned", credential.SecUID, user.ID)
+		}
+		return nil, apperrors.UnauthorizedCode(i18n.ErrPasskeyInvalid)
+	}
+	if err := s.credentialRepo.UpdateUsage(ctx, credential.ID, signCount, time.Now()); err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrPasskeyStoreFailed)
+	}
+	return user, nil
+}
+
+func (s *PasskeyService) registerKey(userID uint) string {
+	return passkeyRegisterPrefix + strconv.FormatUint(uint64(userID), 10)
+}
+
+// descriptors lists stored credentials for excludeCredentials / allowCredentials
+func descriptors(credentials []model.WebAuthnCredential) []webauthn.CredentialDescriptor {
+	result := make([]webauthn.CredentialDescriptor, 0, len(credentials))
+	for _, c := range credentials {
+		result = append(result, webauthn.CredentialDescriptor{Type: "public-key", ID: c.CredentialID, Transports: c.Transports})
+	}
+	return result
+}
+
+// passkeyUserName is the account name the authenticator shows next to the passkey
+func passkeyUserName(user *model.User) string {
+	for _, v := range []*string{user.Email, user.Mobile, user.Username} {
+		if v != nil && *v != "" {
+			return *v
+		}
+	}
+	return user.LPID
+}
+
+// passkeyDisplayName prefers the username over the login account
+func passkeyDisplayName(user *model.User) string {
+	if user.Username != nil && *user.Username != "" {
+		return *user.Username
+	}
+	return passkeyUserName(user)
+}
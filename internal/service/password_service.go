@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+
+	"go-api-starter/internal/config"
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/repository"
+	"go-api-starter/pkg/apperrors"
+	"go-api-starter/pkg/auth"
+	"go-api-starter/pkg/i18n"
+	"go-api-starter/pkg/logger"
+)
+
+// violationCodes maps policy rules to the error codes returned for the field
+var violationCodes = map[string]string{
+	auth.ViolationTooShort:      i18n.ErrPasswordTooShort,
+	auth.ViolationTooLong:       i18n.ErrPasswordTooLong,
+	auth.ViolationTooFewClasses: i18n.ErrPasswordTooSimple,
+	auth.ViolationBreached:      i18n.ErrPasswordBreached,
+}
+
+// PasswordService enforces the password policy on new passwords and keeps
+// each user's recent password hashes so they cannot be reused.
+type PasswordService struct {
+	historyRepo repository.PasswordHistoryRepositoryInterface
+	hasher      *auth.PasswordHasher
+	policy      auth.PasswordPolicy
+	historySize int
+}
+
+// NewPasswordService creates a new PasswordService
+func NewPasswordService(historyRepo repository.PasswordHistoryRepositoryInterface, cfg config.PasswordPolicyConfig) *PasswordService {
+	return &PasswordService{
+		historyRepo: historyRepo,
+		hasher:      auth.NewPasswordHasher(),
+		policy: auth.PasswordPolicy{
+			MinLength:     cfg.MinLength,
+			MaxLength:     cfg.MaxLength,
+			MinClasses:    cfg.MinClasses,
+			CheckBreached: cfg.CheckBreached,
+		},
+		historySize: cfg.HistorySize,
+	}
+}
+
+// CheckPolicy validates a new password against the policy; field names the request field in the error details
+func (s *PasswordService) CheckPolicy(field, password string) error {
+	violations := s.policy.Check(password)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	fields := make([]apperrors.FieldError, 0, len(violations))
+	for _, v := range violations {
+		fields = append(fields, apperrors.NewFieldError(field, violationCodes[v]))
+	}
+	return apperrors.ValidationCode(i18n.ErrPasswordPolicy, fields...)
+}
+
+// CheckHistory rejects the user's current password and their last HistorySize passwords
+func (s *PasswordService) CheckHistory(ctx context.Context, user *model.User, field, password string) error {
+	if s.historySize <= 0 {
+		return nil
+	}
+
+	entries, err := s.historyRepo.FindRecent(ctx, user.ID, s.historySize)
+	if err != nil {
+		return apperrors.InternalCode(err, i18n.ErrPasswordHistoryFailed)
+	}
+	hashes := make([]string, 0, len(entries)+1)
+	// 功能上线前设置的密码不在历史表中
+	if user.Password != nil {
+		hashes = append(hashes, *user.Password)
+	}
+	for _, e := range entries {
+		if user.Password == nil || e.PasswordHash != *user.Password {
+			hashes = append(hashes, e.PasswordHash)
+		}
+	}
+
+	for _, hash := range hashes {
+		if match, err := s.hasher.VerifyPassword(password, hash); err == nil && match {
+			return apperrors.ValidationCode(i18n.ErrPasswordPolicy, apperrors.NewFieldError(field, i18n.ErrPasswordReused))
+		}
+	}
+	return nil
+}
+
+// Remember records a newly set password hash and prunes entries beyond the history size.
+// Failures are logged; the password change itself has already succeeded.
+func (s *PasswordService) Remember(ctx context.Context, userID uint, hash string) {
+	if s.historySize <= 0 {
+		return
+	}
+	err := s.historyRepo.Create(ctx, &model.PasswordHistory{UserID: userID, PasswordHash: hash})
+	if err == nil {
+		err = s.historyRepo.Prune(ctx, userID, s.historySize)
+	}
+	if err != nil && logger.Log != nil {
+		logger.Log.Warnf("failed to update password history: user=%d err=%v", userID, err)
+	}
+}
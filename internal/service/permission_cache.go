@@ -2,12 +2,14 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"go-api-starter/internal/model"
-	"go-api-starter/internal/repository"
+	"go-api-starter/pkg/cache"
+	"go-api-starter/pkg/logger"
 )
 
 // CacheStats holds cache statistics
@@ -16,77 +18,305 @@ type CacheStats struct {
 	Misses int64
 }
 
-// PermissionCache manages user permission caching with TTL support
+const (
+	// permissionInvalidateChannel carries invalidation events between instances
+	permissionInvalidateChannel = "perm:invalidate"
+	// permissionL1MaxEntries bounds the in-process cache; it is emptied when full
+	permissionL1MaxEntries = 100000
+)
+
+// permissionInvalidation is the message published when cached permissions go stale
+type permissionInvalidation struct {
+	UserIDs []uint `json:"user_ids,omitempty"`
+	All     bool   `json:"all,omitempty"`
+}
+
+type l1Key struct {
+	userID  uint
+	spaceID uint
+}
+
+type l1Value struct {
+	value     uint64
+	version   uint64
+	expiresAt time.Time
+}
+
+type l1Permission struct {
+	perm      *model.Permission
+	version   uint64
+	expiresAt time.Time
+}
+
+// permissionLoad is what a cache miss captures before computing a user's values: the L1
+// version of this instance and the user's generation in the shared store
+type permissionLoad struct {
+	version    uint64
+	generation string
+	ok         bool // false when the generation could not be read; the values are not cached
+}
+
+// PermissionCache manages user permission caching with TTL support.
+//
+// Values live in a shared PermissionStore (L2) with a short-lived in-process copy (L1)
+// in front of it, which also remembers the space and bit of permission codes. Every L1
+// entry carries the version it was loaded under; any invalidation, local or received from
+// another instance, bumps the version and so drops the whole L1. Invalidations are rare
+// admin operations and the L1 refills from L2 on the next request.
 type PermissionCache struct {
-	cacheRepo repository.UserPermissionCacheRepositoryInterface
-	ttl       time.Duration
-	mu        sync.RWMutex
-	stats     CacheStats
+	store  PermissionStore
+	pubsub cache.PubSub
+	ttl    time.Duration
+	l1TTL  time.Duration
+
+	mu      sync.RWMutex
+	version uint64
+	values  map[l1Key]l1Value
+	perms   map[string]l1Permission
+	stats   CacheStats
 }
 
-// NewPermissionCache creates a new PermissionCache
-func NewPermissionCache(cacheRepo repository.UserPermissionCacheRepositoryInterface, ttl time.Duration) *PermissionCache {
+// NewPermissionCache creates a new PermissionCache. pubsub may be nil, in which case
+// invalidations only reach the L1 of this instance.
+func NewPermissionCache(store PermissionStore, pubsub cache.PubSub, ttl, l1TTL time.Duration) *PermissionCache {
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
 	return &PermissionCache{
-		cacheRepo: cacheRepo,
-		ttl:       ttl,
+		store:  store,
+		pubsub: pubsub,
+		ttl:    ttl,
+		l1TTL:  l1TTL,
+		values: make(map[l1Key]l1Value),
+		perms:  make(map[string]l1Permission),
+	}
+}
+
+// Listen drops the L1 whenever another instance publishes an invalidation, until ctx is cancelled
+func (c *PermissionCache) Listen(ctx context.Context) {
+	if c.pubsub == nil {
+		return
+	}
+	err := c.pubsub.Subscribe(ctx, permissionInvalidateChannel, func([]byte) {
+		c.dropL1()
+	})
+	if err != nil && logger.Log != nil {
+		logger.Log.Errorf("permission cache: subscribe failed: %v", err)
 	}
 }
 
 // Get retrieves cached permission value for a user and space
 // Returns nil if cache miss or expired
 func (c *PermissionCache) Get(ctx context.Context, userID, spaceID uint) (*uint64, error) {
-	cache, err := c.cacheRepo.FindByUserAndSpace(ctx, userID, spaceID)
-	if err != nil {
-		return nil, err
+	key := l1Key{userID: userID, spaceID: spaceID}
+	version := c.currentVersion()
+	if c.l1TTL > 0 {
+		c.mu.RLock()
+		entry, ok := c.values[key]
+		c.mu.RUnlock()
+		if ok && entry.version == version && time.Now().Before(entry.expiresAt) {
+			atomic.AddInt64(&c.stats.Hits, 1)
+			return &entry.value, nil
+		}
 	}
 
-	if cache == nil {
-		atomic.AddInt64(&c.stats.Misses, 1)
-		return nil, nil
+	value, expiresAt, ok, err := c.store.Get(ctx, userID, spaceID)
+	if err != nil {
+		return nil, err
 	}
-
-	// Check TTL expiration
-	if c.ttl > 0 && cache.ExpiresAt.Before(time.Now()) {
+	if !ok {
 		atomic.AddInt64(&c.stats.Misses, 1)
 		return nil, nil
 	}
 
 	atomic.AddInt64(&c.stats.Hits, 1)
-	return &cache.Value, nil
+	c.putL1(version, map[l1Key]uint64{key: value}, expiresAt)
+	return &value, nil
 }
 
 // Set stores permission values for a user with TTL
 func (c *PermissionCache) Set(ctx context.Context, userID uint, permissions map[uint]uint64) error {
-	now := time.Now()
-	expiresAt := now.Add(c.ttl)
+	return c.set(ctx, userID, permissions, c.beginLoad(ctx, userID), time.Time{})
+}
 
-	for spaceID, value := range permissions {
-		cache := &model.UserPermissionCache{
-			UserID:    userID,
-			SpaceID:   spaceID,
-			Value:     value,
-			ExpiresAt: expiresAt,
-			CreatedAt: now,
-			UpdatedAt: now,
+// beginLoad captures the state that set checks the computed values against. Call it
+// before reading the roles and grants the values are computed from.
+func (c *PermissionCache) beginLoad(ctx context.Context, userID uint) permissionLoad {
+	load := permissionLoad{version: c.currentVersion()}
+	generation, err := c.store.Generation(ctx, userID)
+	if err != nil {
+		if logger.Log != nil {
+			logger.Log.Warnf("permission cache: read generation of user %d: %v", userID, err)
 		}
-		if err := c.cacheRepo.Upsert(ctx, cache); err != nil {
-			return err
+		return load
+	}
+	load.generation, load.ok = generation, true
+	return load
+}
+
+// set stores values computed since load. Values computed before an invalidation are dropped
+// instead of overwriting the fresh state: the L1 version catches invalidations seen by this
+// instance, the store generation those made by any instance. An invalidation that lands
+// between the generation check and the write is caught by checking again afterwards and
+// deleting the value. A non-zero staleAt (e.g. a role assignment expiring) shortens the
+// TTL of the entry to end there.
+func (c *PermissionCache) set(ctx context.Context, userID uint, permissions map[uint]uint64, load permissionLoad, staleAt time.Time) error {
+	if !load.ok || c.currentVersion() != load.version {
+		return nil
+	}
+	ttl := c.ttl
+	if !staleAt.IsZero() {
+		if d := time.Until(staleAt); d < ttl {
+			ttl = d
 		}
 	}
+	// 马上就会变化的值不缓存，Redis 也不接受一秒以内的过期时间
+	if ttl < time.Second {
+		return nil
+	}
+	if changed, err := c.generationChanged(ctx, userID, load); changed || err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(ttl)
+	if err := c.store.Set(ctx, userID, permissions, ttl); err != nil {
+		return err
+	}
+	if changed, err := c.generationChanged(ctx, userID, load); changed || err != nil {
+		return c.store.Delete(ctx, []uint{userID})
+	}
+	entries := make(map[l1Key]uint64, len(permissions))
+	for spaceID, value := range permissions {
+		entries[l1Key{userID: userID, spaceID: spaceID}] = value
+	}
+	c.putL1(load.version, entries, expiresAt)
 	return nil
 }
 
+// generationChanged reports whether the user's values were invalidated since load
+func (c *PermissionCache) generationChanged(ctx context.Context, userID uint, load permissionLoad) (bool, error) {
+	generation, err := c.store.Generation(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return generation != load.generation, nil
+}
+
 // InvalidateUser removes all cached permissions for a user
 func (c *PermissionCache) InvalidateUser(ctx context.Context, userID uint) error {
-	return c.cacheRepo.DeleteByUserID(ctx, userID)
+	return c.invalidate(ctx, permissionInvalidation{UserIDs: []uint{userID}})
 }
 
 // InvalidateByRole removes cached permissions for all users with a specific role
 func (c *PermissionCache) InvalidateByRole(ctx context.Context, roleID uint, userIDs []uint) error {
+	return c.InvalidateUsers(ctx, userIDs)
+}
+
+// InvalidateUsers removes cached permissions for the given users
+func (c *PermissionCache) InvalidateUsers(ctx context.Context, userIDs []uint) error {
 	if len(userIDs) == 0 {
 		return nil
 	}
-	return c.cacheRepo.DeleteByUserIDs(ctx, userIDs)
+	return c.invalidate(ctx, permissionInvalidation{UserIDs: userIDs})
+}
+
+// InvalidateAll removes the cached permissions of every user, e.g. after bit positions changed
+func (c *PermissionCache) InvalidateAll(ctx context.Context) error {
+	return c.invalidate(ctx, permissionInvalidation{All: true})
+}
+
+// invalidate clears the shared store, then the L1 of every instance. The local L1 is
+// dropped on both sides of the store delete, so neither a load that started before it
+// nor one that read the store in between can refill the L1 with old values. The store
+// changes the generations before deleting, which stops loads on other instances from
+// writing values computed before this call back into it.
+func (c *PermissionCache) invalidate(ctx context.Context, event permissionInvalidation) error {
+	c.dropL1()
+	var err error
+	if event.All {
+		err = c.store.DeleteAll(ctx)
+	} else {
+		err = c.store.Delete(ctx, event.UserIDs)
+	}
+	c.dropL1()
+	if err != nil {
+		return err
+	}
+
+	if c.pubsub == nil {
+		return nil
+	}
+	// 通知发不出去时不影响本次修改，其他实例的 L1 最多在 l1TTL 后过期
+	data, _ := json.Marshal(event)
+	if err := c.pubsub.Publish(ctx, permissionInvalidateChannel, data); err != nil && logger.Log != nil {
+		logger.Log.Warnf("permission cache: publish invalidation failed: %v", err)
+	}
+	return nil
+}
+
+// permission returns the cached space and bit of a permission code, or nil
+func (c *PermissionCache) permission(code string) *model.Permission {
+	if c.l1TTL <= 0 {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.perms[code]
+	if !ok || entry.version != c.version || time.Now().After(entry.expiresAt) {
+		return nil
+	}
+	return entry.perm
+}
+
+// putPermission remembers a permission looked up under version
+func (c *PermissionCache) putPermission(code string, perm *model.Permission, version uint64) {
+	if c.l1TTL <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version != version {
+		return
+	}
+	if len(c.perms) >= permissionL1MaxEntries {
+		c.perms = make(map[string]l1Permission)
+	}
+	c.perms[code] = l1Permission{perm: perm, version: version, expiresAt: time.Now().Add(c.l1TTL)}
+}
+
+// putL1 keeps entries loaded under version for l1TTL, but not past expiresAt
+func (c *PermissionCache) putL1(version uint64, entries map[l1Key]uint64, expiresAt time.Time) {
+	if c.l1TTL <= 0 {
+		return
+	}
+	if limit := time.Now().Add(c.l1TTL); expiresAt.IsZero() || limit.Before(expiresAt) {
+		expiresAt = limit
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version != version {
+		return
+	}
+	if len(c.values)+len(entries) > permissionL1MaxEntries {
+		c.values = make(map[l1Key]l1Value)
+	}
+	for key, value := range entries {
+		c.values[key] = l1Value{value: value, version: version, expiresAt: expiresAt}
+	}
+}
+
+// dropL1 bumps the version, which makes every L1 entry stale, and frees the entries
+func (c *PermissionCache) dropL1() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	c.values = make(map[l1Key]l1Value)
+	c.perms = make(map[string]l1Permission)
+}
+
+func (c *PermissionCache) currentVersion() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.version
 }
 
 // GetStats returns cache statistics
@@ -104,5 +334,5 @@ func (c *PermissionCache) GetTTL() time.Duration {
 
 // GetAllForUser retrieves all cached permission values for a user
 func (c *PermissionCache) GetAllForUser(ctx context.Context, userID uint) (map[uint]uint64, error) {
-	return c.cacheRepo.GetUserSpaceValues(ctx, userID)
+	return c.store.GetAll(ctx, userID)
 }
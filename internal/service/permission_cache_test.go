@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-api-starter/pkg/cache"
+)
+
+// invalidatingStore calls before ahead of each Set, to interleave another instance's
+// invalidation with a write
+type invalidatingStore struct {
+	PermissionStore
+	before func()
+}
+
+func (s *invalidatingStore) Set(ctx context.Context, userID uint, values map[uint]uint64, ttl time.Duration) error {
+	if s.before != nil {
+		s.before()
+	}
+	return s.PermissionStore.Set(ctx, userID, values, ttl)
+}
+
+// TestPermissionCacheDropsValuesInvalidatedElsewhere tests two instances sharing one backend:
+// values computed before an invalidation on the other instance never stay in the store
+func TestPermissionCacheDropsValuesInvalidatedElsewhere(t *testing.T) {
+	ctx := context.Background()
+	backend := cache.NewMemoryCache()
+	t.Cleanup(func() { backend.Close() })
+	store := &invalidatingStore{PermissionStore: NewBackendPermissionStore(backend)}
+	first := NewPermissionCache(store, nil, time.Hour, time.Minute)
+	second := NewPermissionCache(NewBackendPermissionStore(backend), nil, time.Hour, time.Minute)
+	stale := map[uint]uint64{1: 0b11}
+
+	// Invalidated while computing: the write is skipped
+	load := first.beginLoad(ctx, 7)
+	require.NoError(t, second.InvalidateUser(ctx, 7))
+	require.NoError(t, first.set(ctx, 7, stale, load, time.Time{}))
+	value, err := second.Get(ctx, 7, 1)
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	// Invalidated between the check and the write: the value is deleted again
+	load = first.beginLoad(ctx, 7)
+	store.before = func() { require.NoError(t, second.InvalidateUser(ctx, 7)) }
+	require.NoError(t, first.set(ctx, 7, stale, load, time.Time{}))
+	value, err = second.Get(ctx, 7, 1)
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	// An invalidation of all users counts as well
+	store.before = nil
+	load = first.beginLoad(ctx, 7)
+	require.NoError(t, second.InvalidateAll(ctx))
+	require.NoError(t, first.set(ctx, 7, stale, load, time.Time{}))
+	value, err = second.Get(ctx, 7, 1)
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	// Without an invalidation the values are shared
+	load = first.beginLoad(ctx, 7)
+	require.NoError(t, first.set(ctx, 7, stale, load, time.Time{}))
+	value, err = second.Get(ctx, 7, 1)
+	require.NoError(t, err)
+	require.NotNil(t, value)
+	assert.Equal(t, uint64(0b11), *value)
+}
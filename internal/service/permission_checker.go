@@ -4,12 +4,21 @@ import (
 	"context"
 	"time"
 
+	"go-api-starter/internal/model"
 	"go-api-starter/internal/repository"
+	"go-api-starter/pkg/apperrors"
+	"go-api-starter/pkg/i18n"
 )
 
+// roleManagePermission lets its holder grant any role, including to themselves. Accounts
+// holding it cannot be impersonated, and only another holder can freeze them or reset
+// their password.
+const roleManagePermission = "role.manage"
+
 // PermissionChecker handles permission checking with caching support
 type PermissionChecker struct {
 	permRepo     repository.PermissionRepositoryInterface
+	roleRepo     repository.RoleRepositoryInterface
 	rolePermRepo repository.RolePermissionRepositoryInterface
 	userRoleRepo repository.UserRoleRepositoryInterface
 	cache        *PermissionCache
@@ -18,12 +27,14 @@ type PermissionChecker struct {
 // NewPermissionChecker creates a new PermissionChecker
 func NewPermissionChecker(
 	permRepo repository.PermissionRepositoryInterface,
+	roleRepo repository.RoleRepositoryInterface,
 	rolePermRepo repository.RolePermissionRepositoryInterface,
 	userRoleRepo repository.UserRoleRepositoryInterface,
 	cache *PermissionCache,
 ) *PermissionChecker {
 	return &PermissionChecker{
 		permRepo:     permRepo,
+		roleRepo:     roleRepo,
 		rolePermRepo: rolePermRepo,
 		userRoleRepo: userRoleRepo,
 		cache:        cache,
@@ -33,7 +44,7 @@ func NewPermissionChecker(
 // HasPermission checks if a user has a specific permission
 func (c *PermissionChecker) HasPermission(ctx context.Context, userID uint, code string) (bool, error) {
 	// Get permission by code
-	perm, err := c.permRepo.FindByCode(ctx, code)
+	perm, err := c.findPermission(ctx, code)
 	if err != nil {
 		return false, nil // Permission not found means no access
 	}
@@ -46,13 +57,14 @@ func (c *PermissionChecker) HasPermission(ctx context.Context, userID uint, code
 
 	// Cache miss - calculate and cache
 	if cachedValue == nil {
-		permissions, err := c.CalculateUserPermissions(ctx, userID)
+		load := c.cache.beginLoad(ctx, userID)
+		permissions, changesAt, err := c.calculate(ctx, userID)
 		if err != nil {
 			return false, err
 		}
 
 		// Cache the calculated permissions
-		if err := c.cache.Set(ctx, userID, permissions); err != nil {
+		if err := c.cache.set(ctx, userID, withSpace(permissions, perm.SpaceID), load, changesAt); err != nil {
 			return false, err
 		}
 
@@ -68,18 +80,38 @@ func (c *PermissionChecker) HasPermission(ctx context.Context, userID uint, code
 	return (*cachedValue & perm.Value) == perm.Value, nil
 }
 
+// checkPrivileged rejects acting on a role manager unless the actor is one too,
+// answering with the denied code
+func (c *PermissionChecker) checkPrivileged(ctx context.Context, actorID, targetID uint, denied string) error {
+	privileged, err := c.HasPermission(ctx, targetID, roleManagePermission)
+	if err != nil {
+		return apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+	if !privileged {
+		return nil
+	}
+	allowed, err := c.HasPermission(ctx, actorID, roleManagePermission)
+	if err != nil {
+		return apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+	if !allowed {
+		return apperrors.ForbiddenCode(denied)
+	}
+	return nil
+}
+
 // GetUserPermissions returns all permission codes for a user
 func (c *PermissionChecker) GetUserPermissions(ctx context.Context, userID uint) ([]string, error) {
-	// Get user roles
-	userRoles, err := c.userRoleRepo.FindByUserID(ctx, userID)
+	// Get user roles, including inherited ones
+	roleIDs, _, err := effectiveRoleIDs(ctx, c.userRoleRepo, c.roleRepo, userID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Collect unique permission codes
 	codeSet := make(map[string]struct{})
-	for _, ur := range userRoles {
-		rolePerms, err := c.rolePermRepo.FindByRoleID(ctx, ur.RoleID)
+	for _, roleID := range roleIDs {
+		rolePerms, err := c.rolePermRepo.FindByRoleID(ctx, roleID)
 		if err != nil {
 			continue
 		}
@@ -100,16 +132,22 @@ func (c *PermissionChecker) GetUserPermissions(ctx context.Context, userID uint)
 
 // CalculateUserPermissions calculates all permission values for a user by space
 func (c *PermissionChecker) CalculateUserPermissions(ctx context.Context, userID uint) (map[uint]uint64, error) {
-	// Get user roles
-	userRoles, err := c.userRoleRepo.FindByUserID(ctx, userID)
+	spaceValues, _, err := c.calculate(ctx, userID)
+	return spaceValues, err
+}
+
+// calculate also returns when the result goes stale because a role assignment starts or ends
+func (c *PermissionChecker) calculate(ctx context.Context, userID uint) (map[uint]uint64, time.Time, error) {
+	// Get user roles plus the roles they inherit from
+	roleIDs, changesAt, err := effectiveRoleIDs(ctx, c.userRoleRepo, c.roleRepo, userID)
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
 
 	// Aggregate permissions by space using bitwise OR
 	spaceValues := make(map[uint]uint64)
-	for _, ur := range userRoles {
-		rolePerms, err := c.rolePermRepo.FindByRoleID(ctx, ur.RoleID)
+	for _, roleID := range roleIDs {
+		rolePerms, err := c.rolePermRepo.FindByRoleID(ctx, roleID)
 		if err != nil {
 			continue
 		}
@@ -118,7 +156,7 @@ func (c *PermissionChecker) CalculateUserPermissions(ctx context.Context, userID
 		}
 	}
 
-	return spaceValues, nil
+	return spaceValues, changesAt, nil
 }
 
 // RefreshUserCache recalculates and caches user permissions
@@ -129,13 +167,14 @@ func (c *PermissionChecker) RefreshUserCache(ctx context.Context, userID uint) e
 	}
 
 	// Calculate new permissions
-	permissions, err := c.CalculateUserPermissions(ctx, userID)
+	load := c.cache.beginLoad(ctx, userID)
+	permissions, changesAt, err := c.calculate(ctx, userID)
 	if err != nil {
 		return err
 	}
 
 	// Cache the new permissions
-	return c.cache.Set(ctx, userID, permissions)
+	return c.cache.set(ctx, userID, permissions, load, changesAt)
 }
 
 // GetCacheStats returns cache statistics
@@ -151,7 +190,7 @@ func (c *PermissionChecker) GetCachedPermissions(ctx context.Context, userID uin
 // CheckPermissionWithCache checks permission and returns whether cache was used
 func (c *PermissionChecker) CheckPermissionWithCache(ctx context.Context, userID uint, code string) (hasPermission bool, cacheHit bool, err error) {
 	// Get permission by code
-	perm, err := c.permRepo.FindByCode(ctx, code)
+	perm, err := c.findPermission(ctx, code)
 	if err != nil {
 		return false, false, nil
 	}
@@ -164,12 +203,13 @@ func (c *PermissionChecker) CheckPermissionWithCache(ctx context.Context, userID
 
 	// Cache miss
 	if cachedValue == nil {
-		permissions, err := c.CalculateUserPermissions(ctx, userID)
+		load := c.cache.beginLoad(ctx, userID)
+		permissions, changesAt, err := c.calculate(ctx, userID)
 		if err != nil {
 			return false, false, err
 		}
 
-		if err := c.cache.Set(ctx, userID, permissions); err != nil {
+		if err := c.cache.set(ctx, userID, withSpace(permissions, perm.SpaceID), load, changesAt); err != nil {
 			return false, false, err
 		}
 
@@ -184,14 +224,37 @@ func (c *PermissionChecker) CheckPermissionWithCache(ctx context.Context, userID
 	return (*cachedValue & perm.Value) == perm.Value, true, nil
 }
 
+// findPermission resolves a code to its space and bit, from the in-process cache when possible
+func (c *PermissionChecker) findPermission(ctx context.Context, code string) (*model.Permission, error) {
+	if perm := c.cache.permission(code); perm != nil {
+		return perm, nil
+	}
+	version := c.cache.currentVersion()
+	perm, err := c.permRepo.FindByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.putPermission(code, perm, version)
+	return perm, nil
+}
+
+// withSpace makes sure spaceID has an entry, so that a user holding nothing in the
+// checked space is cached as 0 instead of missing the cache on every check
+func withSpace(permissions map[uint]uint64, spaceID uint) map[uint]uint64 {
+	if _, ok := permissions[spaceID]; !ok {
+		permissions[spaceID] = 0
+	}
+	return permissions
+}
+
 // InvalidateUserCache invalidates cache for a specific user
 func (c *PermissionChecker) InvalidateUserCache(ctx context.Context, userID uint) error {
 	return c.cache.InvalidateUser(ctx, userID)
 }
 
-// InvalidateRoleCache invalidates cache for all users with a specific role
+// InvalidateRoleCache invalidates cache for all users with a specific role or a role inheriting from it
 func (c *PermissionChecker) InvalidateRoleCache(ctx context.Context, roleID uint) error {
-	userIDs, err := c.userRoleRepo.GetUserIDsByRoleID(ctx, roleID)
+	userIDs, err := roleTreeUserIDs(ctx, c.roleRepo, c.userRoleRepo, roleID)
 	if err != nil {
 		return err
 	}
@@ -2,8 +2,11 @@ package service
 
 import (
 	"context"
+	"errors"
 
 	"go-api-starter/internal/model"
+	"go-api-starter/pkg/apperrors"
+	"go-api-starter/pkg/i18n"
 )
 
 // PermissionService wraps BitPermissionManager with additional business logic
@@ -32,6 +35,23 @@ func (s *PermissionService) GetAllSpaces(ctx context.Context) ([]model.SpaceWith
 	return s.manager.GetAllSpaces(ctx)
 }
 
+// UpdateSpace renames, describes or (de)activates a permission space
+func (s *PermissionService) UpdateSpace(ctx context.Context, id uint, req *model.UpdateSpaceRequest) (*model.PermissionSpace, error) {
+	space, err := s.manager.UpdateSpace(ctx, id, req.Name, req.Description, req.IsActive)
+	return space, permissionError(err)
+}
+
+// DeleteSpace deletes an empty permission space
+func (s *PermissionService) DeleteSpace(ctx context.Context, id uint) error {
+	return permissionError(s.manager.DeleteSpace(ctx, id))
+}
+
+// CompactSpace closes the position holes of a permission space
+func (s *PermissionService) CompactSpace(ctx context.Context, id uint) (*model.CompactSpaceResult, error) {
+	result, err := s.manager.CompactSpace(ctx, id)
+	return result, permissionError(err)
+}
+
 // CreatePermission creates a new permission
 func (s *PermissionService) CreatePermission(ctx context.Context, req *model.CreatePermissionRequest) (*model.Permission, error) {
 	return s.manager.CreatePermission(ctx, req.Code, req.Name, req.Description, req.SpaceID, req.Module)
@@ -54,13 +74,20 @@ func (s *PermissionService) UpdatePermission(ctx context.Context, id uint, req *
 
 // DeletePermission deletes a permission
 func (s *PermissionService) DeletePermission(ctx context.Context, id uint) error {
-	return s.manager.DeletePermission(ctx, id)
+	return permissionError(s.manager.DeletePermission(ctx, id))
+}
+
+// MovePermission moves a permission to another space
+func (s *PermissionService) MovePermission(ctx context.Context, id uint, req *model.MovePermissionRequest) (*model.Permission, error) {
+	perm, err := s.manager.MovePermission(ctx, id, req.SpaceID)
+	return perm, permissionError(err)
 }
 
 
 // CreateRole creates a new role
 func (s *PermissionService) CreateRole(ctx context.Context, req *model.CreateRoleRequest) (*model.Role, error) {
-	return s.manager.CreateRoleWithPermissions(ctx, req.Name, req.Description, req.PermissionCodes)
+	role, err := s.manager.CreateRoleWithPermissions(ctx, req.Name, req.Description, req.ParentID, req.PermissionCodes)
+	return role, permissionError(err)
 }
 
 // GetAllRoles returns all roles
@@ -75,7 +102,32 @@ func (s *PermissionService) GetRoleByID(ctx context.Context, id uint) (*model.Ro
 
 // UpdateRole updates a role
 func (s *PermissionService) UpdateRole(ctx context.Context, id uint, req *model.UpdateRoleRequest) (*model.Role, error) {
-	return s.manager.UpdateRole(ctx, id, req.Name, req.Description, req.IsActive)
+	role, err := s.manager.UpdateRole(ctx, id, req.Name, req.Description, req.IsActive, req.ParentID)
+	return role, permissionError(err)
+}
+
+// permissionError turns manager errors caused by the request into client errors;
+// other errors pass through
+func permissionError(err error) error {
+	switch {
+	case errors.Is(err, ErrPermissionSpaceNotFound):
+		return apperrors.NotFoundCode(i18n.ErrPermissionSpaceNotFound)
+	case errors.Is(err, ErrPermissionSpaceNameExists):
+		return apperrors.ConflictCode(i18n.ErrPermissionSpaceNameExists)
+	case errors.Is(err, ErrPermissionSpaceFull):
+		return apperrors.BadRequestCode(i18n.ErrPermissionSpaceFull)
+	case errors.Is(err, ErrPermissionSpaceNotEmpty):
+		return apperrors.ConflictCode(i18n.ErrPermissionSpaceNotEmpty)
+	case errors.Is(err, ErrPermissionNotFound):
+		return apperrors.NotFoundCode(i18n.ErrPermissionNotFound)
+	case errors.Is(err, ErrRoleParentNotFound):
+		return apperrors.BadRequestCode(i18n.ErrRoleParentNotFound)
+	case errors.Is(err, ErrRoleHierarchyCycle):
+		return apperrors.BadRequestCode(i18n.ErrRoleHierarchyCycle)
+	case errors.Is(err, ErrUserRoleWindowInvalid):
+		return apperrors.BadRequestCode(i18n.ErrUserRoleWindowInvalid)
+	}
+	return err
 }
 
 // DeleteRole deletes a role
@@ -103,9 +155,9 @@ func (s *PermissionService) GetUserRoles(ctx context.Context, userID uint) ([]mo
 	return s.manager.GetUserRoles(ctx, userID)
 }
 
-// AssignUserRole assigns a role to a user
-func (s *PermissionService) AssignUserRole(ctx context.Context, userID, roleID uint) error {
-	return s.manager.AssignRoleToUser(ctx, userID, roleID)
+// AssignUserRole assigns a role to a user, optionally for a limited time
+func (s *PermissionService) AssignUserRole(ctx context.Context, userID uint, req *model.AssignRoleRequest) error {
+	return permissionError(s.manager.AssignRoleToUser(ctx, userID, req.RoleID, req.StartsAt, req.ExpiresAt))
 }
 
 // RemoveUserRole removes a role from a user
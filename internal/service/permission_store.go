@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/repository"
+	"go-api-starter/pkg/cache"
+)
+
+// PermissionStore is the shared store behind PermissionCache: the computed permission
+// values of each user, by space
+type PermissionStore interface {
+	// Get returns the value of a user in a space and when it expires; ok is false when the
+	// user is not cached
+	Get(ctx context.Context, userID, spaceID uint) (value uint64, expiresAt time.Time, ok bool, err error)
+	// GetAll returns all cached space values of a user
+	GetAll(ctx context.Context, userID uint) (map[uint]uint64, error)
+	// Set replaces the values of a user
+	Set(ctx context.Context, userID uint, values map[uint]uint64, ttl time.Duration) error
+	// Delete drops the values of the given users, changing their generation first
+	Delete(ctx context.Context, userIDs []uint) error
+	// DeleteAll drops the values of every user, changing every generation first
+	DeleteAll(ctx context.Context) error
+	// Generation returns a token that changes whenever the user's values are deleted, on
+	// any instance. It lets a load detect an invalidation that happened while it computed.
+	Generation(ctx context.Context, userID uint) (string, error)
+}
+
+const (
+	// permissionStorePrefix + user ID holds the storedPermissions of that user
+	permissionStorePrefix = "perm:user:"
+	// permissionGenerationPrefix + generation scope holds the scope's generation token
+	permissionGenerationPrefix = "perm:gen:"
+	// permissionGenerationAll is the generation scope changed by DeleteAll
+	permissionGenerationAll = "all"
+	// permissionGenerationTTL only has to outlast a load; an expired token reads as changed
+	permissionGenerationTTL = 24 * time.Hour
+)
+
+// generationScope is the generation scope of a single user
+func generationScope(userID uint) string {
+	return strconv.FormatUint(uint64(userID), 10)
+}
+
+// joinGenerations combines the all-users and the user's token into one generation
+func joinGenerations(all, user string) string {
+	return all + ":" + user
+}
+
+// storedPermissions is the JSON kept per user by BackendPermissionStore
+type storedPermissions struct {
+	Values    map[uint]uint64 `json:"values"` // space ID → value
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// BackendPermissionStore keeps the values in a CacheBackend (Redis, or memory when Redis
+// is disabled). A user's values for all spaces sit under one key, so a space missing from
+// a cached entry means the user holds nothing in it.
+type BackendPermissionStore struct {
+	cache cache.CacheBackend
+}
+
+// NewBackendPermissionStore creates a store on top of the cache backend
+func NewBackendPermissionStore(cacheBackend cache.CacheBackend) *BackendPermissionStore {
+	return &BackendPermissionStore{cache: cacheBackend}
+}
+
+// Get returns the value of a user in a space
+func (s *BackendPermissionStore) Get(ctx context.Context, userID, spaceID uint) (uint64, time.Time, bool, error) {
+	stored, err := s.load(ctx, userID)
+	if err != nil || stored == nil {
+		return 0, time.Time{}, false, err
+	}
+	return stored.Values[spaceID], stored.ExpiresAt, true, nil
+}
+
+// GetAll returns all cached space values of a user
+func (s *BackendPermissionStore) GetAll(ctx context.Context, userID uint) (map[uint]uint64, error) {
+	stored, err := s.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil || stored.Values == nil {
+		return make(map[uint]uint64), nil
+	}
+	return stored.Values, nil
+}
+
+// Set replaces the values of a user
+func (s *BackendPermissionStore) Set(ctx context.Context, userID uint, values map[uint]uint64, ttl time.Duration) error {
+	data, err := json.Marshal(&storedPermissions{Values: values, ExpiresAt: time.Now().Add(ttl)})
+	if err != nil {
+		return err
+	}
+	return s.cache.Set(ctx, s.key(userID), data, ttl)
+}
+
+// Delete drops the values of the given users
+func (s *BackendPermissionStore) Delete(ctx context.Context, userIDs []uint) error {
+	for _, id := range userIDs {
+		if err := s.renew(ctx, generationScope(id)); err != nil {
+			return err
+		}
+		if err := s.cache.Delete(ctx, s.key(id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteAll drops the values of every user
+func (s *BackendPermissionStore) DeleteAll(ctx context.Context) error {
+	if err := s.renew(ctx, permissionGenerationAll); err != nil {
+		return err
+	}
+	return s.cache.DeleteByPrefix(ctx, permissionStorePrefix)
+}
+
+// Generation returns the user's generation token
+func (s *BackendPermissionStore) Generation(ctx context.Context, userID uint) (string, error) {
+	all, err := s.token(ctx, permissionGenerationAll)
+	if err != nil {
+		return "", err
+	}
+	user, err := s.token(ctx, generationScope(userID))
+	if err != nil {
+		return "", err
+	}
+	return joinGenerations(all, user), nil
+}
+
+// token returns the generation token of a scope, "" when it has none
+func (s *BackendPermissionStore) token(ctx context.Context, scope string) (string, error) {
+	data, err := s.cache.Get(ctx, permissionGenerationPrefix+scope)
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return "", nil
+	}
+	return string(data), err
+}
+
+// renew gives a scope a new random generation token
+func (s *BackendPermissionStore) renew(ctx context.Context, scope string) error {
+	return s.cache.Set(ctx, permissionGenerationPrefix+scope, []byte(model.GenerateSecUID()), permissionGenerationTTL)
+}
+
+// load returns nil without error when the user is not cached
+func (s *BackendPermissionStore) load(ctx context.Context, userID uint) (*storedPermissions, error) {
+	data, err := s.cache.Get(ctx, s.key(userID))
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var stored storedPermissions
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+func (s *BackendPermissionStore) key(userID uint) string {
+	return permissionStorePrefix + strconv.FormatUint(uint64(userID), 10)
+}
+
+// SQLPermissionStore keeps the values in the user_permission_caches table, one row per
+// user and space
+type SQLPermissionStore struct {
+	cacheRepo repository.UserPermissionCacheRepositoryInterface
+}
+
+// NewSQLPermissionStore creates a store on top of the user_permission_caches table
+func NewSQLPermissionStore(cacheRepo repository.UserPermissionCacheRepositoryInterface) *SQLPermissionStore {
+	return &SQLPermissionStore{cacheRepo: cacheRepo}
+}
+
+// Get returns the value of a user in a space; a missing or expired row is a miss
+func (s *SQLPermissionStore) Get(ctx context.Context, userID, spaceID uint) (uint64, time.Time, bool, error) {
+	row, err := s.cacheRepo.FindByUserAndSpace(ctx, userID, spaceID)
+	if err != nil || row == nil || row.IsExpired() {
+		return 0, time.Time{}, false, err
+	}
+	return row.Value, row.ExpiresAt, true, nil
+}
+
+// GetAll returns all cached space values of a user
+func (s *SQLPermissionStore) GetAll(ctx context.Context, userID uint) (map[uint]uint64, error) {
+	return s.cacheRepo.GetUserSpaceValues(ctx, userID)
+}
+
+// Set replaces the rows of a user
+func (s *SQLPermissionStore) Set(ctx context.Context, userID uint, values map[uint]uint64, ttl time.Duration) error {
+	if err := s.cacheRepo.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+	now := time.Now()
+	for spaceID, value := range values {
+		row := &model.UserPermissionCache{
+			UserID:    userID,
+			SpaceID:   spaceID,
+			Value:     value,
+			ExpiresAt: now.Add(ttl),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := s.cacheRepo.Upsert(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete drops the rows of the given users
+func (s *SQLPermissionStore) Delete(ctx context.Context, userIDs []uint) error {
+	scopes := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		scopes[i] = generationScope(id)
+	}
+	if err := s.cacheRepo.SetGeneration(ctx, scopes, model.GenerateSecUID()); err != nil {
+		return err
+	}
+	return s.cacheRepo.DeleteByUserIDs(ctx, userIDs)
+}
+
+// DeleteAll drops every row
+func (s *SQLPermissionStore) DeleteAll(ctx context.Context) error {
+	if err := s.cacheRepo.SetGeneration(ctx, []string{permissionGenerationAll}, model.GenerateSecUID()); err != nil {
+		return err
+	}
+	return s.cacheRepo.DeleteAll(ctx)
+}
+
+// Generation returns the user's generation token
+func (s *SQLPermissionStore) Generation(ctx context.Context, userID uint) (string, error) {
+	scope := generationScope(userID)
+	tokens, err := s.cacheRepo.FindGenerations(ctx, []string{permissionGenerationAll, scope})
+	if err != nil {
+		return "", err
+	}
+	return joinGenerations(tokens[permissionGenerationAll], tokens[scope]), nil
+}
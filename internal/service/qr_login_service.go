@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go-api-starter/internal/config"
+	"go-api-starter/internal/model"
+	"go-api-starter/pkg/apperrors"
+	"go-api-starter/pkg/cache"
+	"go-api-starter/pkg/i18n"
+)
+
+const (
+	qrSessionPrefix = "qr:session:"
+	qrScanPrefix    = "qr:scan:"
+	qrDecidePrefix  = "qr:decide:"
+	qrIssuePrefix   = "qr:issue:"
+	// 过期后再保留一段时间，桌面端轮询看到的是 expired 而不是 not found
+	qrExpiredGrace = time.Minute
+)
+
+// QRLoginService runs the cross-device QR login state machine in the cache.
+// Each transition is claimed with an atomic counter so that two devices racing
+// on the same QR code cannot both win.
+type QRLoginService struct {
+	cache  cache.CacheBackend
+	config config.QRLoginConfig
+}
+
+// NewQRLoginService creates a new QRLoginService
+func NewQRLoginService(cacheBackend cache.CacheBackend, cfg config.QRLoginConfig) *QRLoginService {
+	if cfg.TTL <= 0 {
+		cfg.TTL = 2 * time.Minute
+	}
+	return &QRLoginService{cache: cacheBackend, config: cfg}
+}
+
+// Create starts a QR login for the desktop client
+func (s *QRLoginService) Create(ctx context.Context, client model.ClientInfo) (*model.QRCreateResponse, error) {
+	key := model.GenerateSecUID()
+	pollToken := model.GenerateSecUID()
+
+	now := time.Now()
+	session := &model.QRLoginSession{
+		Status:        model.QRStatusPending,
+		PollTokenHash: hashToken(pollToken),
+		Client:        model.ClientInfo{IP: client.IP, UserAgent: truncate(client.UserAgent, 512)},
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(s.config.TTL),
+	}
+	if err := s.save(ctx, key, session); err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrCreateQRFailed)
+	}
+
+	return &model.QRCreateResponse{Key: key, PollToken: pollToken, ExpiresIn: int64(s.config.TTL.Seconds())}, nil
+}
+
+// Poll returns the state for the desktop holding the poll token. A confirmed
+// session is handed out exactly once and then removed; the caller issues the tokens.
+func (s *QRLoginService) Poll(ctx context.Context, key, pollToken string) (*model.QRLoginSession, error) {
+	session, err := s.load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(session.PollTokenHash), []byte(hashToken(pollToken))) != 1 {
+		return nil, apperrors.ForbiddenCode(i18n.ErrQRNoPermission)
+	}
+	if session.Status != model.QRStatusConfirmed {
+		return session, nil
+	}
+
+	claimed, err := s.cache.IncrWithExpire(ctx, qrIssuePrefix+key, s.config.TTL+qrExpiredGrace)
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrQRStoreFailed)
+	}
+	if claimed > 1 {
+		return nil, apperrors.NotFoundCode(i18n.ErrQRNotFound)
+	}
+	_ = s.cache.Delete(ctx, qrSessionPrefix+key)
+	return session, nil
+}
+
+// Scan binds the QR code to the mobile user and returns the requesting device for display
+func (s *QRLoginService) Scan(ctx context.Context, key string, userID uint) (*model.QRScanResponse, error) {
+	session, err := s.load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch session.Status {
+	case model.QRStatusPending:
+		claimed, err := s.cache.IncrWithExpire(ctx, qrScanPrefix+key, s.config.TTL+qrExpiredGrace)
+		if err != nil {
+			return nil, apperrors.InternalCode(err, i18n.ErrQRStoreFailed)
+		}
+		if claimed > 1 {
+			// 另一台设备抢先扫码
+			return nil, apperrors.ConflictCode(i18n.ErrQRInvalidState)
+		}
+		session.Status = model.QRStatusScanned
+		session.UserID = userID
+		if err := s.save(ctx, key, session); err != nil {
+			return nil, apperrors.InternalCode(err, i18n.ErrQRStoreFailed)
+		}
+	case model.QRStatusScanned:
+		// 同一用户重复扫码是幂等的
+		if session.UserID != userID {
+			return nil, apperrors.ConflictCode(i18n.ErrQRInvalidState)
+		}
+	case model.QRStatusExpired:
+		return nil, apperrors.BadRequestCode(i18n.ErrQRExpired)
+	default:
+		return nil, apperrors.ConflictCode(i18n.ErrQRInvalidState)
+	}
+
+	return &model.QRScanResponse{
+		Status:    session.Status,
+		IP:        session.Client.IP,
+		UserAgent: session.Client.UserAgent,
+		CreatedAt: session.CreatedAt,
+	}, nil
+}
+
+// Confirm approves the login on the desktop; only the user who scanned may confirm
+func (s *QRLoginService) Confirm(ctx context.Context, key string, userID uint) error {
+	return s.decide(ctx, key, userID, model.QRStatusConfirmed)
+}
+
+// Cancel rejects the login on the desktop
+func (s *QRLoginService) Cancel(ctx context.Context, key string, userID uint) error {
+	return s.decide(ctx, key, userID, model.QRStatusCancelled)
+}
+
+func (s *QRLoginService) decide(ctx context.Context, key string, userID uint, status string) error {
+	session, err := s.load(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	switch session.Status {
+	case model.QRStatusScanned:
+	case model.QRStatusPending:
+		return apperrors.BadRequestCode(i18n.ErrQRScanFirst)
+	case model.QRStatusExpired:
+		return apperrors.BadRequestCode(i18n.ErrQRExpired)
+	default:
+		return apperrors.ConflictCode(i18n.ErrQRInvalidState)
+	}
+	if session.UserID != userID {
+		return apperrors.ForbiddenCode(i18n.ErrQRNoPermission)
+	}
+
+	claimed, err := s.cache.IncrWithExpire(ctx, qrDecidePrefix+key, s.config.TTL+qrExpiredGrace)
+	if err != nil {
+		return apperrors.InternalCode(err, i18n.ErrQRStoreFailed)
+	}
+	if claimed > 1 {
+		return apperrors.ConflictCode(i18n.ErrQRInvalidState)
+	}
+
+	session.Status = status
+	if err := s.save(ctx, key, session); err != nil {
+		return apperrors.InternalCode(err, i18n.ErrQRStoreFailed)
+	}
+	return nil
+}
+
+// load reads a session; pending or scanned sessions past their expiry are reported as expired
+func (s *QRLoginService) load(ctx context.Context, key string) (*model.QRLoginSession, error) {
+	data, err := s.cache.Get(ctx, qrSessionPrefix+key)
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return nil, apperrors.NotFoundCode(i18n.ErrQRNotFound)
+	}
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrQRStoreFailed)
+	}
+
+	var session model.QRLoginSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrQRStoreFailed)
+	}
+	if (session.Status == model.QRStatusPending || session.Status == model.QRStatusScanned) &&
+		time.Now().After(session.ExpiresAt) {
+		session.Status = model.QRStatusExpired
+	}
+	return &session, nil
+}
+
+// save writes a session, keeping it for the grace period after it expires
+func (s *QRLoginService) save(ctx context.Context, key string, session *model.QRLoginSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(session.ExpiresAt) + qrExpiredGrace
+	if ttl <= 0 {
+		ttl = time.Second
+	}
+	return s.cache.Set(ctx, qrSessionPrefix+key, data, ttl)
+}
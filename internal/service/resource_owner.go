@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go-api-starter/internal/repository"
+	"go-api-starter/pkg/apperrors"
+	"go-api-starter/pkg/i18n"
+)
+
+// FileOwnerResolver resolves a file sec_uid to the user who uploaded it
+type FileOwnerResolver struct {
+	fileRepo repository.FileRepositoryInterface
+}
+
+// NewFileOwnerResolver creates a new FileOwnerResolver
+func NewFileOwnerResolver(fileRepo repository.FileRepositoryInterface) *FileOwnerResolver {
+	return &FileOwnerResolver{fileRepo: fileRepo}
+}
+
+// ResourceOwner returns the ID of the file's uploader
+func (r *FileOwnerResolver) ResourceOwner(ctx context.Context, secUID string) (uint, error) {
+	file, err := r.fileRepo.FindBySecUID(ctx, secUID)
+	if err != nil {
+		if errors.Is(err, repository.ErrFileNotFound) {
+			return 0, apperrors.NotFound("file not found")
+		}
+		return 0, apperrors.Wrap(err, "failed to get file")
+	}
+	return file.UserID, nil
+}
+
+// UserOwnerResolver resolves a user sec_uid to the user itself: every account owns itself
+type UserOwnerResolver struct {
+	userRepo repository.UserRepositoryInterface
+}
+
+// NewUserOwnerResolver creates a new UserOwnerResolver
+func NewUserOwnerResolver(userRepo repository.UserRepositoryInterface) *UserOwnerResolver {
+	return &UserOwnerResolver{userRepo: userRepo}
+}
+
+// ResourceOwner returns the ID of the user
+func (r *UserOwnerResolver) ResourceOwner(ctx context.Context, secUID string) (uint, error) {
+	user, err := r.userRepo.FindBySecUID(ctx, secUID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return 0, apperrors.NotFoundCode(i18n.ErrUserNotFound)
+		}
+		return 0, apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+	return user.ID, nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go-api-starter/internal/config"
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/repository"
+)
+
+// RoleExpiryService deletes role assignments whose expires_at has passed. Permission checks
+// ignore such assignments already; the sweep keeps user_roles tidy and leaves an audit trail.
+type RoleExpiryService struct {
+	userRoleRepo  repository.UserRoleRepositoryInterface
+	permCache     *PermissionCache
+	audit         *AuditService
+	sweepInterval time.Duration
+}
+
+// NewRoleExpiryService creates a new RoleExpiryService
+func NewRoleExpiryService(
+	userRoleRepo repository.UserRoleRepositoryInterface,
+	permCache *PermissionCache,
+	audit *AuditService,
+	cfg config.RoleExpiryConfig,
+) *RoleExpiryService {
+	interval := cfg.SweepInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &RoleExpiryService{
+		userRoleRepo:  userRoleRepo,
+		permCache:     permCache,
+		audit:         audit,
+		sweepInterval: interval,
+	}
+}
+
+// SweepExpired deletes expired role assignments and returns how many it deleted. Assignments
+// an administrator renewed or removed during the sweep are left alone.
+func (s *RoleExpiryService) SweepExpired(ctx context.Context) (int, error) {
+	userRoles, err := sweepExpired(ctx, s.userRoleRepo.FindExpired, func(ctx context.Context, ur *model.UserRole, now time.Time) (bool, error) {
+		return s.userRoleRepo.DeleteExpired(ctx, ur.ID, now)
+	})
+
+	userIDs := make([]uint, 0, len(userRoles))
+	for _, ur := range userRoles {
+		userIDs = append(userIDs, ur.UserID)
+
+		metadata := map[string]any{
+			"role_id":    ur.RoleID,
+			"expires_at": ur.ExpiresAt.UTC().Format(time.RFC3339),
+		}
+		if ur.Role != nil {
+			metadata["role"] = ur.Role.Name
+		}
+		if ur.StartsAt != nil {
+			metadata["starts_at"] = ur.StartsAt.UTC().Format(time.RFC3339)
+		}
+		s.audit.Record(ctx, &model.AuditLog{
+			Action:   model.AuditActionRoleExpired,
+			UserID:   &ur.UserID,
+			Metadata: metadata,
+		})
+	}
+	if invalidateErr := s.permCache.InvalidateUsers(ctx, userIDs); err == nil {
+		err = invalidateErr
+	}
+	return len(userIDs), err
+}
+
+// RunSweeper calls SweepExpired every sweep interval until ctx is cancelled
+func (s *RoleExpiryService) RunSweeper(ctx context.Context) {
+	runSweeper(ctx, s.sweepInterval, "expired role assignments", s.SweepExpired)
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/repository"
+)
+
+// roleGraph maps every role ID to its parent role ID (nil for top-level roles).
+// Roles are few, so the whole hierarchy is loaded in one query per calculation.
+type roleGraph map[uint]*uint
+
+func loadRoleGraph(ctx context.Context, roleRepo repository.RoleRepositoryInterface) (roleGraph, error) {
+	roles, err := roleRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	g := make(roleGraph, len(roles))
+	for _, r := range roles {
+		g[r.ID] = r.ParentID
+	}
+	return g, nil
+}
+
+// withAncestors returns roleIDs followed by every role they inherit from, each once.
+// The seen set also stops the walk should the stored hierarchy ever contain a loop.
+func (g roleGraph) withAncestors(roleIDs []uint) []uint {
+	seen := make(map[uint]bool)
+	result := make([]uint, 0, len(roleIDs))
+	for _, id := range roleIDs {
+		for !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+			parent := g[id]
+			if parent == nil {
+				break
+			}
+			id = *parent
+		}
+	}
+	return result
+}
+
+// withDescendants returns roleID and every role inheriting from it, directly or not
+func (g roleGraph) withDescendants(roleID uint) []uint {
+	children := make(map[uint][]uint)
+	for id, parent := range g {
+		if parent != nil {
+			children[*parent] = append(children[*parent], id)
+		}
+	}
+	seen := map[uint]bool{roleID: true}
+	result := []uint{roleID}
+	for i := 0; i < len(result); i++ {
+		for _, child := range children[result[i]] {
+			if !seen[child] {
+				seen[child] = true
+				result = append(result, child)
+			}
+		}
+	}
+	return result
+}
+
+// createsCycle reports whether making parentID the parent of roleID would close a loop,
+// i.e. whether roleID is parentID itself or one of its ancestors.
+func (g roleGraph) createsCycle(roleID, parentID uint) bool {
+	for _, id := range g.withAncestors([]uint{parentID}) {
+		if id == roleID {
+			return true
+		}
+	}
+	return false
+}
+
+// effectiveRoleIDs returns the roles currently assigned to a user plus every role they
+// inherit from. changesAt is the next time one of the assignments starts or ends, after
+// which the result is stale; it is zero when none is scheduled.
+func effectiveRoleIDs(ctx context.Context, userRoleRepo repository.UserRoleRepositoryInterface, roleRepo repository.RoleRepositoryInterface, userID uint) (roleIDs []uint, changesAt time.Time, err error) {
+	userRoles, err := userRoleRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	roleIDs, changesAt = activeRoleIDs(userRoles, time.Now())
+	if len(roleIDs) == 0 {
+		return nil, changesAt, nil
+	}
+	g, err := loadRoleGraph(ctx, roleRepo)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return g.withAncestors(roleIDs), changesAt, nil
+}
+
+// activeRoleIDs picks the assignments in effect at now and the earliest future start or
+// expiry among all of them
+func activeRoleIDs(userRoles []model.UserRole, now time.Time) ([]uint, time.Time) {
+	var changesAt time.Time
+	next := func(t *time.Time) {
+		if t != nil && t.After(now) && (changesAt.IsZero() || t.Before(changesAt)) {
+			changesAt = *t
+		}
+	}
+	roleIDs := make([]uint, 0, len(userRoles))
+	for i := range userRoles {
+		ur := &userRoles[i]
+		next(ur.StartsAt)
+		next(ur.ExpiresAt)
+		if ur.ActiveAt(now) {
+			roleIDs = append(roleIDs, ur.RoleID)
+		}
+	}
+	return roleIDs, changesAt
+}
+
+// roleTreeUserIDs returns the users of roleID and of all its descendant roles, i.e.
+// everyone whose effective permissions change when those of roleID do
+func roleTreeUserIDs(ctx context.Context, roleRepo repository.RoleRepositoryInterface, userRoleRepo repository.UserRoleRepositoryInterface, roleID uint) ([]uint, error) {
+	g, err := loadRoleGraph(ctx, roleRepo)
+	if err != nil {
+		return nil, err
+	}
+	return userRoleRepo.GetUserIDsByRoleIDs(ctx, g.withDescendants(roleID))
+}
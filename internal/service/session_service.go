@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/repository"
+	"go-api-starter/pkg/apperrors"
+	"go-api-starter/pkg/i18n"
+
+	"github.com/google/uuid"
+)
+
+// sessionTouchInterval throttles last-seen updates made on authenticated requests
+const sessionTouchInterval = time.Minute
+
+// SessionService manages per-device login sessions and the refresh-token family each one owns
+type SessionService struct {
+	sessionRepo      repository.SessionRepositoryInterface
+	refreshTokenRepo repository.RefreshTokenRepositoryInterface
+}
+
+// NewSessionService creates a new SessionService
+func NewSessionService(sessionRepo repository.SessionRepositoryInterface, refreshTokenRepo repository.RefreshTokenRepositoryInterface) *SessionService {
+	return &SessionService{
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+	}
+}
+
+// Start creates a session with a new refresh-token family
+func (s *SessionService) Start(ctx context.Context, userID uint, client model.ClientInfo, expiresAt time.Time) (*model.Session, error) {
+	now := time.Now()
+	session := &model.Session{
+		UserID:     userID,
+		FamilyID:   uuid.NewString(),
+		UserAgent:  truncate(client.UserAgent, 512),
+		IP:         client.IP,
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt,
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrGenerateTokenFailed)
+	}
+	return session, nil
+}
+
+// FindByFamily returns the session owning a refresh-token family
+func (s *SessionService) FindByFamily(ctx context.Context, familyID string) (*model.Session, error) {
+	session, err := s.sessionRepo.FindByFamilyID(ctx, familyID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return nil, apperrors.UnauthorizedCode(i18n.ErrInvalidRefreshToken)
+		}
+		return nil, apperrors.InternalCode(err, i18n.ErrQuerySessionFailed)
+	}
+	return session, nil
+}
+
+// FamilyActive reports whether the session owning a refresh-token family is still usable
+func (s *SessionService) FamilyActive(ctx context.Context, familyID string) (bool, error) {
+	session, err := s.sessionRepo.FindByFamilyID(ctx, familyID)
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, apperrors.InternalCode(err, i18n.ErrQuerySessionFailed)
+	}
+	return session.IsActive(), nil
+}
+
+// Extend records a refresh on the session and moves its expiry to the new refresh token's
+func (s *SessionService) Extend(ctx context.Context, session *model.Session, expiresAt time.Time) error {
+	if err := s.sessionRepo.Touch(ctx, session.ID, time.Now(), expiresAt); err != nil {
+		return apperrors.InternalCode(err, i18n.ErrGenerateTokenFailed)
+	}
+	return nil
+}
+
+// IsActive reports whether the session referenced by an access token is still usable
+// and bumps its last-seen time at most once per sessionTouchInterval
+func (s *SessionService) IsActive(ctx context.Context, userID uint, sessionID string) (bool, error) {
+	session, err := s.findOwned(ctx, userID, sessionID)
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, apperrors.InternalCode(err, i18n.ErrQuerySessionFailed)
+	}
+	if !session.IsActive() {
+		return false, nil
+	}
+
+	if time.Since(session.LastSeenAt) >= sessionTouchInterval {
+		_ = s.sessionRepo.Touch(ctx, session.ID, time.Now(), time.Time{})
+	}
+	return true, nil
+}
+
+// List returns the active sessions of a user
+func (s *SessionService) List(ctx context.Context, userID uint) ([]model.Session, error) {
+	sessions, err := s.sessionRepo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrQuerySessionFailed)
+	}
+	return sessions, nil
+}
+
+// Revoke revokes one of the user's sessions together with its refresh tokens
+func (s *SessionService) Revoke(ctx context.Context, userID uint, sessionID string) error {
+	session, err := s.findOwned(ctx, userID, sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return apperrors.NotFoundCode(i18n.ErrSessionNotFound)
+		}
+		return apperrors.InternalCode(err, i18n.ErrQuerySessionFailed)
+	}
+	return s.revoke(ctx, session)
+}
+
+// End revokes the session an access token belongs to; a missing session is not an error
+func (s *SessionService) End(ctx context.Context, userID uint, sessionID string) error {
+	session, err := s.findOwned(ctx, userID, sessionID)
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return apperrors.InternalCode(err, i18n.ErrQuerySessionFailed)
+	}
+	return s.revoke(ctx, session)
+}
+
+// RevokeFamily revokes the session that owns a refresh-token family, used on token reuse
+func (s *SessionService) RevokeFamily(ctx context.Context, familyID string) error {
+	session, err := s.sessionRepo.FindByFamilyID(ctx, familyID)
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		// 没有对应会话时仍然吊销令牌家族
+		if err := s.refreshTokenRepo.RevokeFamily(ctx, familyID); err != nil {
+			return apperrors.InternalCode(err, i18n.ErrRevokeSessionsFailed)
+		}
+		return nil
+	}
+	if err != nil {
+		return apperrors.InternalCode(err, i18n.ErrQuerySessionFailed)
+	}
+	return s.revoke(ctx, session)
+}
+
+// RevokeAll revokes every session and refresh token of a user
+func (s *SessionService) RevokeAll(ctx context.Context, userID uint) error {
+	if err := s.sessionRepo.RevokeByUserID(ctx, userID); err != nil {
+		return apperrors.InternalCode(err, i18n.ErrRevokeSessionsFailed)
+	}
+	if err := s.refreshTokenRepo.RevokeByUserID(ctx, userID); err != nil {
+		return apperrors.InternalCode(err, i18n.ErrRevokeSessionsFailed)
+	}
+	return nil
+}
+
+// findOwned finds a session of the given user; other users' sessions look like missing ones
+func (s *SessionService) findOwned(ctx context.Context, userID uint, sessionID string) (*model.Session, error) {
+	session, err := s.sessionRepo.FindBySecUID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != userID {
+		return nil, repository.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (s *SessionService) revoke(ctx context.Context, session *model.Session) error {
+	if err := s.sessionRepo.Revoke(ctx, session.ID); err != nil {
+		return apperrors.InternalCode(err, i18n.ErrRevokeSessionsFailed)
+	}
+	if err := s.refreshTokenRepo.RevokeFamily(ctx, session.FamilyID); err != nil {
+		return apperrors.InternalCode(err, i18n.ErrRevokeSessionsFailed)
+	}
+	return nil
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 sequence
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go-api-starter/pkg/logger"
+)
+
+// sweepBatchSize is how many expired rows one sweep handles; the rest wait for the next one
+const sweepBatchSize = 100
+
+// sweepExpired loads up to sweepBatchSize items that expired before now and clears each one
+// with clear, a conditional write that reports false for an item changed since it was
+// loaded (renewed, or already cleared by hand). It returns the items it cleared, also
+// when it stops at an error.
+func sweepExpired[T any](
+	ctx context.Context,
+	find func(ctx context.Context, now time.Time, limit int) ([]T, error),
+	clear func(ctx context.Context, item *T, now time.Time) (bool, error),
+) ([]*T, error) {
+	now := time.Now()
+	items, err := find(ctx, now, sweepBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var cleared []*T
+	for i := range items {
+		ok, err := clear(ctx, &items[i], now)
+		if err != nil {
+			return cleared, err
+		}
+		if ok {
+			cleared = append(cleared, &items[i])
+		}
+	}
+	return cleared, nil
+}
+
+// runSweeper calls sweep every interval until ctx is cancelled and logs its outcome;
+// name describes what sweep clears, e.g. "expired freezes"
+func runSweeper(ctx context.Context, interval time.Duration, name string, sweep func(context.Context) (int, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			swept, err := sweep(ctx)
+			if logger.Log == nil {
+				continue
+			}
+			if err != nil {
+				logger.Log.Errorf("failed to sweep %s: %v", name, err)
+			} else if swept > 0 {
+				logger.Log.Infof("swept %d %s", swept, name)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
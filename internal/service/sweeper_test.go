@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSweepExpired tests that only items the conditional write cleared are returned
+func TestSweepExpired(t *testing.T) {
+	ctx := context.Background()
+	find := func(ctx context.Context, now time.Time, limit int) ([]int, error) {
+		assert.Equal(t, sweepBatchSize, limit)
+		return []int{1, 2, 3, 4}, nil
+	}
+
+	// Even items were changed since they were found
+	cleared, err := sweepExpired(ctx, find, func(ctx context.Context, item *int, now time.Time) (bool, error) {
+		return *item%2 == 1, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, cleared, 2)
+	assert.Equal(t, 1, *cleared[0])
+	assert.Equal(t, 3, *cleared[1])
+
+	// An error stops the sweep but keeps what was cleared before it
+	failed := errors.New("write failed")
+	cleared, err = sweepExpired(ctx, find, func(ctx context.Context, item *int, now time.Time) (bool, error) {
+		if *item == 3 {
+			return false, failed
+		}
+		return true, nil
+	})
+	assert.ErrorIs(t, err, failed)
+	assert.Len(t, cleared, 2)
+}
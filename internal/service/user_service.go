@@ -12,15 +12,27 @@ import (
 
 // UserService handles user business logic
 type UserService struct {
-	repo     repository.UserRepositoryInterface
-	fileRepo repository.FileRepositoryInterface
+	repo          repository.UserRepositoryInterface
+	fileRepo      repository.FileRepositoryInterface
+	loginGuard    *LoginGuard
+	audit         *AuditService
+	contactChange *ContactChangeService
 }
 
 // NewUserService creates a new UserService
-func NewUserService(repo repository.UserRepositoryInterface, fileRepo repository.FileRepositoryInterface) *UserService {
+func NewUserService(
+	repo repository.UserRepositoryInterface,
+	fileRepo repository.FileRepositoryInterface,
+	loginGuard *LoginGuard,
+	audit *AuditService,
+	contactChange *ContactChangeService,
+) *UserService {
 	return &UserService{
-		repo:     repo,
-		fileRepo: fileRepo,
+		repo:          repo,
+		fileRepo:      fileRepo,
+		loginGuard:    loginGuard,
+		audit:         audit,
+		contactChange: contactChange,
 	}
 }
 
@@ -42,7 +54,7 @@ func (s *UserService) Create(ctx context.Context, req *model.CreateUserRequest)
 	}
 
 	user := req.ToUser()
-	if err := s.repo.Create(ctx, user); err != nil {
+	if err := s.repo.CreateWithDefaultRoles(ctx, user); err != nil {
 		return nil, apperrors.Wrap(err, "failed to create user")
 	}
 	return user, nil
@@ -81,21 +93,60 @@ func (s *UserService) GetBySecUID(ctx context.Context, secUID string) (*model.Us
 	return user, nil
 }
 
-// Update updates a user
+// Update updates a user. Email and mobile are login identifiers: changing them requires
+// verifying the new address through ContactChangeService, so a different value is rejected here.
 func (s *UserService) Update(ctx context.Context, id uint, req *model.UpdateUserRequest) (*model.User, error) {
+	user, _, err := s.update(ctx, id, req, false)
+	return user, err
+}
+
+// ForceUpdate lets an administrator change any field including email and mobile without verification.
+// The old addresses are still notified and the change is audited.
+func (s *UserService) ForceUpdate(ctx context.Context, actorID, id uint, req *model.UpdateUserRequest, ip string) (*model.User, error) {
+	user, oldContacts, err := s.update(ctx, id, req, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range []string{model.ContactFieldEmail, model.ContactFieldMobile} {
+		if old, changed := oldContacts[field]; changed {
+			s.contactChange.announce(ctx, actorID, user, field, old, ip, true)
+		}
+	}
+	return user, nil
+}
+
+// update applies req to the user and returns the previous value of each contact field it changed
+func (s *UserService) update(ctx context.Context, id uint, req *model.UpdateUserRequest, force bool) (*model.User, map[string]string, error) {
 	user, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
-			return nil, apperrors.NotFound("user not found")
+			return nil, nil, apperrors.NotFound("user not found")
 		}
-		return nil, apperrors.Wrap(err, "failed to find user")
+		return nil, nil, apperrors.Wrap(err, "failed to find user")
+	}
+
+	// Contacts are compared and stored the way login looks them up. Sending the current
+	// value back unchanged is fine, even if it was stored before normalization.
+	email, mobile := normalizeContact(req.Email), normalizeContact(req.Mobile)
+	oldContacts := make(map[string]string)
+	if email != nil && *email != normalizeAccount(contactValue(user, model.ContactFieldEmail)) {
+		if !force {
+			return nil, nil, apperrors.BadRequestCode(i18n.ErrBindEmailCodeRequired)
+		}
+		oldContacts[model.ContactFieldEmail] = contactValue(user, model.ContactFieldEmail)
+	}
+	if mobile != nil && *mobile != normalizeAccount(contactValue(user, model.ContactFieldMobile)) {
+		if !force {
+			return nil, nil, apperrors.BadRequestCode(i18n.ErrBindMobileCodeRequired)
+		}
+		oldContacts[model.ContactFieldMobile] = contactValue(user, model.ContactFieldMobile)
 	}
 
 	if req.LPID != nil && *req.LPID != "" {
 		if *req.LPID != user.LPID {
 			existing, err := s.repo.FindByLPID(ctx, *req.LPID)
 			if err == nil && existing != nil && existing.ID != user.ID {
-				return nil, apperrors.ConflictCode(i18n.ErrLPIDTaken)
+				return nil, nil, apperrors.ConflictCode(i18n.ErrLPIDTaken)
 			}
 			user.LPID = *req.LPID
 		}
@@ -103,23 +154,29 @@ func (s *UserService) Update(ctx context.Context, id uint, req *model.UpdateUser
 	if req.Username != nil && *req.Username != "" {
 		user.Username = req.Username
 	}
-	if req.Mobile != nil {
-		if *req.Mobile != "" && (user.Mobile == nil || *req.Mobile != *user.Mobile) {
-			existing, err := s.repo.FindByMobile(ctx, *req.Mobile)
+	if _, changed := oldContacts[model.ContactFieldMobile]; changed {
+		user.Mobile = nil
+		if *mobile != "" {
+			existing, err := s.repo.FindByMobile(ctx, *mobile)
 			if err == nil && existing != nil && existing.ID != user.ID {
-				return nil, apperrors.ConflictCode(i18n.ErrMobileTaken)
+				return nil, nil, apperrors.ConflictCode(i18n.ErrMobileTaken)
 			}
+			user.Mobile = mobile
 		}
-		user.Mobile = req.Mobile
 	}
-	if req.Email != nil {
-		if *req.Email != "" && (user.Email == nil || *req.Email != *user.Email) {
-			existing, err := s.repo.FindByEmail(ctx, *req.Email)
+	if _, changed := oldContacts[model.ContactFieldEmail]; changed {
+		user.Email = nil
+		if *email != "" {
+			existing, err := s.repo.FindByEmail(ctx, *email)
 			if err == nil && existing != nil && existing.ID != user.ID {
-				return nil, apperrors.ConflictCode(i18n.ErrEmailTaken)
+				return nil, nil, apperrors.ConflictCode(i18n.ErrEmailTaken)
 			}
+			user.Email = email
 		}
-		user.Email = req.Email
+	}
+	// The user must keep at least one address to log in and recover the account with
+	if len(oldContacts) > 0 && user.Email == nil && user.Mobile == nil {
+		return nil, nil, apperrors.BadRequestCode(i18n.ErrMobileOrEmailRequired)
 	}
 	if req.AvatarSecUID != nil {
 		if *req.AvatarSecUID == "" {
@@ -128,7 +185,7 @@ func (s *UserService) Update(ctx context.Context, id uint, req *model.UpdateUser
 		} else if s.fileRepo != nil {
 			file, err := s.fileRepo.FindBySecUID(ctx, *req.AvatarSecUID)
 			if err != nil {
-				return nil, apperrors.BadRequest("avatar file not found: " + *req.AvatarSecUID)
+				return nil, nil, apperrors.BadRequest("avatar file not found: " + *req.AvatarSecUID)
 			}
 			user.AvatarFileID = &file.ID
 			user.AvatarFile = file
@@ -141,7 +198,7 @@ func (s *UserService) Update(ctx context.Context, id uint, req *model.UpdateUser
 		} else if s.fileRepo != nil {
 			file, err := s.fileRepo.FindBySecUID(ctx, *req.BackgroundSecUID)
 			if err != nil {
-				return nil, apperrors.BadRequest("background file not found: " + *req.BackgroundSecUID)
+				return nil, nil, apperrors.BadRequest("background file not found: " + *req.BackgroundSecUID)
 			}
 			user.BackgroundFileID = &file.ID
 			user.BackgroundFile = file
@@ -170,9 +227,18 @@ func (s *UserService) Update(ctx context.Context, id uint, req *model.UpdateUser
 	}
 
 	if err := s.repo.Update(ctx, user); err != nil {
-		return nil, apperrors.Wrap(err, "failed to update user")
+		return nil, nil, apperrors.Wrap(err, "failed to update user")
 	}
-	return user, nil
+	return user, oldContacts, nil
+}
+
+// normalizeContact returns a normalized copy of an optional email or mobile
+func normalizeContact(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	normalized := normalizeAccount(*value)
+	return &normalized
 }
 
 // Delete deletes a user by ID
@@ -186,3 +252,51 @@ func (s *UserService) Delete(ctx context.Context, id uint) error {
 	}
 	return nil
 }
+
+// Unlock lifts a login lockout on the user's email and mobile and records who did it
+func (s *UserService) Unlock(ctx context.Context, actorID uint, secUID, ip string) error {
+	user, err := s.GetBySecUID(ctx, secUID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.loginGuard.Unlock(ctx, loginAccounts(user)...); err != nil {
+		return apperrors.Wrap(err, "failed to unlock user")
+	}
+
+	s.audit.Record(ctx, &model.AuditLog{
+		Action:  model.AuditActionAccountUnlocked,
+		ActorID: &actorID,
+		UserID:  &user.ID,
+		IP:      ip,
+	})
+	return nil
+}
+
+// UnlockIP lifts a login lockout on an IP and records who did it
+func (s *UserService) UnlockIP(ctx context.Context, actorID uint, lockedIP, ip string) error {
+	if err := s.loginGuard.UnlockIP(ctx, lockedIP); err != nil {
+		return apperrors.Wrap(err, "failed to unlock ip")
+	}
+
+	s.audit.Record(ctx, &model.AuditLog{
+		Action:  model.AuditActionIPUnlocked,
+		ActorID: &actorID,
+		Subject: lockedIP,
+		IP:      ip,
+	})
+	return nil
+}
+
+// loginAccounts returns the normalized email and mobile a user can log in with,
+// which are the account keys the login guard counts failures under
+func loginAccounts(user *model.User) []string {
+	var accounts []string
+	if user.Email != nil && *user.Email != "" {
+		accounts = append(accounts, normalizeAccount(*user.Email))
+	}
+	if user.Mobile != nil && *user.Mobile != "" {
+		accounts = append(accounts, normalizeAccount(*user.Mobile))
+	}
+	return accounts
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-api-starter/internal/config"
+	"go-api-starter/internal/model"
+	"go-api-starter/pkg/cache"
+	"go-api-starter/pkg/i18n"
+)
+
+func newTestUserService(t *testing.T, users ...*model.User) (*UserService, *fakeUserRepo) {
+	memCache := cache.NewMemoryCache()
+	t.Cleanup(func() { memCache.Close() })
+	repo := newFakeUserRepo(users...)
+	audit := NewAuditService(&fakeAuditRepo{})
+	contactChange := NewContactChangeService(memCache, nil, newFakeCodeSender(), repo, audit, config.VerifyConfig{})
+	return NewUserService(repo, nil, nil, audit, contactChange), repo
+}
+
+// TestForceUpdateNormalizesContacts tests that forced contact changes are normalized before the uniqueness check
+func TestForceUpdateNormalizesContacts(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestUserService(t,
+		&model.User{ID: 1, Email: strPtr("a@example.com")},
+		&model.User{ID: 2, Email: strPtr("taken@example.com")})
+
+	_, err := svc.ForceUpdate(ctx, 9, 1, &model.UpdateUserRequest{Email: strPtr(" Taken@Example.com ")}, "")
+	assert.Equal(t, i18n.ErrEmailTaken, errorCode(err))
+
+	user, err := svc.ForceUpdate(ctx, 9, 1, &model.UpdateUserRequest{Email: strPtr(" New@Example.com ")}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", *user.Email)
+
+	// Sending the stored address back in another case is not a change
+	_, err = svc.Update(ctx, 1, &model.UpdateUserRequest{Email: strPtr("NEW@example.com")})
+	assert.NoError(t, err)
+}
+
+// TestForceUpdateKeepsOneContact tests that a forced update cannot remove the last login identifier
+func TestForceUpdateKeepsOneContact(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newTestUserService(t,
+		&model.User{ID: 1, Email: strPtr("a@example.com"), Mobile: strPtr("13800000000")})
+
+	_, err := svc.ForceUpdate(ctx, 9, 1, &model.UpdateUserRequest{Email: strPtr(""), Mobile: strPtr("")}, "")
+	assert.Equal(t, i18n.ErrMobileOrEmailRequired, errorCode(err))
+	stored, err := repo.FindByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "a@example.com", *stored.Email)
+
+	// Clearing one of the two is allowed, clearing the remaining one is not
+	user, err := svc.ForceUpdate(ctx, 9, 1, &model.UpdateUserRequest{Email: strPtr("")}, "")
+	require.NoError(t, err)
+	assert.Nil(t, user.Email)
+	_, err = svc.ForceUpdate(ctx, 9, 1, &model.UpdateUserRequest{Mobile: strPtr("")}, "")
+	assert.Equal(t, i18n.ErrMobileOrEmailRequired, errorCode(err))
+}
+
+// TestUpdateKeepsLegacyMixedCaseEmail tests that re-sending an address stored before normalization is not a change
+func TestUpdateKeepsLegacyMixedCaseEmail(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestUserService(t,
+		&model.User{ID: 1, Email: strPtr("Legacy@Example.com"), Username: strPtr("old")})
+
+	user, err := svc.Update(ctx, 1, &model.UpdateUserRequest{Email: strPtr("Legacy@Example.com"), Username: strPtr("new")})
+	require.NoError(t, err)
+	assert.Equal(t, "new", *user.Username)
+	assert.Equal(t, "Legacy@Example.com", *user.Email)
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"go-api-starter/internal/config"
+	"go-api-starter/internal/model"
+	"go-api-starter/internal/repository"
+	"go-api-starter/pkg/apperrors"
+	"go-api-starter/pkg/cache"
+	"go-api-starter/pkg/i18n"
+)
+
+const (
+	verifyCodePrefix     = "verify:code:"
+	verifyAttemptsPrefix = "verify:attempts:"
+	verifyUsedPrefix     = "verify:used:"
+	verifyCooldownPrefix = "verify:cooldown:"
+	verifyAccountPrefix  = "verify:limit:account:"
+	verifyIPPrefix       = "verify:limit:ip:"
+	verifyCodeLength     = 6
+)
+
+// VerificationCodeService issues and checks one-time verification codes.
+// Codes are stored hashed in the cache backend and consumed on first successful use.
+type VerificationCodeService struct {
+	cache    cache.CacheBackend
+	sender   CodeSender
+	userRepo repository.UserRepositoryInterface
+	config   config.VerifyConfig
+}
+
+// NewVerificationCodeService creates a new VerificationCodeService
+func NewVerificationCodeService(cacheBackend cache.CacheBackend, sender CodeSender, userRepo repository.UserRepositoryInterface, cfg config.VerifyConfig) *VerificationCodeService {
+	return &VerificationCodeService{
+		cache:    cacheBackend,
+		sender:   sender,
+		userRepo: userRepo,
+		config:   cfg,
+	}
+}
+
+// normalizeAccount trims the account and lowercases emails so keys are stable
+func normalizeAccount(account string) string {
+	account = strings.TrimSpace(account)
+	if strings.Contains(account, "@") {
+		return strings.ToLower(account)
+	}
+	return account
+}
+
+// isValidMobile reports whether s looks like an 11-digit mobile number
+func isValidMobile(s string) bool {
+	if len(s) != 11 {
+		return false
+	}
+	for _, ch := range s {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *VerificationCodeService) codeKey(purpose, account string) string {
+	return verifyCodePrefix + purpose + ":" + account
+}
+
+func (s *VerificationCodeService) attemptsKey(purpose, account string) string {
+	return verifyAttemptsPrefix + purpose + ":" + account
+}
+
+// Send generates a code for the account and delivers it through the CodeSender.
+// An account that cannot use a code for the purpose (e.g. an unknown account for login,
+// a taken one for register) gets the same response but no code, so Send does not reveal
+// which accounts exist.
+func (s *VerificationCodeService) Send(ctx context.Context, req *model.SendCodeRequest, clientIP string) (*model.SendCodeResponse, error) {
+	account := normalizeAccount(req.Account)
+	isEmail := strings.Contains(account, "@")
+	if !isEmail && !isValidMobile(account) {
+		return nil, apperrors.BadRequestCode(i18n.ErrProvideMobileOrEmail)
+	}
+
+	if err := s.checkSendLimits(ctx, account, clientIP); err != nil {
+		return nil, err
+	}
+	resp := &model.SendCodeResponse{
+		ExpiresIn:  int64(s.config.CodeTTL.Seconds()),
+		RetryAfter: int64(s.config.Cooldown.Seconds()),
+	}
+	usable, err := s.usableFor(ctx, req.Purpose, account, isEmail)
+	if err != nil {
+		return nil, err
+	}
+	if !usable {
+		return resp, nil
+	}
+
+	code, err := generateNumericCode(verifyCodeLength)
+	if err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrCodeStoreFailed)
+	}
+
+	// A new code replaces any previous one and resets its attempt counter
+	if err := s.cache.Set(ctx, s.codeKey(req.Purpose, account), []byte(hashToken(code)), s.config.CodeTTL); err != nil {
+		return nil, apperrors.InternalCode(err, i18n.ErrCodeStoreFailed)
+	}
+	_ = s.cache.Delete(ctx, s.attemptsKey(req.Purpose, account))
+
+	if err := s.sender.SendCode(ctx, account, code, req.Purpose); err != nil {
+		_ = s.cache.Delete(ctx, s.codeKey(req.Purpose, account))
+		if isEmail {
+			return nil, apperrors.InternalCode(err, i18n.ErrMailSendFailed)
+		}
+		return nil, apperrors.InternalCode(err, i18n.ErrSMSSendFailed)
+	}
+	return resp, nil
+}
+
+// usableFor reports whether a code for purpose could ever be used with the account:
+// register and change_contact need a free address, the other purposes an existing account
+func (s *VerificationCodeService) usableFor(ctx context.Context, purpose, account string, isEmail bool) (bool, error) {
+	var err error
+	if isEmail {
+		_, err = s.userRepo.FindByEmail(ctx, account)
+	} else {
+		_, err = s.userRepo.FindByMobile(ctx, account)
+	}
+	if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+		return false, apperrors.InternalCode(err, i18n.ErrQueryUserFailed)
+	}
+	exists := err == nil
+
+	switch purpose {
+	case model.CodePurposeRegister, model.CodePurposeChangeContact:
+		return !exists, nil
+	default:
+		return exists, nil
+	}
+}
+
+// checkSendLimits applies the resend cooldown and the per-account / per-IP quotas
+func (s *VerificationCodeService) checkSendLimits(ctx context.Context, account, clientIP string) error {
+	if s.config.Cooldown > 0 {
+		n, err := s.cache.IncrWithExpire(ctx, verifyCooldownPrefix+account, s.config.Cooldown)
+		if err != nil {
+			return apperrors.InternalCode(err, i18n.ErrCodeStoreFailed)
+		}
+		if n > 1 {
+			return apperrors.TooManyRequestsCode(i18n.ErrCodeRateLimit)
+		}
+	}
+
+	if s.config.AccountPerDay > 0 {
+		n, err := s.cache.IncrWithExpire(ctx, verifyAccountPrefix+account, 24*time.Hour)
+		if err != nil {
+			return apperrors.InternalCode(err, i18n.ErrCodeStoreFailed)
+		}
+		if n > int64(s.config.AccountPerDay) {
+			return apperrors.TooManyRequestsCode(i18n.ErrCodeRateLimit)
+		}
+	}
+
+	if s.config.IPPerHour > 0 && clientIP != "" {
+		n, err := s.cache.IncrWithExpire(ctx, verifyIPPrefix+clientIP, time.Hour)
+		if err != nil {
+			return apperrors.InternalCode(err, i18n.ErrCodeStoreFailed)
+		}
+		if n > int64(s.config.IPPerHour) {
+			return apperrors.TooManyRequestsCode(i18n.ErrCodeRateLimit)
+		}
+	}
+	return nil
+}
+
+// Verify checks a code for the account and purpose, consuming it on success.
+// A code is discarded after MaxAttempts wrong guesses.
+func (s *VerificationCodeService) Verify(ctx context.Context, purpose, account, code string) error {
+	if code == "" {
+		return apperrors.BadRequestCode(i18n.ErrCodeRequired)
+	}
+	account = normalizeAccount(account)
+	key := s.codeKey(purpose, account)
+
+	stored, err := s.cache.Get(ctx, key)
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return apperrors.BadRequestCode(i18n.ErrCodeExpired)
+	}
+	if err != nil {
+		return apperrors.InternalCode(err, i18n.ErrCodeStoreFailed)
+	}
+
+	if subtle.ConstantTimeCompare(stored, []byte(hashToken(code))) != 1 {
+		attempts, err := s.cache.IncrWithExpire(ctx, s.attemptsKey(purpose, account), s.config.CodeTTL)
+		if err == nil && s.config.MaxAttempts > 0 && attempts >= int64(s.config.MaxAttempts) {
+			_ = s.cache.Delete(ctx, key)
+			_ = s.cache.Delete(ctx, s.attemptsKey(purpose, account))
+		}
+		return apperrors.BadRequestCode(i18n.ErrCodeInvalid)
+	}
+
+	// Claim the code atomically so concurrent requests cannot both use it
+	claimed, err := s.cache.IncrWithExpire(ctx, verifyUsedPrefix+purpose+":"+account+":"+string(stored), s.config.CodeTTL)
+	if err != nil {
+		return apperrors.InternalCode(err, i18n.ErrCodeStoreFailed)
+	}
+	if claimed > 1 {
+		return apperrors.BadRequestCode(i18n.ErrCodeExpired)
+	}
+
+	_ = s.cache.Delete(ctx, key)
+	_ = s.cache.Delete(ctx, s.attemptsKey(purpose, account))
+	return nil
+}
+
+// generateNumericCode returns a cryptographically random numeric code of n digits
+func generateNumericCode(n int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+	v, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", n, v), nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-api-starter/internal/config"
+	"go-api-starter/internal/model"
+	"go-api-starter/pkg/cache"
+	"go-api-starter/pkg/i18n"
+)
+
+func newTestCodeService(t *testing.T, cfg config.VerifyConfig, users ...*model.User) (*VerificationCodeService, *fakeCodeSender) {
+	memCache := cache.NewMemoryCache()
+	t.Cleanup(func() { memCache.Close() })
+	sender := newFakeCodeSender()
+	return NewVerificationCodeService(memCache, sender, newFakeUserRepo(users...), cfg), sender
+}
+
+// TestSendDoesNotRevealAccounts tests that Send answers the same whether or not the account exists
+func TestSendDoesNotRevealAccounts(t *testing.T) {
+	ctx := context.Background()
+	svc, sender := newTestCodeService(t, config.VerifyConfig{CodeTTL: time.Minute},
+		&model.User{ID: 1, Email: strPtr("taken@example.com")})
+
+	// Login code for an unknown account: success, nothing sent
+	resp, err := svc.Send(ctx, &model.SendCodeRequest{Account: "nobody@example.com", Purpose: model.CodePurposeLogin}, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(60), resp.ExpiresIn)
+	_, sent := sender.code("nobody@example.com")
+	assert.False(t, sent)
+
+	// Register code for a taken account: success, nothing sent
+	_, err = svc.Send(ctx, &model.SendCodeRequest{Account: "Taken@Example.com", Purpose: model.CodePurposeRegister}, "")
+	require.NoError(t, err)
+	_, sent = sender.code("taken@example.com")
+	assert.False(t, sent)
+
+	// Login code for the existing account is sent to the normalized address
+	_, err = svc.Send(ctx, &model.SendCodeRequest{Account: "Taken@Example.com", Purpose: model.CodePurposeLogin}, "")
+	require.NoError(t, err)
+	_, sent = sender.code("taken@example.com")
+	assert.True(t, sent)
+}
+
+// TestSendFindsLegacyMixedCaseEmail tests that an address stored before normalization still gets its login code
+func TestSendFindsLegacyMixedCaseEmail(t *testing.T) {
+	ctx := context.Background()
+	svc, sender := newTestCodeService(t, config.VerifyConfig{CodeTTL: time.Minute},
+		&model.User{ID: 1, Email: strPtr("Legacy@Example.com")})
+
+	_, err := svc.Send(ctx, &model.SendCodeRequest{Account: "legacy@example.com", Purpose: model.CodePurposeLogin}, "")
+	require.NoError(t, err)
+	_, sent := sender.code("legacy@example.com")
+	assert.True(t, sent)
+}
+
+// TestSendLimits tests the resend cooldown and the per-account and per-IP quotas
+func TestSendLimits(t *testing.T) {
+	ctx := context.Background()
+	req := func(account string) *model.SendCodeRequest {
+		return &model.SendCodeRequest{Account: account, Purpose: model.CodePurposeRegister}
+	}
+
+	// Cooldown: a second send within the interval is rejected, also for unknown accounts
+	svc, _ := newTestCodeService(t, config.VerifyConfig{CodeTTL: time.Minute, Cooldown: time.Minute})
+	_, err := svc.Send(ctx, req("a@example.com"), "")
+	require.NoError(t, err)
+	_, err = svc.Send(ctx, req("a@example.com"), "")
+	assert.Equal(t, i18n.ErrCodeRateLimit, errorCode(err))
+
+	// Per-account quota
+	svc, _ = newTestCodeService(t, config.VerifyConfig{CodeTTL: time.Minute, AccountPerDay: 2})
+	for i := 0; i < 2; i++ {
+		_, err = svc.Send(ctx, req("b@example.com"), "")
+		require.NoError(t, err)
+	}
+	_, err = svc.Send(ctx, req("b@example.com"), "")
+	assert.Equal(t, i18n.ErrCodeRateLimit, errorCode(err))
+
+	// Per-IP quota across different accounts
+	svc, _ = newTestCodeService(t, config.VerifyConfig{CodeTTL: time.Minute, IPPerHour: 1})
+	_, err = svc.Send(ctx, req("c@example.com"), "203.0.113.7")
+	require.NoError(t, err)
+	_, err = svc.Send(ctx, req("d@example.com"), "203.0.113.7")
+	assert.Equal(t, i18n.ErrCodeRateLimit, errorCode(err))
+	_, err = svc.Send(ctx, req("d@example.com"), "203.0.113.8")
+	assert.NoError(t, err)
+}
+
+// TestVerifyConsumesCode tests that a code works once
+func TestVerifyConsumesCode(t *testing.T) {
+	ctx := context.Background()
+	svc, sender := newTestCodeService(t, config.VerifyConfig{CodeTTL: time.Minute, MaxAttempts: 5})
+
+	_, err := svc.Send(ctx, &model.SendCodeRequest{Account: "a@example.com", Purpose: model.CodePurposeRegister}, "")
+	require.NoError(t, err)
+	code, _ := sender.code("a@example.com")
+
+	// The code is bound to its purpose
+	err = svc.Verify(ctx, model.CodePurposeLogin, "a@example.com", code)
+	assert.Equal(t, i18n.ErrCodeExpired, errorCode(err))
+
+	assert.NoError(t, svc.Verify(ctx, model.CodePurposeRegister, "A@example.com", code))
+	err = svc.Verify(ctx, model.CodePurposeRegister, "a@example.com", code)
+	assert.Equal(t, i18n.ErrCodeExpired, errorCode(err))
+}
+
+// TestVerifyMaxAttempts tests that a code is discarded after too many wrong guesses
+func TestVerifyMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	svc, sender := newTestCodeService(t, config.VerifyConfig{CodeTTL: time.Minute, MaxAttempts: 3})
+
+	_, err := svc.Send(ctx, &model.SendCodeRequest{Account: "a@example.com", Purpose: model.CodePurposeRegister}, "")
+	require.NoError(t, err)
+	code, _ := sender.code("a@example.com")
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	for i := 0; i < 3; i++ {
+		err = svc.Verify(ctx, model.CodePurposeRegister, "a@example.com", wrong)
+		assert.Equal(t, i18n.ErrCodeInvalid, errorCode(err))
+	}
+	err = svc.Verify(ctx, model.CodePurposeRegister, "a@example.com", code)
+	assert.Equal(t, i18n.ErrCodeExpired, errorCode(err))
+}
+
+// TestVerifyExpiredCode tests that a code stops working after CodeTTL
+func TestVerifyExpiredCode(t *testing.T) {
+	ctx := context.Background()
+	svc, sender := newTestCodeService(t, config.VerifyConfig{CodeTTL: 50 * time.Millisecond})
+
+	_, err := svc.Send(ctx, &model.SendCodeRequest{Account: "a@example.com", Purpose: model.CodePurposeRegister}, "")
+	require.NoError(t, err)
+	code, _ := sender.code("a@example.com")
+
+	time.Sleep(100 * time.Millisecond)
+	err = svc.Verify(ctx, model.CodePurposeRegister, "a@example.com", code)
+	assert.Equal(t, i18n.ErrCodeExpired, errorCode(err))
+}
@@ -187,6 +187,37 @@ func ConflictCode(code string) *AppError {
 	}
 }
 
+// TooManyRequestsCode creates a 429 error from an error code
+func TooManyRequestsCode(code string) *AppError {
+	return &AppError{
+		Code:       code,
+		Message:    i18n.T(code),
+		HTTPStatus: http.StatusTooManyRequests,
+	}
+}
+
+// FieldError describes why one request field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewFieldError creates a FieldError whose message is resolved from the code
+func NewFieldError(field, code string) FieldError {
+	return FieldError{Field: field, Code: code, Message: i18n.T(code)}
+}
+
+// ValidationCode creates a 400 error from an error code with field-level details
+func ValidationCode(code string, fields ...FieldError) *AppError {
+	return &AppError{
+		Code:       code,
+		Message:    i18n.T(code),
+		HTTPStatus: http.StatusBadRequest,
+		Details:    fields,
+	}
+}
+
 // WrapCode wraps an error with an error code
 func WrapCode(err error, code string) *AppError {
 	if err == nil {
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"embed"
+	"encoding/hex"
+	"strings"
+)
+
+// BreachedPrefixLen is how many hex characters of the SHA-1 name each file in breached/.
+// Like the Have I Been Pwned range API, a lookup only opens the file for its prefix.
+const BreachedPrefixLen = 2
+
+//go:embed breached
+var breachedFS embed.FS
+
+// BreachedHash returns the uppercase SHA-1 hex digest used to index the breached password list
+func BreachedHash(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// IsBreachedPassword reports whether the password, or its lowercase form,
+// is in the bundled list of common and breached passwords
+func IsBreachedPassword(password string) bool {
+	if inBreachedList(BreachedHash(password)) {
+		return true
+	}
+	lower := strings.ToLower(password)
+	return lower != password && inBreachedList(BreachedHash(lower))
+}
+
+func inBreachedList(hash string) bool {
+	data, err := breachedFS.ReadFile("breached/" + hash[:BreachedPrefixLen])
+	if err != nil {
+		return false
+	}
+
+	suffix := []byte(hash[BreachedPrefixLen:])
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		// 每行是去掉前缀的哈希，可带 ":出现次数"
+		line, _, _ := bytes.Cut(scanner.Bytes(), []byte(":"))
+		if bytes.Equal(line, suffix) {
+			return true
+		}
+	}
+	return false
+}
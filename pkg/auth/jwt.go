@@ -2,9 +2,11 @@ package auth
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 var (
@@ -15,24 +17,42 @@ var (
 )
 
 const (
-	TokenTypeAccess  = "access"
-	TokenTypeRefresh = "refresh"
+	TokenTypeAccess     = "access"
+	TokenTypeRefresh    = "refresh"
+	TokenTypeMFAPending = "mfa_pending" // 密码已验证、等待第二因素的临时令牌
 )
 
-// TokenConfig holds JWT token configuration
+// TokenConfig holds JWT token configuration.
+// When Keys is nil tokens are signed with HS256 and Secret.
 type TokenConfig struct {
 	Secret               string
 	AccessTokenDuration  time.Duration
 	RefreshTokenDuration time.Duration
+	Keys                 *KeySet
 }
 
 // Claims represents JWT claims
 type Claims struct {
 	UserID    uint   `json:"user_id"`
 	TokenType string `json:"token_type"`
+	SessionID string `json:"sid,omitempty"`       // 所属登录会话
+	Act       *Actor `json:"act,omitempty"`       // 模拟登录时的实际操作人
+	ClientID  string `json:"client_id,omitempty"` // client_credentials 令牌的 OAuth 客户端，此时 UserID 为 0
+	Scope     string `json:"scope,omitempty"`     // 客户端令牌获准的权限码，空格分隔
 	jwt.RegisteredClaims
 }
 
+// Scopes returns the permission codes in the scope claim
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// Actor identifies who is acting on behalf of the token's user (RFC 8693 "act" claim)
+type Actor struct {
+	Subject string `json:"sub"` // 操作人的 sec_uid
+	UserID  uint   `json:"user_id"`
+}
+
 // JWTManager handles JWT token operations
 type JWTManager struct {
 	config TokenConfig
@@ -64,11 +84,51 @@ func NewJWTManagerWithConfig(config TokenConfig) *JWTManager {
 	}
 }
 
+// WithKeySet returns a copy of the manager that signs and verifies with the asymmetric key set
+func (m *JWTManager) WithKeySet(keys *KeySet) *JWTManager {
+	config := m.config
+	config.Keys = keys
+	return &JWTManager{config: config}
+}
+
 // GenerateAccessToken generates an access token for a user
 func (m *JWTManager) GenerateAccessToken(userID uint) (string, error) {
 	return m.generateToken(userID, TokenTypeAccess, m.config.AccessTokenDuration)
 }
 
+// GenerateSessionAccessToken generates an access token bound to a login session
+func (m *JWTManager) GenerateSessionAccessToken(userID uint, sessionID string) (string, error) {
+	claims := m.newClaims(userID, TokenTypeAccess, m.config.AccessTokenDuration)
+	claims.SessionID = sessionID
+	return m.sign(claims)
+}
+
+// IssueImpersonationToken generates an access token for userID that carries the actor in its
+// "act" claim. It is not bound to a session and no refresh token is issued for it.
+func (m *JWTManager) IssueImpersonationToken(userID uint, actor Actor, duration time.Duration) (string, *Claims, error) {
+	claims := m.newClaims(userID, TokenTypeAccess, duration)
+	claims.Act = &actor
+	token, err := m.sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// IssueClientToken generates an access token for an OAuth client (client_credentials grant).
+// It names no user and no session, so it cannot be refreshed; scope lists the permission codes granted.
+func (m *JWTManager) IssueClientToken(clientID string, scope []string, duration time.Duration) (string, *Claims, error) {
+	claims := m.newClaims(0, TokenTypeAccess, duration)
+	claims.Subject = clientID
+	claims.ClientID = clientID
+	claims.Scope = strings.Join(scope, " ")
+	token, err := m.sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
 // GenerateRefreshToken generates a refresh token for a user
 func (m *JWTManager) GenerateRefreshToken(userID uint) (string, error) {
 	return m.generateToken(userID, TokenTypeRefresh, m.config.RefreshTokenDuration)
@@ -94,32 +154,96 @@ func (m *JWTManager) AccessTokenExpiresIn() int64 {
 	return int64(m.config.AccessTokenDuration.Seconds())
 }
 
+// RefreshTokenExpiresIn returns the refresh token duration in seconds
+func (m *JWTManager) RefreshTokenExpiresIn() int64 {
+	return int64(m.config.RefreshTokenDuration.Seconds())
+}
+
+// IssueRefreshToken generates a refresh token and returns its claims,
+// so callers can persist the token ID (jti) and expiry for rotation tracking
+func (m *JWTManager) IssueRefreshToken(userID uint) (string, *Claims, error) {
+	claims := m.newClaims(userID, TokenTypeRefresh, m.config.RefreshTokenDuration)
+	token, err := m.sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// GenerateMFAToken generates a short-lived mfa_pending token that can only be
+// exchanged for real tokens together with a second-factor code
+func (m *JWTManager) GenerateMFAToken(userID uint, duration time.Duration) (string, error) {
+	return m.generateToken(userID, TokenTypeMFAPending, duration)
+}
+
 // generateToken generates a JWT token with specified type and duration
 func (m *JWTManager) generateToken(userID uint, tokenType string, duration time.Duration) (string, error) {
+	return m.sign(m.newClaims(userID, tokenType, duration))
+}
+
+// newClaims builds claims with a unique token ID (jti)
+func (m *JWTManager) newClaims(userID uint, tokenType string, duration time.Duration) *Claims {
 	now := time.Now()
-	claims := Claims{
+	return &Claims{
 		UserID:    userID,
 		TokenType: tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
+}
 
+// sign signs the claims with the configured secret, or with the signing key (and its kid) of the key set
+func (m *JWTManager) sign(claims *Claims) (string, error) {
+	if ks := m.config.Keys; ks != nil {
+		token := jwt.NewWithClaims(ks.method, claims)
+		token.Header["kid"] = ks.signingKID
+		return token.SignedString(ks.signingKey)
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(m.config.Secret))
 }
 
-// ValidateToken validates a JWT token and returns the claims
-func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verify signing method
+// Algorithm returns the JWS algorithm used to sign tokens
+func (m *JWTManager) Algorithm() string {
+	if m.config.Keys != nil {
+		return m.config.Keys.Algorithm()
+	}
+	return AlgHS256
+}
+
+// JWKS returns the public verification keys; it is empty in HS256 mode
+func (m *JWTManager) JWKS() JWKS {
+	if m.config.Keys == nil {
+		return JWKS{Keys: []JWK{}}
+	}
+	return m.config.Keys.JWKS()
+}
+
+// keyFunc resolves the verification key. Only the configured algorithm is accepted,
+// and asymmetric tokens must name a known key in their kid header.
+func (m *JWTManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	ks := m.config.Keys
+	if ks == nil {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, ErrInvalidSigningMethod
 		}
 		return []byte(m.config.Secret), nil
-	})
+	}
+
+	if token.Method.Alg() != ks.method.Alg() {
+		return nil, ErrInvalidSigningMethod
+	}
+	kid, _ := token.Header["kid"].(string)
+	return ks.verificationKey(kid)
+}
+
+// ValidateToken validates a JWT token and returns the claims
+func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, m.keyFunc, jwt.WithValidMethods([]string{m.Algorithm()}))
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -168,6 +292,20 @@ func (m *JWTManager) ValidateRefreshToken(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
+// ValidateMFAToken validates an mfa_pending token
+func (m *JWTManager) ValidateMFAToken(tokenString string) (*Claims, error) {
+	claims, err := m.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != TokenTypeMFAPending {
+		return nil, ErrInvalidTokenType
+	}
+
+	return claims, nil
+}
+
 // RefreshAccessToken generates a new access token from a valid refresh token
 func (m *JWTManager) RefreshAccessToken(refreshToken string) (string, error) {
 	claims, err := m.ValidateRefreshToken(refreshToken)
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrSigningKeyNotFound   = errors.New("signing key not found")
+	ErrUnknownKeyID         = errors.New("unknown key id")
+)
+
+// KeyFile points to the PEM files of one key.
+// PrivateKeyFile is required for the signing key; retired keys only need PublicKeyFile.
+type KeyFile struct {
+	ID             string
+	PrivateKeyFile string
+	PublicKeyFile  string
+}
+
+// KeySet holds the asymmetric keys of a JWTManager: one key signs new tokens,
+// every key in the set verifies tokens, so old keys keep working until removed.
+type KeySet struct {
+	method     jwt.SigningMethod
+	signingKID string
+	signingKey crypto.Signer
+	publicKeys map[string]crypto.PublicKey
+	order      []string
+}
+
+// LoadKeySet reads PEM keys for RS256 or EdDSA. signingKID selects the key that signs new tokens.
+func LoadKeySet(alg, signingKID string, files []KeyFile) (*KeySet, error) {
+	var method jwt.SigningMethod
+	switch alg {
+	case AlgRS256:
+		method = jwt.SigningMethodRS256
+	case AlgEdDSA:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, alg)
+	}
+
+	ks := &KeySet{
+		method:     method,
+		signingKID: signingKID,
+		publicKeys: make(map[string]crypto.PublicKey, len(files)),
+	}
+	for _, f := range files {
+		if f.ID == "" {
+			return nil, errors.New("jwt key id must not be empty")
+		}
+		if _, dup := ks.publicKeys[f.ID]; dup {
+			return nil, fmt.Errorf("duplicate jwt key id %q", f.ID)
+		}
+
+		var pub crypto.PublicKey
+		if f.PrivateKeyFile != "" {
+			priv, err := loadPrivateKey(alg, f.PrivateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("jwt key %q: %w", f.ID, err)
+			}
+			pub = priv.Public()
+			if f.ID == signingKID {
+				ks.signingKey = priv
+			}
+		} else {
+			var err error
+			if pub, err = loadPublicKey(alg, f.PublicKeyFile); err != nil {
+				return nil, fmt.Errorf("jwt key %q: %w", f.ID, err)
+			}
+		}
+		ks.publicKeys[f.ID] = pub
+		ks.order = append(ks.order, f.ID)
+	}
+
+	if ks.signingKey == nil {
+		return nil, fmt.Errorf("%w: %q needs a private_key_file", ErrSigningKeyNotFound, signingKID)
+	}
+	return ks, nil
+}
+
+// Algorithm returns the JWS algorithm name of the set
+func (ks *KeySet) Algorithm() string {
+	return ks.method.Alg()
+}
+
+// verificationKey returns the public key for a kid
+func (ks *KeySet) verificationKey(kid string) (crypto.PublicKey, error) {
+	key, ok := ks.publicKeys[kid]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	return key, nil
+}
+
+func loadPrivateKey(alg, path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if alg == AlgRS256 {
+		return jwt.ParseRSAPrivateKeyFromPEM(data)
+	}
+	key, err := jwt.ParseEdPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an Ed25519 private key")
+	}
+	return signer, nil
+}
+
+func loadPublicKey(alg, path string) (crypto.PublicKey, error) {
+	if path == "" {
+		return nil, errors.New("private_key_file or public_key_file is required")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if alg == AlgRS256 {
+		return jwt.ParseRSAPublicKeyFromPEM(data)
+	}
+	return jwt.ParseEdPublicKeyFromPEM(data)
+}
+
+// JWK is a JSON Web Key (RFC 7517) holding a public signing key
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // OKP curve
+	X   string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the set in configuration order
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(ks.order))}
+	b64 := base64.RawURLEncoding
+	for _, kid := range ks.order {
+		jwk := JWK{Kid: kid, Use: "sig", Alg: ks.method.Alg()}
+		switch key := ks.publicKeys[kid].(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = b64.EncodeToString(key.N.Bytes())
+			jwk.E = b64.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = b64.EncodeToString(key)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+func newEd25519Files(t *testing.T, dir, kid string) (privPath, pubPath string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	return writePEM(t, dir, kid+".pem", "PRIVATE KEY", privDER), writePEM(t, dir, kid+".pub.pem", "PUBLIC KEY", pubDER)
+}
+
+func newManager(t *testing.T, alg, kid string, files []KeyFile) *JWTManager {
+	t.Helper()
+	ks, err := LoadKeySet(alg, kid, files)
+	require.NoError(t, err)
+	return NewJWTManager("unused", 1, 1).WithKeySet(ks)
+}
+
+// TestKeySet_EdDSARotation tests that tokens signed by a retired key still verify
+// while it stays in the set, and stop verifying once it is removed
+func TestKeySet_EdDSARotation(t *testing.T) {
+	dir := t.TempDir()
+	oldPriv, oldPub := newEd25519Files(t, dir, "old")
+	newPriv, _ := newEd25519Files(t, dir, "new")
+
+	oldManager := newManager(t, AlgEdDSA, "old", []KeyFile{{ID: "old", PrivateKeyFile: oldPriv}})
+	oldToken, err := oldManager.GenerateAccessToken(7)
+	require.NoError(t, err)
+
+	rotated := newManager(t, AlgEdDSA, "new", []KeyFile{
+		{ID: "new", PrivateKeyFile: newPriv},
+		{ID: "old", PublicKeyFile: oldPub},
+	})
+	claims, err := rotated.ValidateAccessToken(oldToken)
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), claims.UserID)
+
+	newToken, err := rotated.GenerateAccessToken(8)
+	require.NoError(t, err)
+	_, err = rotated.ValidateAccessToken(newToken)
+	assert.NoError(t, err)
+
+	retired := newManager(t, AlgEdDSA, "new", []KeyFile{{ID: "new", PrivateKeyFile: newPriv}})
+	_, err = retired.ValidateAccessToken(oldToken)
+	assert.ErrorIs(t, err, ErrInvalidToken, "unknown kid is rejected")
+
+	jwks := rotated.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "new", jwks.Keys[0].Kid)
+	assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+	assert.Equal(t, "Ed25519", jwks.Keys[0].Crv)
+	assert.Equal(t, "EdDSA", jwks.Keys[0].Alg)
+}
+
+// TestKeySet_RS256 tests RS256 signing and the JWK encoding of RSA keys
+func TestKeySet_RS256(t *testing.T) {
+	dir := t.TempDir()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privPath := writePEM(t, dir, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(priv))
+
+	m := newManager(t, AlgRS256, "rsa-1", []KeyFile{{ID: "rsa-1", PrivateKeyFile: privPath}})
+	assert.Equal(t, AlgRS256, m.Algorithm())
+
+	token, err := m.GenerateAccessToken(1)
+	require.NoError(t, err)
+	_, err = m.ValidateAccessToken(token)
+	assert.NoError(t, err)
+
+	jwks := m.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+	assert.NotEmpty(t, jwks.Keys[0].N)
+
+	// An HS256 token must not be accepted by an RS256 manager
+	hsToken, err := NewJWTManager("secret", 1, 1).GenerateAccessToken(1)
+	require.NoError(t, err)
+	_, err = m.ValidateAccessToken(hsToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+// TestLoadKeySet_Errors tests configuration mistakes
+func TestLoadKeySet_Errors(t *testing.T) {
+	dir := t.TempDir()
+	_, pub := newEd25519Files(t, dir, "k")
+
+	_, err := LoadKeySet("ES256", "k", nil)
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+
+	_, err = LoadKeySet(AlgEdDSA, "k", []KeyFile{{ID: "k", PublicKeyFile: pub}})
+	assert.ErrorIs(t, err, ErrSigningKeyNotFound, "signing key needs a private key")
+
+	_, err = LoadKeySet(AlgRS256, "k", []KeyFile{{ID: "k", PublicKeyFile: pub}})
+	assert.Error(t, err, "Ed25519 key cannot be used for RS256")
+}
+
+// TestHS256_Default tests that the default mode still uses the shared secret
+func TestHS256_Default(t *testing.T) {
+	m := NewJWTManager("secret", 1, 1)
+	assert.Equal(t, AlgHS256, m.Algorithm())
+	assert.Empty(t, m.JWKS().Keys)
+
+	token, err := m.GenerateAccessToken(3)
+	require.NoError(t, err)
+	claims, err := m.ValidateAccessToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), claims.UserID)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), claims.ExpiresAt.Time, time.Minute)
+}
+
+// TestIssueClientToken tests that client_credentials tokens carry the client instead of a user
+func TestIssueClientToken(t *testing.T) {
+	m := NewJWTManager("secret", 1, 1)
+
+	token, issued, err := m.IssueClientToken("client-1", []string{"user.read", "audit.read"}, time.Hour)
+	require.NoError(t, err)
+	claims, err := m.ValidateAccessToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, issued.ID, claims.ID)
+	assert.Zero(t, claims.UserID)
+	assert.Empty(t, claims.SessionID)
+	assert.Equal(t, "client-1", claims.ClientID)
+	assert.Equal(t, "client-1", claims.Subject)
+	assert.Equal(t, []string{"user.read", "audit.read"}, claims.Scopes())
+	assert.WithinDuration(t, time.Now().Add(time.Hour), claims.ExpiresAt.Time, time.Minute)
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hash algorithms recognised by PasswordHasher.VerifyPassword
+const (
+	HashArgon2id     = "argon2id"
+	HashBcrypt       = "bcrypt"
+	HashPBKDF2SHA256 = "pbkdf2-sha256"
+)
+
+// HashAlgorithm detects the algorithm of an encoded hash from its prefix.
+// It returns "" for formats we cannot verify.
+//
+//	$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+//	$2a$10$<salt+hash>                       bcrypt（$2b$ / $2y$ 同样支持）
+//	$pbkdf2-sha256$<iter>$<salt>$<hash>      passlib，adapted base64
+//	pbkdf2_sha256$<iter>$<salt>$<hash>       Django，salt 为明文，hash 为标准 base64
+func HashAlgorithm(encodedHash string) string {
+	switch {
+	case strings.HasPrefix(encodedHash, "$argon2id$"):
+		return HashArgon2id
+	case strings.HasPrefix(encodedHash, "$2a$"),
+		strings.HasPrefix(encodedHash, "$2b$"),
+		strings.HasPrefix(encodedHash, "$2y$"):
+		return HashBcrypt
+	case strings.HasPrefix(encodedHash, "$pbkdf2-sha256$"),
+		strings.HasPrefix(encodedHash, "pbkdf2_sha256$"):
+		return HashPBKDF2SHA256
+	}
+	return ""
+}
+
+// verifyBcrypt checks a password against a bcrypt hash
+func verifyBcrypt(password, encodedHash string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, ErrInvalidHash
+	}
+	return true, nil
+}
+
+// verifyPBKDF2 checks a password against a passlib or Django PBKDF2-SHA256 hash
+func verifyPBKDF2(password, encodedHash string) (bool, error) {
+	parts := strings.Split(strings.TrimPrefix(encodedHash, "$"), "$")
+	if len(parts) != 4 {
+		return false, ErrInvalidHash
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false, ErrInvalidHash
+	}
+
+	var salt, hash []byte
+	if parts[0] == "pbkdf2_sha256" {
+		salt = []byte(parts[2])
+		hash, err = base64.StdEncoding.DecodeString(parts[3])
+	} else {
+		if salt, err = decodeAB64(parts[2]); err == nil {
+			hash, err = decodeAB64(parts[3])
+		}
+	}
+	if err != nil || len(hash) == 0 {
+		return false, ErrInvalidHash
+	}
+
+	otherHash, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(hash))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(hash, otherHash) == 1, nil
+}
+
+// decodeAB64 decodes passlib's adapted base64: "." instead of "+" and no padding
+func decodeAB64(s string) ([]byte, error) {
+	return base64.RawStdEncoding.DecodeString(strings.ReplaceAll(s, ".", "+"))
+}
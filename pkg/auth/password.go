@@ -90,8 +90,36 @@ func (h *PasswordHasher) HashPassword(password string) (string, error) {
 	return encodedHash, nil
 }
 
-// VerifyPassword verifies a password against an Argon2 hash
+// VerifyPassword verifies a password against an encoded hash.
+// Besides Argon2id it accepts bcrypt and PBKDF2-SHA256 hashes imported from legacy systems;
+// see HashAlgorithm for the formats.
 func (h *PasswordHasher) VerifyPassword(password, encodedHash string) (bool, error) {
+	switch HashAlgorithm(encodedHash) {
+	case HashArgon2id:
+		return h.verifyArgon2(password, encodedHash)
+	case HashBcrypt:
+		return verifyBcrypt(password, encodedHash)
+	case HashPBKDF2SHA256:
+		return verifyPBKDF2(password, encodedHash)
+	}
+	return false, ErrInvalidHash
+}
+
+// NeedsRehash reports whether a hash that just verified should be replaced:
+// it uses another algorithm, or Argon2id parameters other than the hasher's.
+func (h *PasswordHasher) NeedsRehash(encodedHash string) bool {
+	if HashAlgorithm(encodedHash) != HashArgon2id {
+		return true
+	}
+	params, _, _, err := h.decodeHash(encodedHash)
+	if err != nil {
+		return true
+	}
+	return *params != *h.params
+}
+
+// verifyArgon2 verifies a password against an Argon2id hash
+func (h *PasswordHasher) verifyArgon2(password, encodedHash string) (bool, error) {
 	// Extract parameters and hash from encoded string
 	params, salt, hash, err := h.decodeHash(encodedHash)
 	if err != nil {
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TestPasswordHasher_LegacyHashes tests that imported bcrypt and PBKDF2 hashes verify
+// and are flagged for rehashing
+func TestPasswordHasher_LegacyHashes(t *testing.T) {
+	hasher := NewPasswordHasher()
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("legacy-Pass1"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	hashes := map[string]string{
+		"bcrypt":         string(bcryptHash),
+		"bcrypt $2y$":    "$2y$" + string(bcryptHash[4:]),
+		"passlib pbkdf2": "$pbkdf2-sha256$29000$N2bMWQsBoHSu9d6bE0Ljnw$7WTsdc/mjrDmHra8xcCYe/hxUGVfvEyztpdgT8Tob08",
+		"django pbkdf2":  "pbkdf2_sha256$260000$hUq2rLBnX8cK$ke0Jq2UQ9/O5VAqMa31F+LmkqQA9u1Wn9i6qO9KDE8U=",
+	}
+	for name, hash := range hashes {
+		t.Run(name, func(t *testing.T) {
+			ok, err := hasher.VerifyPassword("legacy-Pass1", hash)
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = hasher.VerifyPassword("legacy-Pass2", hash)
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			assert.True(t, hasher.NeedsRehash(hash))
+		})
+	}
+
+	_, err = hasher.VerifyPassword("legacy-Pass1", "md5$abc")
+	assert.ErrorIs(t, err, ErrInvalidHash)
+}
+
+// TestPasswordHasher_NeedsRehash tests that only hashes with the current Argon2 params are kept
+func TestPasswordHasher_NeedsRehash(t *testing.T) {
+	weak := NewPasswordHasherWithParams(&Argon2Params{Memory: 8 * 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	hasher := NewPasswordHasher()
+
+	oldHash, err := weak.HashPassword("Tr1cky-Kite")
+	require.NoError(t, err)
+	ok, err := hasher.VerifyPassword("Tr1cky-Kite", oldHash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, hasher.NeedsRehash(oldHash))
+
+	newHash, err := hasher.HashPassword("Tr1cky-Kite")
+	require.NoError(t, err)
+	assert.False(t, hasher.NeedsRehash(newHash))
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Password policy rules a password can violate
+const (
+	ViolationTooShort      = "too_short"
+	ViolationTooLong       = "too_long"
+	ViolationTooFewClasses = "too_few_classes"
+	ViolationBreached      = "breached"
+)
+
+// PasswordPolicy describes what a new password must satisfy
+type PasswordPolicy struct {
+	MinLength     int  // 按字符（rune）计数
+	MaxLength     int  // 0 表示不限制
+	MinClasses    int  // 小写字母、大写字母、数字、符号四类中至少包含几类
+	CheckBreached bool // 拒绝内置常见 / 泄露密码列表中的密码
+}
+
+// Check returns the rules the password violates; an empty result means it is acceptable
+func (p PasswordPolicy) Check(password string) []string {
+	var violations []string
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		violations = append(violations, ViolationTooShort)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, ViolationTooLong)
+	}
+	if CharacterClasses(password) < p.MinClasses {
+		violations = append(violations, ViolationTooFewClasses)
+	}
+	if p.CheckBreached && IsBreachedPassword(password) {
+		violations = append(violations, ViolationBreached)
+	}
+	return violations
+}
+
+// CharacterClasses counts how many of lowercase, uppercase, digits and symbols the password uses
+func CharacterClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	count := 0
+	for _, used := range []bool{lower, upper, digit, symbol} {
+		if used {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIsBreachedPassword checks lookups in the bundled prefix files
+func TestIsBreachedPassword(t *testing.T) {
+	assert.True(t, IsBreachedPassword("password"))
+	assert.True(t, IsBreachedPassword("PASSWORD"), "lowercase form is checked too")
+	assert.True(t, IsBreachedPassword("woaini1314"))
+	assert.False(t, IsBreachedPassword("c0rrect-h0rse-battery-staple"))
+}
+
+// TestPasswordPolicy_Check tests each rule of the policy
+func TestPasswordPolicy_Check(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 8, MaxLength: 16, MinClasses: 3, CheckBreached: true}
+
+	tests := []struct {
+		password string
+		want     []string
+	}{
+		{"Tr1cky-Kite", nil},
+		{"Ab1!", []string{ViolationTooShort}},
+		{"Abcdefgh1!Abcdefgh1!", []string{ViolationTooLong}},
+		{"lowercaseonly", []string{ViolationTooFewClasses}},
+		{"Password123", []string{ViolationBreached}},
+		{"密码Abc12345", nil}, // 中文字符计入符号类
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, policy.Check(tt.password), tt.password)
+	}
+}
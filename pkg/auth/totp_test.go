@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfc6238Key is the SHA1 test key from RFC 6238 Appendix B
+var rfc6238Key = []byte("12345678901234567890")
+
+// TestHOTP_RFC6238Vectors checks the SHA1 test vectors from RFC 6238 Appendix B
+func TestHOTP_RFC6238Vectors(t *testing.T) {
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, v := range vectors {
+		step := TOTPStep(time.Unix(v.unix, 0))
+		assert.Equal(t, v.code, hotp(rfc6238Key, uint64(step), 8), "time %d", v.unix)
+	}
+}
+
+// TestTOTPCode tests 6-digit codes derived from a base32 secret
+func TestTOTPCode(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(rfc6238Key)
+
+	code, err := TOTPCode(secret, time.Unix(59, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, "287082", code, "6-digit code is the low 6 digits of the 8-digit vector")
+
+	// Secrets are accepted in lower case and with spaces, as users often type them
+	code, err = TOTPCode(strings.ToLower(secret[:8])+" "+secret[8:], time.Unix(1111111109, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, "081804", code)
+
+	_, err = TOTPCode("not base32!", time.Now())
+	assert.ErrorIs(t, err, ErrInvalidTOTPSecret)
+}
+
+// TestValidateTOTP tests the skew window and the returned step
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	assert.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	prev, err := TOTPCode(secret, now.Add(-TOTPPeriod*time.Second))
+	assert.NoError(t, err)
+
+	step, ok := ValidateTOTP(secret, prev, now, 1)
+	assert.True(t, ok, "code from the previous step is accepted with skew 1")
+	assert.Equal(t, TOTPStep(now)-1, step)
+
+	_, ok = ValidateTOTP(secret, prev, now, 0)
+	assert.False(t, ok, "code from the previous step is rejected without skew")
+
+	_, ok = ValidateTOTP(secret, "12345", now, 1)
+	assert.False(t, ok, "wrong length is rejected")
+}
+
+// TestTOTPURI tests the otpauth URI format
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("go-api-starter", "john@example.com", "JBSWY3DPEHPK3PXP")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/go-api-starter:john@example.com?"))
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=go-api-starter")
+	assert.Contains(t, uri, "digits=6")
+	assert.Contains(t, uri, "period=30")
+}
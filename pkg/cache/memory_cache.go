@@ -8,6 +8,9 @@ import (
 	"time"
 )
 
+// counterExpSuffix marks the entry holding a counter's expiration
+const counterExpSuffix = "_exp"
+
 // cacheItem represents a cached item with expiration
 type cacheItem struct {
 	value     []byte
@@ -28,6 +31,11 @@ type MemoryCache struct {
 	counters  sync.Map
 	available atomic.Bool
 	stopCh    chan struct{}
+
+	// 进程内发布 / 订阅
+	subsMu    sync.RWMutex
+	subs      map[string]map[uint64]func([]byte)
+	nextSubID uint64
 }
 
 // NewMemoryCache creates a new memory cache backend
@@ -63,6 +71,10 @@ func (m *MemoryCache) cleanup() {
 	m.data.Range(func(key, value interface{}) bool {
 		if item, ok := value.(*cacheItem); ok && item.isExpired() {
 			m.data.Delete(key)
+			// Expired counter TTL markers take their counter with them
+			if k, ok := key.(string); ok && strings.HasSuffix(k, counterExpSuffix) {
+				m.counters.Delete(strings.TrimSuffix(k, counterExpSuffix))
+			}
 		}
 		return true
 	})
@@ -100,6 +112,8 @@ func (m *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl tim
 // Delete removes a key
 func (m *MemoryCache) Delete(ctx context.Context, key string) error {
 	m.data.Delete(key)
+	m.counters.Delete(key)
+	m.data.Delete(key + counterExpSuffix)
 	return nil
 }
 
@@ -111,6 +125,12 @@ func (m *MemoryCache) DeleteByPrefix(ctx context.Context, prefix string) error {
 		}
 		return true
 	})
+	m.counters.Range(func(key, value interface{}) bool {
+		if k, ok := key.(string); ok && strings.HasPrefix(k, prefix) {
+			m.counters.Delete(key)
+		}
+		return true
+	})
 	return nil
 }
 
@@ -145,6 +165,13 @@ func (m *MemoryCache) Incr(ctx context.Context, key string) (int64, error) {
 
 // IncrWithExpire increments a counter with TTL and returns the new value
 func (m *MemoryCache) IncrWithExpire(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	// Reset the counter once its window has passed
+	if value, ok := m.data.Load(key + counterExpSuffix); ok {
+		if item, ok := value.(*cacheItem); ok && item.isExpired() {
+			m.counters.Delete(key)
+		}
+	}
+
 	result, err := m.Incr(ctx, key)
 	if err != nil {
 		return 0, err
@@ -155,7 +182,7 @@ func (m *MemoryCache) IncrWithExpire(ctx context.Context, key string, ttl time.D
 		value:     nil,
 		expiresAt: time.Now().Add(ttl),
 	}
-	m.data.Store(key+"_exp", item)
+	m.data.Store(key+counterExpSuffix, item)
 
 	return result, nil
 }
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+)
+
+// PubSub broadcasts small messages to every subscriber of a channel. With Redis the
+// subscribers are all instances sharing the server; the memory implementation only
+// reaches subscribers in the same process.
+type PubSub interface {
+	// Publish sends message to the current subscribers of channel
+	Publish(ctx context.Context, channel string, message []byte) error
+
+	// Subscribe calls handler for every message on channel until ctx is cancelled
+	Subscribe(ctx context.Context, channel string, handler func(message []byte)) error
+}
+
+var (
+	_ PubSub = (*MemoryCache)(nil)
+	_ PubSub = (*RedisCache)(nil)
+)
+
+// Publish delivers message to the in-process subscribers of channel
+func (m *MemoryCache) Publish(ctx context.Context, channel string, message []byte) error {
+	m.subsMu.RLock()
+	handlers := make([]func([]byte), 0, len(m.subs[channel]))
+	for _, h := range m.subs[channel] {
+		handlers = append(handlers, h)
+	}
+	m.subsMu.RUnlock()
+
+	for _, h := range handlers {
+		h(message)
+	}
+	return nil
+}
+
+// Subscribe registers handler for channel and blocks until ctx is cancelled
+func (m *MemoryCache) Subscribe(ctx context.Context, channel string, handler func(message []byte)) error {
+	m.subsMu.Lock()
+	if m.subs == nil {
+		m.subs = make(map[string]map[uint64]func([]byte))
+	}
+	if m.subs[channel] == nil {
+		m.subs[channel] = make(map[uint64]func([]byte))
+	}
+	m.nextSubID++
+	id := m.nextSubID
+	m.subs[channel][id] = handler
+	m.subsMu.Unlock()
+
+	<-ctx.Done()
+
+	m.subsMu.Lock()
+	delete(m.subs[channel], id)
+	m.subsMu.Unlock()
+	return nil
+}
+
+// Publish sends message to all instances subscribed to channel
+func (r *RedisCache) Publish(ctx context.Context, channel string, message []byte) error {
+	return r.client.Publish(ctx, channel, message).Err()
+}
+
+// Subscribe calls handler for every message on channel until ctx is cancelled.
+// The connection is re-established after network errors; messages published while
+// it is down are lost.
+func (r *RedisCache) Subscribe(ctx context.Context, channel string, handler func(message []byte)) error {
+	sub := r.client.Subscribe(ctx, channel)
+	defer sub.Close()
+
+	messages := sub.Channel()
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			handler([]byte(msg.Payload))
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
@@ -6,10 +6,14 @@ const (
 	ErrTokenExpired       = "AUTH_TOKEN_EXPIRED"
 	ErrRefreshTokenExpired = "AUTH_REFRESH_TOKEN_EXPIRED"
 	ErrInvalidRefreshToken = "AUTH_INVALID_REFRESH_TOKEN"
+	ErrRefreshTokenReused = "AUTH_REFRESH_TOKEN_REUSED"
+	ErrSessionRevoked     = "AUTH_SESSION_REVOKED"
 	ErrUnauthenticated    = "AUTH_UNAUTHENTICATED"
 	ErrWrongCredentials   = "AUTH_WRONG_CREDENTIALS"
 	ErrAccountNotFound    = "AUTH_ACCOUNT_NOT_FOUND"
 	ErrAccountFrozen      = "AUTH_ACCOUNT_FROZEN"
+	ErrAccountLocked      = "AUTH_ACCOUNT_LOCKED"
+	ErrIPLocked           = "AUTH_IP_LOCKED"
 	ErrPasswordRequired   = "AUTH_PASSWORD_REQUIRED"
 )
 
@@ -30,11 +34,87 @@ const (
 	ErrCodeRateLimit      = "VERIFY_RATE_LIMIT"
 	ErrCodeStoreFailed    = "VERIFY_STORE_FAILED"
 	ErrMailSendFailed     = "VERIFY_MAIL_SEND_FAILED"
+	ErrSMSSendFailed      = "VERIFY_SMS_SEND_FAILED"
 	ErrBindEmailCodeRequired = "VERIFY_BIND_EMAIL_CODE_REQUIRED"
+	ErrBindMobileCodeRequired = "VERIFY_BIND_MOBILE_CODE_REQUIRED"
+	ErrContactChangeNotFound = "VERIFY_CONTACT_CHANGE_NOT_FOUND"
 	ErrProvideCode        = "VERIFY_PROVIDE_CODE"
 	ErrProvideMobileOrEmail = "VERIFY_PROVIDE_MOBILE_OR_EMAIL"
 )
 
+// ─── Password Policy ───
+const (
+	ErrPasswordPolicy      = "PASSWORD_POLICY_VIOLATED"
+	ErrPasswordTooShort    = "PASSWORD_TOO_SHORT"
+	ErrPasswordTooLong     = "PASSWORD_TOO_LONG"
+	ErrPasswordTooSimple   = "PASSWORD_TOO_SIMPLE"
+	ErrPasswordBreached    = "PASSWORD_BREACHED"
+	ErrPasswordReused      = "PASSWORD_REUSED"
+	ErrResetPasswordDenied = "PASSWORD_RESET_DENIED"
+)
+
+// ─── MFA ───
+const (
+	ErrMFAAlreadyEnabled  = "MFA_ALREADY_ENABLED"
+	ErrMFANotEnrolled     = "MFA_NOT_ENROLLED"
+	ErrMFACodeInvalid     = "MFA_CODE_INVALID"
+	ErrMFATokenInvalid    = "MFA_TOKEN_INVALID"
+	ErrMFARequiredByRole  = "MFA_REQUIRED_BY_ROLE"
+)
+
+// ─── OIDC ───
+const (
+	ErrOIDCProviderNotFound = "OIDC_PROVIDER_NOT_FOUND"
+	ErrOIDCStateInvalid     = "OIDC_STATE_INVALID"
+	ErrOIDCLoginFailed      = "OIDC_LOGIN_FAILED"
+	ErrOIDCEmailUnverified  = "OIDC_EMAIL_UNVERIFIED"
+)
+
+// ─── API Key ───
+const (
+	ErrAPIKeyNotFound     = "API_KEY_NOT_FOUND"
+	ErrAPIKeyInvalid      = "API_KEY_INVALID"
+	ErrAPIKeyScopeDenied  = "API_KEY_SCOPE_DENIED"
+	ErrAPIKeyLimitReached = "API_KEY_LIMIT_REACHED"
+)
+
+// ─── Impersonation ───
+const (
+	ErrImpersonateSelf     = "IMPERSONATE_SELF"
+	ErrImpersonationDenied = "IMPERSONATE_DENIED"
+)
+
+// ─── Freeze ───
+const (
+	ErrFreezeSelf         = "FREEZE_SELF"
+	ErrFreezeDenied       = "FREEZE_DENIED"
+	ErrFreezeUntilInvalid = "FREEZE_UNTIL_INVALID"
+	ErrUserNotFrozen      = "FREEZE_USER_NOT_FROZEN"
+)
+
+// ─── Passkey ───
+const (
+	ErrPasskeyInvalid          = "PASSKEY_INVALID"
+	ErrPasskeyChallengeExpired = "PASSKEY_CHALLENGE_EXPIRED"
+	ErrPasskeyNotFound         = "PASSKEY_NOT_FOUND"
+	ErrPasskeyExists           = "PASSKEY_EXISTS"
+)
+
+// ─── Magic Link ───
+const (
+	ErrMagicLinkInvalid         = "MAGIC_LINK_INVALID"
+	ErrMagicLinkBrowserMismatch = "MAGIC_LINK_BROWSER_MISMATCH"
+	ErrMagicLinkRateLimit       = "MAGIC_LINK_RATE_LIMIT"
+)
+
+// ─── OAuth ───
+const (
+	ErrOAuthInvalidClient  = "OAUTH_INVALID_CLIENT"
+	ErrOAuthClientNotFound = "OAUTH_CLIENT_NOT_FOUND"
+	ErrOAuthScopeDenied    = "OAUTH_SCOPE_DENIED"
+	ErrOAuthInvalidScope   = "OAUTH_INVALID_SCOPE"
+)
+
 // ─── QR Code ───
 const (
 	ErrQRNotFound         = "QR_NOT_FOUND"
@@ -52,12 +132,29 @@ const (
 	ErrEmailBoundToWechat   = "WECHAT_EMAIL_BOUND"
 )
 
+// ─── Role ───
+const (
+	ErrRoleParentNotFound    = "ROLE_PARENT_NOT_FOUND"
+	ErrRoleHierarchyCycle    = "ROLE_HIERARCHY_CYCLE"
+	ErrUserRoleWindowInvalid = "USER_ROLE_WINDOW_INVALID"
+)
+
+// ─── Permission Space ───
+const (
+	ErrPermissionSpaceNotFound   = "PERMISSION_SPACE_NOT_FOUND"
+	ErrPermissionSpaceNameExists = "PERMISSION_SPACE_NAME_EXISTS"
+	ErrPermissionSpaceFull       = "PERMISSION_SPACE_FULL"
+	ErrPermissionSpaceNotEmpty   = "PERMISSION_SPACE_NOT_EMPTY"
+	ErrPermissionNotFound        = "PERMISSION_NOT_FOUND"
+)
+
 // ─── Validation / Common ───
 const (
 	ErrValidationFailed   = "VALIDATION_FAILED"
 	ErrParamInvalid       = "PARAM_INVALID"
 	ErrInvalidUserID      = "INVALID_USER_ID"
 	ErrInvalidRoleID      = "INVALID_ROLE_ID"
+	ErrInvalidSpaceID     = "INVALID_SPACE_ID"
 	ErrInvalidPermissionID = "INVALID_PERMISSION_ID"
 	ErrInvalidLogID       = "INVALID_LOG_ID"
 )
@@ -73,6 +170,7 @@ const (
 const (
 	ErrLogNotFound        = "LOG_NOT_FOUND"
 	ErrAccountNotRegistered = "ACCOUNT_NOT_REGISTERED"
+	ErrSessionNotFound    = "SESSION_NOT_FOUND"
 )
 
 // ─── Generic ───
@@ -89,6 +187,20 @@ const (
 	ErrGenerateTokenFailed = "INTERNAL_GENERATE_TOKEN_FAILED"
 	ErrResetPasswordFailed = "INTERNAL_RESET_PASSWORD_FAILED"
 	ErrCreateQRFailed     = "INTERNAL_CREATE_QR_FAILED"
+	ErrRevokeSessionsFailed = "INTERNAL_REVOKE_SESSIONS_FAILED"
+	ErrQuerySessionFailed = "INTERNAL_QUERY_SESSION_FAILED"
+	ErrMFAStoreFailed     = "INTERNAL_MFA_STORE_FAILED"
+	ErrOIDCProviderUnavailable = "INTERNAL_OIDC_PROVIDER_UNAVAILABLE"
+	ErrIdentityStoreFailed = "INTERNAL_IDENTITY_STORE_FAILED"
+	ErrOIDCStateStoreFailed = "INTERNAL_OIDC_STATE_STORE_FAILED"
+	ErrQRStoreFailed      = "INTERNAL_QR_STORE_FAILED"
+	ErrAPIKeyStoreFailed  = "INTERNAL_API_KEY_STORE_FAILED"
+	ErrAuditQueryFailed   = "INTERNAL_AUDIT_QUERY_FAILED"
+	ErrPasswordHistoryFailed = "INTERNAL_PASSWORD_HISTORY_FAILED"
+	ErrPasskeyStoreFailed = "INTERNAL_PASSKEY_STORE_FAILED"
+	ErrOAuthClientStoreFailed = "INTERNAL_OAUTH_CLIENT_STORE_FAILED"
+	ErrFreezeUserFailed = "INTERNAL_FREEZE_USER_FAILED"
+	ErrMagicLinkStoreFailed = "INTERNAL_MAGIC_LINK_STORE_FAILED"
 )
 
 // ─── WeChat ───
@@ -6,10 +6,14 @@ var enUS = map[string]string{
 	ErrTokenExpired:        "Token expired",
 	ErrRefreshTokenExpired: "Refresh token expired",
 	ErrInvalidRefreshToken: "Invalid refresh token",
+	ErrRefreshTokenReused:  "Refresh token already used; the session has been revoked, please log in again",
+	ErrSessionRevoked:      "Session has been revoked, please log in again",
 	ErrUnauthenticated:     "User not authenticated",
 	ErrWrongCredentials:    "Wrong phone/email or password",
 	ErrAccountNotFound:     "Account not found",
 	ErrAccountFrozen:       "Account has been frozen",
+	ErrAccountLocked:       "Too many failed login attempts, the account is temporarily locked. Please try again later",
+	ErrIPLocked:            "Too many failed login attempts from this IP, login is temporarily blocked. Please try again later",
 	ErrPasswordRequired:    "Password is required",
 
 	// Registration / Account
@@ -20,15 +24,73 @@ var enUS = map[string]string{
 	ErrUserNotFound:          "User not found",
 
 	// Verification Code
-	ErrCodeRequired:          "Verification code is required",
-	ErrCodeInvalid:           "Invalid verification code",
-	ErrCodeExpired:           "Verification code expired",
-	ErrCodeRateLimit:         "Please wait 60 seconds before resending",
-	ErrCodeStoreFailed:       "Failed to store verification code",
-	ErrMailSendFailed:        "Failed to send email",
-	ErrBindEmailCodeRequired: "Verification code required for email binding",
-	ErrProvideCode:           "Please provide verification code",
-	ErrProvideMobileOrEmail:  "Please provide phone or email",
+	ErrCodeRequired:           "Verification code is required",
+	ErrCodeInvalid:            "Invalid verification code",
+	ErrCodeExpired:            "Verification code expired",
+	ErrCodeRateLimit:          "Please wait 60 seconds before resending",
+	ErrCodeStoreFailed:        "Failed to store verification code",
+	ErrMailSendFailed:         "Failed to send email",
+	ErrSMSSendFailed:          "Failed to send SMS",
+	ErrBindEmailCodeRequired:  "Verification code required for email binding",
+	ErrBindMobileCodeRequired: "Verification code required for mobile binding",
+	ErrContactChangeNotFound:  "No pending change or it has expired; request a new code",
+	ErrProvideCode:            "Please provide verification code",
+	ErrProvideMobileOrEmail:   "Please provide phone or email",
+
+	// Password Policy
+	ErrPasswordPolicy:      "Password does not meet the security requirements",
+	ErrPasswordTooShort:    "Password is too short",
+	ErrPasswordTooLong:     "Password is too long",
+	ErrPasswordTooSimple:   "Password needs more kinds of characters (lowercase, uppercase, digits, symbols)",
+	ErrPasswordBreached:    "This password is too common or has appeared in a data breach, please choose another",
+	ErrPasswordReused:      "You cannot reuse a recent password",
+	ErrResetPasswordDenied: "Only administrators who can manage roles may reset the password of accounts that can",
+
+	// MFA
+	ErrMFAAlreadyEnabled: "Two-factor authentication is already enabled",
+	ErrMFANotEnrolled:    "Two-factor authentication is not set up",
+	ErrMFACodeInvalid:    "Invalid two-factor code",
+	ErrMFATokenInvalid:   "Two-factor verification expired, please log in again",
+	ErrMFARequiredByRole: "Your role requires two-factor authentication",
+
+	// OIDC
+	ErrOIDCProviderNotFound: "Login provider not found",
+	ErrOIDCStateInvalid:     "Login request expired, please start again",
+	ErrOIDCLoginFailed:      "External login failed",
+	ErrOIDCEmailUnverified:  "This email is already registered; please sign in the way you usually do",
+
+	// API Key
+	ErrAPIKeyNotFound:     "API key not found",
+	ErrAPIKeyInvalid:      "API key is invalid or expired",
+	ErrAPIKeyScopeDenied:  "Cannot grant a permission you do not have",
+	ErrAPIKeyLimitReached: "Too many API keys",
+
+	// Impersonation
+	ErrImpersonateSelf:     "You cannot impersonate yourself",
+	ErrImpersonationDenied: "Accounts that can manage roles cannot be impersonated",
+
+	// Freeze
+	ErrFreezeSelf:         "You cannot freeze your own account",
+	ErrFreezeDenied:       "Only administrators who can manage roles may freeze accounts that can",
+	ErrFreezeUntilInvalid: "frozen_until must be in the future",
+	ErrUserNotFrozen:      "This user is not frozen",
+
+	// Passkey
+	ErrPasskeyInvalid:          "Passkey verification failed",
+	ErrPasskeyChallengeExpired: "The passkey request has expired, please try again",
+	ErrPasskeyNotFound:         "Passkey not found",
+	ErrPasskeyExists:           "This passkey is already registered",
+
+	// Magic Link
+	ErrMagicLinkInvalid:         "The sign-in link is invalid or has expired",
+	ErrMagicLinkBrowserMismatch: "Open the sign-in link in the browser that requested it",
+	ErrMagicLinkRateLimit:       "Too many sign-in links requested, please try again later",
+
+	// OAuth
+	ErrOAuthInvalidClient:  "Client authentication failed",
+	ErrOAuthClientNotFound: "OAuth client not found",
+	ErrOAuthScopeDenied:    "You can only grant a client permissions you hold",
+	ErrOAuthInvalidScope:   "Requested scope exceeds the client's scopes",
 
 	// QR Code
 	ErrQRNotFound:      "QR code not found",
@@ -43,11 +105,24 @@ var enUS = map[string]string{
 	ErrMobileBoundToWechat: "Phone number already bound to another WeChat",
 	ErrEmailBoundToWechat:  "Email already bound to another WeChat",
 
+	// Role
+	ErrRoleParentNotFound:    "Parent role not found",
+	ErrRoleHierarchyCycle:    "A role cannot inherit from itself or its descendants",
+	ErrUserRoleWindowInvalid: "expires_at must be in the future and after starts_at",
+
+	// Permission Space
+	ErrPermissionSpaceNotFound:   "Permission space not found",
+	ErrPermissionSpaceNameExists: "Permission space name already exists",
+	ErrPermissionSpaceFull:       "Permission space is full (64 permissions max); compact it or use another space",
+	ErrPermissionSpaceNotEmpty:   "Permission space still has permissions; move or delete them first",
+	ErrPermissionNotFound:        "Permission not found",
+
 	// Validation / Common
 	ErrValidationFailed:    "Validation failed",
 	ErrParamInvalid:        "Invalid parameter",
 	ErrInvalidUserID:       "Invalid user ID",
 	ErrInvalidRoleID:       "Invalid role ID",
+	ErrInvalidSpaceID:      "Invalid permission space ID",
 	ErrInvalidPermissionID: "Invalid permission ID",
 	ErrInvalidLogID:        "Invalid log ID",
 
@@ -59,6 +134,7 @@ var enUS = map[string]string{
 	// Resource
 	ErrLogNotFound:          "Log not found",
 	ErrAccountNotRegistered: "Account not registered",
+	ErrSessionNotFound:      "Session not found",
 
 	// Generic
 	ErrInternalError: "Internal server error",
@@ -73,6 +149,20 @@ func init() {
 		ErrGenerateTokenFailed: "Failed to generate token",
 		ErrResetPasswordFailed: "Failed to reset password",
 		ErrCreateQRFailed:      "Failed to create QR code",
+		ErrRevokeSessionsFailed: "Failed to revoke sessions",
+		ErrQuerySessionFailed:   "Failed to query sessions",
+		ErrMFAStoreFailed:       "Failed to save two-factor settings",
+		ErrOIDCProviderUnavailable: "Identity provider is unavailable",
+		ErrIdentityStoreFailed:     "Failed to save the linked account",
+		ErrOIDCStateStoreFailed:    "Failed to store the login request",
+		ErrQRStoreFailed:           "Failed to save QR code state",
+		ErrAPIKeyStoreFailed:       "Failed to save API key",
+		ErrAuditQueryFailed:        "Failed to query audit logs",
+		ErrPasswordHistoryFailed:   "Failed to read password history",
+		ErrPasskeyStoreFailed:      "Failed to save passkey",
+		ErrOAuthClientStoreFailed:  "Failed to save OAuth client",
+		ErrFreezeUserFailed:        "Failed to update freeze status",
+		ErrMagicLinkStoreFailed:    "Failed to save sign-in link",
 		ErrWechatNotConfigured: "WeChat mini-program not configured",
 		ErrWechatLoginFailed:   "WeChat login failed",
 		ErrWechatBindFailed:    "Failed to bind WeChat",
@@ -6,10 +6,14 @@ var zhCN = map[string]string{
 	ErrTokenExpired:        "令牌已过期",
 	ErrRefreshTokenExpired: "刷新令牌已过期",
 	ErrInvalidRefreshToken: "无效的刷新令牌",
+	ErrRefreshTokenReused:  "刷新令牌已被使用，该登录会话已失效，请重新登录",
+	ErrSessionRevoked:      "登录会话已失效，请重新登录",
 	ErrUnauthenticated:     "用户未认证",
 	ErrWrongCredentials:    "手机号/邮箱或密码错误",
 	ErrAccountNotFound:     "账号不存在",
 	ErrAccountFrozen:       "账号已被冻结",
+	ErrAccountLocked:       "登录失败次数过多，账号已被临时锁定，请稍后再试",
+	ErrIPLocked:            "该 IP 登录失败次数过多，已被临时限制登录，请稍后再试",
 	ErrPasswordRequired:    "密码不能为空",
 
 	// Registration / Account
@@ -20,15 +24,73 @@ var zhCN = map[string]string{
 	ErrUserNotFound:          "用户不存在",
 
 	// Verification Code
-	ErrCodeRequired:          "验证码不能为空",
-	ErrCodeInvalid:           "验证码错误",
-	ErrCodeExpired:           "验证码已过期或不存在",
-	ErrCodeRateLimit:         "请等待60秒后再发送验证码",
-	ErrCodeStoreFailed:       "存储验证码失败",
-	ErrMailSendFailed:        "发送邮件失败",
-	ErrBindEmailCodeRequired: "绑定邮箱需要提供验证码",
-	ErrProvideCode:           "请提供验证码",
-	ErrProvideMobileOrEmail:  "请提供手机号或邮箱",
+	ErrCodeRequired:           "验证码不能为空",
+	ErrCodeInvalid:            "验证码错误",
+	ErrCodeExpired:            "验证码已过期或不存在",
+	ErrCodeRateLimit:          "请等待60秒后再发送验证码",
+	ErrCodeStoreFailed:        "存储验证码失败",
+	ErrMailSendFailed:         "发送邮件失败",
+	ErrSMSSendFailed:          "发送短信失败",
+	ErrBindEmailCodeRequired:  "绑定邮箱需要提供验证码",
+	ErrBindMobileCodeRequired: "绑定手机号需要提供验证码",
+	ErrContactChangeNotFound:  "没有待确认的变更或已过期，请重新获取验证码",
+	ErrProvideCode:            "请提供验证码",
+	ErrProvideMobileOrEmail:   "请提供手机号或邮箱",
+
+	// Password Policy
+	ErrPasswordPolicy:      "密码不符合安全要求",
+	ErrPasswordTooShort:    "密码长度不足",
+	ErrPasswordTooLong:     "密码过长",
+	ErrPasswordTooSimple:   "密码需要混合更多类型的字符（小写字母、大写字母、数字、符号）",
+	ErrPasswordBreached:    "该密码过于常见或已出现在泄露数据中，请换一个",
+	ErrPasswordReused:      "不能使用最近用过的密码",
+	ErrResetPasswordDenied: "只有拥有角色管理权限的管理员才能重置同样拥有该权限的账号的密码",
+
+	// MFA
+	ErrMFAAlreadyEnabled: "已启用两步验证",
+	ErrMFANotEnrolled:    "尚未设置两步验证",
+	ErrMFACodeInvalid:    "两步验证码错误",
+	ErrMFATokenInvalid:   "两步验证已失效，请重新登录",
+	ErrMFARequiredByRole: "当前角色要求必须启用两步验证",
+
+	// OIDC
+	ErrOIDCProviderNotFound: "登录方式不存在",
+	ErrOIDCStateInvalid:     "登录请求已过期，请重新发起",
+	ErrOIDCLoginFailed:      "第三方登录失败",
+	ErrOIDCEmailUnverified:  "该邮箱已注册，请使用原有方式登录",
+
+	// API Key
+	ErrAPIKeyNotFound:     "API Key 不存在",
+	ErrAPIKeyInvalid:      "API Key 无效或已过期",
+	ErrAPIKeyScopeDenied:  "不能授予自己没有的权限",
+	ErrAPIKeyLimitReached: "API Key 数量已达上限",
+
+	// Impersonation
+	ErrImpersonateSelf:     "不能模拟登录自己的账号",
+	ErrImpersonationDenied: "不能模拟登录拥有角色管理权限的账号",
+
+	// Freeze
+	ErrFreezeSelf:         "不能冻结自己的账号",
+	ErrFreezeDenied:       "只有拥有角色管理权限的管理员才能冻结同样拥有该权限的账号",
+	ErrFreezeUntilInvalid: "冻结截止时间必须晚于当前时间",
+	ErrUserNotFrozen:      "该用户未被冻结",
+
+	// Passkey
+	ErrPasskeyInvalid:          "通行密钥验证失败",
+	ErrPasskeyChallengeExpired: "通行密钥请求已过期，请重试",
+	ErrPasskeyNotFound:         "通行密钥不存在",
+	ErrPasskeyExists:           "该通行密钥已注册",
+
+	// Magic Link
+	ErrMagicLinkInvalid:         "登录链接无效或已过期",
+	ErrMagicLinkBrowserMismatch: "请在申请登录链接的浏览器中打开该链接",
+	ErrMagicLinkRateLimit:       "登录链接发送过于频繁，请稍后再试",
+
+	// OAuth
+	ErrOAuthInvalidClient:  "客户端认证失败",
+	ErrOAuthClientNotFound: "OAuth 客户端不存在",
+	ErrOAuthScopeDenied:    "只能授予客户端自己拥有的权限",
+	ErrOAuthInvalidScope:   "请求的权限超出客户端的授权范围",
 
 	// QR Code
 	ErrQRNotFound:      "二维码不存在",
@@ -43,11 +105,24 @@ var zhCN = map[string]string{
 	ErrMobileBoundToWechat: "该手机号已绑定其他微信号",
 	ErrEmailBoundToWechat:  "该邮箱已绑定其他微信号",
 
+	// Role
+	ErrRoleParentNotFound:    "父角色不存在",
+	ErrRoleHierarchyCycle:    "角色不能继承自身或其子孙角色",
+	ErrUserRoleWindowInvalid: "到期时间必须晚于当前时间和生效时间",
+
+	// Permission Space
+	ErrPermissionSpaceNotFound:   "权限空间不存在",
+	ErrPermissionSpaceNameExists: "权限空间名称已存在",
+	ErrPermissionSpaceFull:       "权限空间已满（最多 64 个权限），请先压缩或换一个空间",
+	ErrPermissionSpaceNotEmpty:   "权限空间下仍有权限，请先移走或删除",
+	ErrPermissionNotFound:        "权限不存在",
+
 	// Validation / Common
 	ErrValidationFailed:   "参数验证失败",
 	ErrParamInvalid:       "参数错误",
 	ErrInvalidUserID:      "无效的用户ID",
 	ErrInvalidRoleID:      "无效的角色ID",
+	ErrInvalidSpaceID:     "无效的权限空间ID",
 	ErrInvalidPermissionID: "无效的权限ID",
 	ErrInvalidLogID:       "无效的日志ID",
 
@@ -59,6 +134,7 @@ var zhCN = map[string]string{
 	// Resource
 	ErrLogNotFound:          "日志不存在",
 	ErrAccountNotRegistered: "该账号未注册",
+	ErrSessionNotFound:      "登录会话不存在",
 
 	// Generic
 	ErrInternalError: "服务器内部错误",
@@ -73,6 +149,20 @@ func init() {
 		ErrGenerateTokenFailed: "生成令牌失败",
 		ErrResetPasswordFailed: "密码重置失败",
 		ErrCreateQRFailed:      "创建二维码失败",
+		ErrRevokeSessionsFailed: "注销登录会话失败",
+		ErrQuerySessionFailed:   "查询登录会话失败",
+		ErrMFAStoreFailed:       "保存两步验证信息失败",
+		ErrOIDCProviderUnavailable: "身份提供方暂不可用",
+		ErrIdentityStoreFailed:     "保存第三方账号关联失败",
+		ErrOIDCStateStoreFailed:    "保存登录请求失败",
+		ErrQRStoreFailed:           "保存二维码状态失败",
+		ErrAPIKeyStoreFailed:       "保存 API Key 失败",
+		ErrAuditQueryFailed:        "查询审计日志失败",
+		ErrPasswordHistoryFailed:   "读取历史密码失败",
+		ErrPasskeyStoreFailed:      "保存通行密钥失败",
+		ErrOAuthClientStoreFailed:  "保存 OAuth 客户端失败",
+		ErrFreezeUserFailed:        "更新冻结状态失败",
+		ErrMagicLinkStoreFailed:    "保存登录链接失败",
 		ErrWechatNotConfigured: "微信小程序未配置",
 		ErrWechatLoginFailed:   "微信登录失败",
 		ErrWechatBindFailed:    "绑定微信失败",
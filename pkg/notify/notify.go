@@ -0,0 +1,20 @@
+// Package notify delivers outbound messages (email, SMS) to end users.
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotConfigured is returned when a sender is used without the required settings
+var ErrNotConfigured = errors.New("notify: sender not configured")
+
+// EmailSender sends plain-text emails
+type EmailSender interface {
+	SendEmail(ctx context.Context, to, subject, body string) error
+}
+
+// SMSSender sends short text messages to a mobile number
+type SMSSender interface {
+	SendSMS(ctx context.Context, mobile, content string) error
+}
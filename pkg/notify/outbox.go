@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// FileSender implements EmailSender by writing each email to its own .eml file in a
+// directory instead of delivering it. Meant for development, where the links and codes
+// in the emails can be opened straight from the outbox.
+type FileSender struct {
+	dir string
+}
+
+// NewFileSender creates a sender that writes emails into dir, creating it when needed
+func NewFileSender(dir string) *FileSender {
+	return &FileSender{dir: dir}
+}
+
+// SendEmail writes the email to a new file in the outbox directory
+func (s *FileSender) SendEmail(ctx context.Context, to, subject, body string) error {
+	if s.dir == "" {
+		return ErrNotConfigured
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+
+	// 文件名按时间排序，收件人只保留安全字符
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '@' || r == '-' {
+			return r
+		}
+		return '_'
+	}, to)
+	f, err := os.CreateTemp(s.dir, time.Now().Format("20060102-150405")+"-"+name+"-*.eml")
+	if err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "To: %s\r\nSubject: %s\r\nDate: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		to, subject, time.Now().Format(time.RFC1123Z), body)
+	if err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+	return nil
+}
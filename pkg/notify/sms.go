@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPSMSConfig holds settings for a JSON-over-HTTP SMS gateway
+type HTTPSMSConfig struct {
+	GatewayURL string
+	APIKey     string
+	SignName   string
+}
+
+// HTTPSMSSender implements SMSSender by POSTing to a generic SMS gateway.
+// The request body is {"mobile", "content", "sign_name"}; any 2xx is treated as success.
+type HTTPSMSSender struct {
+	cfg    HTTPSMSConfig
+	client *http.Client
+}
+
+// NewHTTPSMSSender creates a new HTTP SMS sender
+func NewHTTPSMSSender(cfg HTTPSMSConfig) *HTTPSMSSender {
+	return &HTTPSMSSender{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SendSMS sends a text message through the gateway
+func (s *HTTPSMSSender) SendSMS(ctx context.Context, mobile, content string) error {
+	if s.cfg.GatewayURL == "" {
+		return ErrNotConfigured
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"mobile":    mobile,
+		"content":   content,
+		"sign_name": s.cfg.SignName,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.GatewayURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.APIKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sms gateway: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sms gateway: status %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
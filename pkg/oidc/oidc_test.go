@@ -0,0 +1,168 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubIdP is a minimal OpenID provider: discovery, JWKS and a token endpoint
+// that checks the PKCE verifier and returns a signed ID token
+type stubIdP struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	// values the next ID token is issued with
+	challenge string
+	nonce     string
+	audience  string
+	claims    map[string]interface{}
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	idp := &stubIdP{t: t, key: key, audience: "client-1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		b64 := base64.RawURLEncoding
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "idp-1", "use": "sig", "alg": "RS256",
+			"n": b64.EncodeToString(key.N.Bytes()),
+			"e": b64.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "client-1" || secret != "secret-1" {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		if r.PostForm.Get("code") != "code-1" || S256Challenge(r.PostForm.Get("code_verifier")) != idp.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "at", "token_type": "Bearer", "id_token": idp.idToken(),
+		})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *stubIdP) idToken() string {
+	claims := jwt.MapClaims{
+		"iss":   idp.server.URL,
+		"sub":   "subject-42",
+		"aud":   idp.audience,
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": idp.nonce,
+	}
+	for k, v := range idp.claims {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "idp-1"
+	signed, err := token.SignedString(idp.key)
+	require.NoError(idp.t, err)
+	return signed
+}
+
+func (idp *stubIdP) provider() *Provider {
+	return NewProvider(Config{
+		Name:         "stub",
+		Issuer:       idp.server.URL,
+		ClientID:     "client-1",
+		ClientSecret: "secret-1",
+		RedirectURL:  "https://app.example.com/callback",
+	}, idp.server.Client())
+}
+
+// TestProvider_CodeFlow tests the full authorization-code + PKCE flow against the stub IdP
+func TestProvider_CodeFlow(t *testing.T) {
+	idp := newStubIdP(t)
+	p := idp.provider()
+	ctx := context.Background()
+
+	verifier, err := GenerateVerifier()
+	require.NoError(t, err)
+	assert.Len(t, verifier, 43)
+
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", S256Challenge(verifier))
+	require.NoError(t, err)
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	q := u.Query()
+	assert.Equal(t, idp.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, "code", q.Get("response_type"))
+	assert.Equal(t, "openid email profile", q.Get("scope"))
+	assert.Equal(t, "S256", q.Get("code_challenge_method"))
+	assert.Equal(t, "state-1", q.Get("state"))
+
+	idp.challenge = q.Get("code_challenge")
+	idp.nonce = q.Get("nonce")
+	idp.claims = map[string]interface{}{"email": "a@example.com", "email_verified": "true"}
+
+	token, err := p.Exchange(ctx, "code-1", verifier)
+	require.NoError(t, err)
+
+	claims, err := p.VerifyIDToken(ctx, token.IDToken, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "subject-42", claims.Subject)
+	assert.True(t, claims.EmailIsVerified(), "string email_verified is accepted")
+
+	_, err = p.Exchange(ctx, "code-1", "wrong-verifier")
+	assert.ErrorIs(t, err, ErrExchange)
+}
+
+// TestProvider_VerifyIDTokenRejects tests that tampered ID tokens are rejected
+func TestProvider_VerifyIDTokenRejects(t *testing.T) {
+	idp := newStubIdP(t)
+	p := idp.provider()
+	ctx := context.Background()
+	idp.nonce = "nonce-1"
+
+	_, err := p.VerifyIDToken(ctx, idp.idToken(), "another-nonce")
+	assert.ErrorIs(t, err, ErrInvalidIDToken, "nonce mismatch")
+
+	idp.audience = "someone-else"
+	_, err = p.VerifyIDToken(ctx, idp.idToken(), "nonce-1")
+	assert.ErrorIs(t, err, ErrInvalidIDToken, "audience mismatch")
+
+	idp.audience = "client-1"
+	idp.claims = map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()}
+	_, err = p.VerifyIDToken(ctx, idp.idToken(), "nonce-1")
+	assert.ErrorIs(t, err, ErrInvalidIDToken, "expired")
+
+	idp.claims = nil
+	forged, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	idp.key = forged
+	_, err = p.VerifyIDToken(ctx, idp.idToken(), "nonce-1")
+	assert.ErrorIs(t, err, ErrInvalidIDToken, "signed by an unknown key")
+}
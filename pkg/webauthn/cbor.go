@@ -0,0 +1,135 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// cborMaxDepth bounds nesting so a hostile attestation object cannot exhaust the stack
+const cborMaxDepth = 16
+
+var errMalformedCBOR = errors.New("webauthn: malformed cbor")
+
+// decodeCBOR decodes one data item and returns it together with the bytes that follow it.
+// Only what WebAuthn uses is supported: integers (as int64), byte strings ([]byte), text
+// strings, arrays ([]any), maps with integer or text keys (map[any]any), booleans and null.
+// Tags, floats and indefinite lengths are rejected.
+func decodeCBOR(data []byte) (any, []byte, error) {
+	return decodeItem(data, 0)
+}
+
+func decodeItem(data []byte, depth int) (any, []byte, error) {
+	if len(data) == 0 || depth > cborMaxDepth {
+		return nil, nil, errMalformedCBOR
+	}
+	major, info := data[0]>>5, data[0]&0x1f
+	data = data[1:]
+
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, data, nil
+		case 21:
+			return true, data, nil
+		case 22:
+			return nil, data, nil
+		}
+		return nil, nil, errMalformedCBOR
+	}
+
+	n, data, err := readArgument(info, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch major {
+	case 0:
+		if n > math.MaxInt64 {
+			return nil, nil, errMalformedCBOR
+		}
+		return int64(n), data, nil
+	case 1:
+		if n > math.MaxInt64 {
+			return nil, nil, errMalformedCBOR
+		}
+		return -1 - int64(n), data, nil
+	case 2, 3:
+		if n > uint64(len(data)) {
+			return nil, nil, errMalformedCBOR
+		}
+		if major == 3 {
+			return string(data[:n]), data[n:], nil
+		}
+		return data[:n:n], data[n:], nil
+	case 4:
+		// 每个元素至少占一个字节，先挡住过大的长度
+		if n > uint64(len(data)) {
+			return nil, nil, errMalformedCBOR
+		}
+		items := make([]any, 0, n)
+		for range n {
+			var item any
+			if item, data, err = decodeItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+		}
+		return items, data, nil
+	case 5:
+		if n > uint64(len(data))/2 {
+			return nil, nil, errMalformedCBOR
+		}
+		items := make(map[any]any, n)
+		for range n {
+			var key, value any
+			if key, data, err = decodeItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, nil, errMalformedCBOR
+			}
+			if value, data, err = decodeItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+			items[key] = value
+		}
+		return items, data, nil
+	}
+	return nil, nil, errMalformedCBOR
+}
+
+// readArgument reads the length or value that follows an initial byte
+func readArgument(info byte, data []byte) (uint64, []byte, error) {
+	size := 0
+	switch {
+	case info < 24:
+		return uint64(info), data, nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, nil, errMalformedCBOR
+	}
+	if len(data) < size {
+		return 0, nil, errMalformedCBOR
+	}
+	var n uint64
+	switch size {
+	case 1:
+		n = uint64(data[0])
+	case 2:
+		n = uint64(binary.BigEndian.Uint16(data))
+	case 4:
+		n = uint64(binary.BigEndian.Uint32(data))
+	case 8:
+		n = binary.BigEndian.Uint64(data)
+	}
+	return n, data[size:], nil
+}
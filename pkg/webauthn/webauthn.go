@@ -0,0 +1,381 @@
+// Package webauthn implements the relying-party side of WebAuthn (passkeys): building
+// creation and request options, and verifying registration and authentication responses.
+// Attestation statements are not checked, i.e. any authenticator is accepted
+// ("none" conveyance); the credential is trusted because the user registers it while signed in.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidResponse      = errors.New("webauthn: invalid response")
+	ErrChallengeMismatch    = errors.New("webauthn: challenge mismatch")
+	ErrOriginMismatch       = errors.New("webauthn: origin not allowed")
+	ErrRPIDMismatch         = errors.New("webauthn: rp id hash mismatch")
+	ErrUserNotVerified      = errors.New("webauthn: user presence or verification missing")
+	ErrBadSignature         = errors.New("webauthn: signature verification failed")
+	ErrSignCount            = errors.New("webauthn: signature counter did not increase, authenticator may be cloned")
+	ErrUnsupportedAlgorithm = errors.New("webauthn: unsupported credential algorithm")
+)
+
+// ChallengeSize is the number of random bytes in a challenge
+const ChallengeSize = 32
+
+// Config describes the relying party
+type Config struct {
+	RPID    string   // 通常是站点域名，例如 example.com
+	RPName  string   // 展示给用户的名称
+	Origins []string // 允许的前端 origin，例如 https://app.example.com
+	Timeout time.Duration
+}
+
+// RelyingParty builds options and verifies responses for one RP ID
+type RelyingParty struct {
+	config   Config
+	rpIDHash [32]byte
+}
+
+// New creates a RelyingParty
+func New(cfg Config) *RelyingParty {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Minute
+	}
+	if cfg.RPName == "" {
+		cfg.RPName = cfg.RPID
+	}
+	return &RelyingParty{config: cfg, rpIDHash: sha256.Sum256([]byte(cfg.RPID))}
+}
+
+// Timeout is how long a ceremony may take; challenges should expire after it
+func (rp *RelyingParty) Timeout() time.Duration {
+	return rp.config.Timeout
+}
+
+// NewChallenge returns a fresh random challenge
+func NewChallenge() ([]byte, error) {
+	challenge := make([]byte, ChallengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// EncodeID encodes binary values (challenges, credential IDs, user handles) as base64url
+func EncodeID(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeID decodes base64url with or without padding
+func DecodeID(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// RPEntity is PublicKeyCredentialRpEntity
+type RPEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserEntity is PublicKeyCredentialUserEntity. ID is the base64url user handle.
+type UserEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// CredentialParameter is PublicKeyCredentialParameters
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+// CredentialDescriptor is PublicKeyCredentialDescriptor. ID is base64url.
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+// AuthenticatorSelection is AuthenticatorSelectionCriteria
+type AuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// CreationOptions is the JSON form of PublicKeyCredentialCreationOptions,
+// as accepted by PublicKeyCredential.parseCreationOptionsFromJSON
+type CreationOptions struct {
+	Challenge              string                 `json:"challenge"`
+	RP                     RPEntity               `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials,omitempty"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions is the JSON form of PublicKeyCredentialRequestOptions,
+// as accepted by PublicKeyCredential.parseRequestOptionsFromJSON
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials,omitempty"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// CreationOptions builds the options for registering a discoverable credential.
+// exclude lists the user's existing credentials so an authenticator is not registered twice.
+func (rp *RelyingParty) CreationOptions(challenge []byte, user UserEntity, exclude []CredentialDescriptor) *CreationOptions {
+	params := make([]CredentialParameter, 0, len(SupportedAlgorithms))
+	for _, alg := range SupportedAlgorithms {
+		params = append(params, CredentialParameter{Type: "public-key", Alg: alg})
+	}
+	return &CreationOptions{
+		Challenge:          EncodeID(challenge),
+		RP:                 RPEntity{ID: rp.config.RPID, Name: rp.config.RPName},
+		User:               user,
+		PubKeyCredParams:   params,
+		Timeout:            rp.config.Timeout.Milliseconds(),
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:        "required",
+			RequireResidentKey: true,
+			UserVerification:   "required",
+		},
+		Attestation: "none",
+	}
+}
+
+// RequestOptions builds the options for an assertion. With no allowed credentials the
+// browser offers every passkey it holds for the RP ID.
+func (rp *RelyingParty) RequestOptions(challenge []byte, allow []CredentialDescriptor) *RequestOptions {
+	return &RequestOptions{
+		Challenge:        EncodeID(challenge),
+		Timeout:          rp.config.Timeout.Milliseconds(),
+		RPID:             rp.config.RPID,
+		AllowCredentials: allow,
+		UserVerification: "required",
+	}
+}
+
+// RegistrationResponse is PublicKeyCredential.toJSON() of navigator.credentials.create()
+type RegistrationResponse struct {
+	ID       string              `json:"id"`
+	RawID    string              `json:"rawId"`
+	Type     string              `json:"type"`
+	Response AttestationResponse `json:"response"`
+}
+
+// AttestationResponse is AuthenticatorAttestationResponse; binary fields are base64url
+type AttestationResponse struct {
+	ClientDataJSON    string   `json:"clientDataJSON"`
+	AttestationObject string   `json:"attestationObject"`
+	Transports        []string `json:"transports,omitempty"`
+}
+
+// AuthenticationResponse is PublicKeyCredential.toJSON() of navigator.credentials.get()
+type AuthenticationResponse struct {
+	ID       string            `json:"id"`
+	RawID    string            `json:"rawId"`
+	Type     string            `json:"type"`
+	Response AssertionResponse `json:"response"`
+}
+
+// AssertionResponse is AuthenticatorAssertionResponse; binary fields are base64url
+type AssertionResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON"`
+	AuthenticatorData string `json:"authenticatorData"`
+	Signature         string `json:"signature"`
+	UserHandle        string `json:"userHandle,omitempty"`
+}
+
+// Credential is a verified new credential, to be stored with the user
+type Credential struct {
+	ID         []byte
+	PublicKey  []byte // COSE_Key
+	SignCount  uint32
+	AAGUID     []byte
+	Transports []string
+}
+
+// clientData is CollectedClientData
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// ChallengeOf reads the challenge from a response's clientDataJSON so the server can find
+// the ceremony it belongs to. The value is not verified here.
+func ChallengeOf(clientDataJSON string) ([]byte, error) {
+	raw, err := DecodeID(clientDataJSON)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return nil, ErrInvalidResponse
+	}
+	challenge, err := DecodeID(cd.Challenge)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+	return challenge, nil
+}
+
+// CredentialID returns the raw ID of the credential that produced the assertion
+func (r *AuthenticationResponse) CredentialID() ([]byte, error) {
+	id := r.RawID
+	if id == "" {
+		id = r.ID
+	}
+	raw, err := DecodeID(id)
+	if err != nil || len(raw) == 0 {
+		return nil, ErrInvalidResponse
+	}
+	return raw, nil
+}
+
+// VerifyRegistration checks a registration response against the challenge that was issued
+// and returns the new credential
+func (rp *RelyingParty) VerifyRegistration(challenge []byte, resp *RegistrationResponse) (*Credential, error) {
+	if resp.Type != "public-key" {
+		return nil, ErrInvalidResponse
+	}
+	if _, err := rp.verifyClientData(resp.Response.ClientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	rawObject, err := DecodeID(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+	item, rest, err := decodeCBOR(rawObject)
+	if err != nil || len(rest) != 0 {
+		return nil, ErrInvalidResponse
+	}
+	object, ok := item.(map[any]any)
+	if !ok {
+		return nil, ErrInvalidResponse
+	}
+	rawAuthData, ok := object["authData"].([]byte)
+	if !ok {
+		return nil, ErrInvalidResponse
+	}
+	ad, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.checkAuthenticatorData(ad); err != nil {
+		return nil, err
+	}
+	if ad.credentialID == nil {
+		return nil, ErrInvalidResponse
+	}
+	if resp.RawID != "" {
+		if rawID, err := DecodeID(resp.RawID); err != nil || !bytes.Equal(rawID, ad.credentialID) {
+			return nil, ErrInvalidResponse
+		}
+	}
+	// 确认公钥可用，避免存下之后每次登录都失败
+	if _, err := parseCOSEKey(ad.publicKey); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:         bytes.Clone(ad.credentialID),
+		PublicKey:  bytes.Clone(ad.publicKey),
+		SignCount:  ad.signCount,
+		AAGUID:     bytes.Clone(ad.aaguid),
+		Transports: resp.Response.Transports,
+	}, nil
+}
+
+// VerifyAuthentication checks an assertion made with a stored credential against the challenge
+// that was issued, and returns the new signature counter to store.
+// A counter that does not increase means the credential may have been cloned.
+func (rp *RelyingParty) VerifyAuthentication(challenge []byte, resp *AuthenticationResponse, publicKeyCOSE []byte, storedSignCount uint32) (uint32, error) {
+	if resp.Type != "public-key" {
+		return 0, ErrInvalidResponse
+	}
+	rawClientData, err := rp.verifyClientData(resp.Response.ClientDataJSON, "webauthn.get", challenge)
+	if err != nil {
+		return 0, err
+	}
+	rawAuthData, err := DecodeID(resp.Response.AuthenticatorData)
+	if err != nil {
+		return 0, ErrInvalidResponse
+	}
+	sig, err := DecodeID(resp.Response.Signature)
+	if err != nil {
+		return 0, ErrInvalidResponse
+	}
+	ad, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return 0, err
+	}
+	if err := rp.checkAuthenticatorData(ad); err != nil {
+		return 0, err
+	}
+
+	key, err := parseCOSEKey(publicKeyCOSE)
+	if err != nil {
+		return 0, err
+	}
+	clientDataHash := sha256.Sum256(rawClientData)
+	if !key.verify(append(bytes.Clone(rawAuthData), clientDataHash[:]...), sig) {
+		return 0, ErrBadSignature
+	}
+
+	// 不支持计数器的认证器始终返回 0
+	if (ad.signCount != 0 || storedSignCount != 0) && ad.signCount <= storedSignCount {
+		return 0, ErrSignCount
+	}
+	return ad.signCount, nil
+}
+
+// verifyClientData checks type, challenge and origin, and returns the raw clientDataJSON
+func (rp *RelyingParty) verifyClientData(encoded, ceremony string, challenge []byte) ([]byte, error) {
+	raw, err := DecodeID(encoded)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil || cd.Type != ceremony {
+		return nil, ErrInvalidResponse
+	}
+	got, err := DecodeID(cd.Challenge)
+	if err != nil || subtle.ConstantTimeCompare(got, challenge) != 1 {
+		return nil, ErrChallengeMismatch
+	}
+	if cd.CrossOrigin || !slices.Contains(rp.config.Origins, cd.Origin) {
+		return nil, ErrOriginMismatch
+	}
+	return raw, nil
+}
+
+// checkAuthenticatorData checks the RP ID hash and that the user was present and verified.
+// Passkeys replace both factors, so user verification is always required.
+func (rp *RelyingParty) checkAuthenticatorData(ad *authenticatorData) error {
+	if subtle.ConstantTimeCompare(ad.rpIDHash, rp.rpIDHash[:]) != 1 {
+		return ErrRPIDMismatch
+	}
+	if ad.flags&flagUserPresent == 0 || ad.flags&flagUserVerified == 0 {
+		return ErrUserNotVerified
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://app.example.com"
+)
+
+// softAuthenticator is a P-256 platform authenticator that lives in memory
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	signCount    uint32
+	rpID         string
+	origin       string
+	flags        byte
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	id := make([]byte, 16)
+	_, err = rand.Read(id)
+	require.NoError(t, err)
+	return &softAuthenticator{
+		key:          key,
+		credentialID: id,
+		rpID:         testRPID,
+		origin:       testOrigin,
+		flags:        flagUserPresent | flagUserVerified,
+	}
+}
+
+func (a *softAuthenticator) clientData(ceremony, challenge string) []byte {
+	data, _ := json.Marshal(map[string]any{"type": ceremony, "challenge": challenge, "origin": a.origin})
+	return data
+}
+
+func (a *softAuthenticator) authData(flags byte, attested []byte) []byte {
+	hash := sha256.Sum256([]byte(a.rpID))
+	out := append(hash[:], flags)
+	out = binary.BigEndian.AppendUint32(out, a.signCount)
+	return append(out, attested...)
+}
+
+// create answers navigator.credentials.create()
+func (a *softAuthenticator) create(options *CreationOptions) *RegistrationResponse {
+	point, _ := a.key.PublicKey.Bytes()
+	coseKey := encodeCBOR(map[any]any{
+		int64(coseKty): int64(ktyEC2),
+		int64(coseAlg): int64(AlgES256),
+		int64(coseCrv): int64(crvP256),
+		int64(coseX):   point[1:33],
+		int64(coseY):   point[33:],
+	})
+	attested := append(make([]byte, 16), byte(len(a.credentialID)>>8), byte(len(a.credentialID)))
+	attested = append(append(attested, a.credentialID...), coseKey...)
+
+	object := encodeCBOR(map[any]any{
+		"fmt":      "none",
+		"attStmt":  map[any]any{},
+		"authData": a.authData(a.flags|flagAttestedData, attested),
+	})
+	return &RegistrationResponse{
+		ID:    EncodeID(a.credentialID),
+		RawID: EncodeID(a.credentialID),
+		Type:  "public-key",
+		Response: AttestationResponse{
+			ClientDataJSON:    EncodeID(a.clientData("webauthn.create", options.Challenge)),
+			AttestationObject: EncodeID(object),
+			Transports:        []string{"internal"},
+		},
+	}
+}
+
+// get answers navigator.credentials.get()
+func (a *softAuthenticator) get(t *testing.T, options *RequestOptions) *AuthenticationResponse {
+	a.signCount++
+	authData := a.authData(a.flags, nil)
+	clientData := a.clientData("webauthn.get", options.Challenge)
+	hash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(bytes.Clone(authData), hash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+
+	return &AuthenticationResponse{
+		ID:    EncodeID(a.credentialID),
+		RawID: EncodeID(a.credentialID),
+		Type:  "public-key",
+		Response: AssertionResponse{
+			ClientDataJSON:    EncodeID(clientData),
+			AuthenticatorData: EncodeID(authData),
+			Signature:         EncodeID(sig),
+		},
+	}
+}
+
+// encodeCBOR is the encoding counterpart of decodeCBOR, enough for the test authenticator
+func encodeCBOR(v any) []byte {
+	head := func(major byte, n uint64) []byte {
+		switch {
+		case n < 24:
+			return []byte{major<<5 | byte(n)}
+		case n < 1<<8:
+			return []byte{major<<5 | 24, byte(n)}
+		default:
+			return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+		}
+	}
+	switch v := v.(type) {
+	case int64:
+		if v < 0 {
+			return head(1, uint64(-1-v))
+		}
+		return head(0, uint64(v))
+	case []byte:
+		return append(head(2, uint64(len(v))), v...)
+	case string:
+		return append(head(3, uint64(len(v))), v...)
+	case map[any]any:
+		out := head(5, uint64(len(v)))
+		for key, value := range v {
+			out = append(out, encodeCBOR(key)...)
+			out = append(out, encodeCBOR(value)...)
+		}
+		return out
+	}
+	panic("unsupported cbor value")
+}
+
+// TestRelyingParty_Ceremonies tests registration and login driven by a software authenticator
+func TestRelyingParty_Ceremonies(t *testing.T) {
+	rp := New(Config{RPID: testRPID, RPName: "Example", Origins: []string{testOrigin}})
+	authenticator := newSoftAuthenticator(t)
+
+	challenge, err := NewChallenge()
+	require.NoError(t, err)
+	creation := rp.CreationOptions(challenge, UserEntity{ID: EncodeID([]byte("user-1")), Name: "alice", DisplayName: "Alice"}, nil)
+	assert.Equal(t, "required", creation.AuthenticatorSelection.UserVerification)
+
+	credential, err := rp.VerifyRegistration(challenge, authenticator.create(creation))
+	require.NoError(t, err)
+	assert.Equal(t, authenticator.credentialID, credential.ID)
+	assert.Equal(t, []string{"internal"}, credential.Transports)
+
+	challenge, err = NewChallenge()
+	require.NoError(t, err)
+	request := rp.RequestOptions(challenge, nil)
+	assertion := authenticator.get(t, request)
+
+	got, err := ChallengeOf(assertion.Response.ClientDataJSON)
+	require.NoError(t, err)
+	assert.Equal(t, challenge, got)
+	id, err := assertion.CredentialID()
+	require.NoError(t, err)
+	assert.Equal(t, credential.ID, id)
+
+	signCount, err := rp.VerifyAuthentication(challenge, assertion, credential.PublicKey, credential.SignCount)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), signCount)
+
+	// 重放同一个断言：计数器没有增长
+	_, err = rp.VerifyAuthentication(challenge, assertion, credential.PublicKey, signCount)
+	assert.ErrorIs(t, err, ErrSignCount)
+}
+
+// TestRelyingParty_Rejects tests that tampered or foreign responses are rejected
+func TestRelyingParty_Rejects(t *testing.T) {
+	rp := New(Config{RPID: testRPID, Origins: []string{testOrigin}})
+	authenticator := newSoftAuthenticator(t)
+	challenge, _ := NewChallenge()
+	credential, err := rp.VerifyRegistration(challenge, authenticator.create(rp.CreationOptions(challenge, UserEntity{}, nil)))
+	require.NoError(t, err)
+
+	t.Run("wrong challenge", func(t *testing.T) {
+		other, _ := NewChallenge()
+		assertion := authenticator.get(t, rp.RequestOptions(other, nil))
+		_, err := rp.VerifyAuthentication(challenge, assertion, credential.PublicKey, 0)
+		assert.ErrorIs(t, err, ErrChallengeMismatch)
+	})
+
+	t.Run("wrong origin", func(t *testing.T) {
+		phishing := *authenticator
+		phishing.origin = "https://app.example.com.evil.test"
+		_, err := rp.VerifyAuthentication(challenge, phishing.get(t, rp.RequestOptions(challenge, nil)), credential.PublicKey, 0)
+		assert.ErrorIs(t, err, ErrOriginMismatch)
+	})
+
+	t.Run("wrong rp id", func(t *testing.T) {
+		foreign := *authenticator
+		foreign.rpID = "evil.test"
+		_, err := rp.VerifyAuthentication(challenge, foreign.get(t, rp.RequestOptions(challenge, nil)), credential.PublicKey, 0)
+		assert.ErrorIs(t, err, ErrRPIDMismatch)
+	})
+
+	t.Run("user not verified", func(t *testing.T) {
+		presenceOnly := *authenticator
+		presenceOnly.flags = flagUserPresent
+		_, err := rp.VerifyAuthentication(challenge, presenceOnly.get(t, rp.RequestOptions(challenge, nil)), credential.PublicKey, 0)
+		assert.ErrorIs(t, err, ErrUserNotVerified)
+	})
+
+	t.Run("other key", func(t *testing.T) {
+		impostor := newSoftAuthenticator(t)
+		_, err := rp.VerifyAuthentication(challenge, impostor.get(t, rp.RequestOptions(challenge, nil)), credential.PublicKey, 0)
+		assert.ErrorIs(t, err, ErrBadSignature)
+	})
+
+	t.Run("malformed cbor", func(t *testing.T) {
+		_, _, err := decodeCBOR([]byte{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+		assert.Error(t, err)
+		_, err = parseCOSEKey([]byte{0xa1, 0x01})
+		assert.Error(t, err)
+	})
+}